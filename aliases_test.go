@@ -0,0 +1,58 @@
+package envreq_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestAliases(t *testing.T) {
+	req := envreq.Requirement{
+		Name:    "ALIAS_DATABASE_URL",
+		Aliases: []string{"ALIAS_LEGACY_DB_URL", "ALIAS_DB_URL_OLD"},
+	}
+
+	// The first alias set supplies the value
+	t.Setenv("ALIAS_DB_URL_OLD", "postgres://old")
+	g := envreq.New()
+	res := g.Check(req)
+	if res.Value != "postgres://old" || res.From != "ALIAS_DB_URL_OLD" {
+		t.Errorf("Expected the value from ALIAS_DB_URL_OLD, got %q from %q", res.Value, res.From)
+	}
+	var buf bytes.Buffer
+	g.Report(&buf)
+	if !strings.Contains(buf.String(), "[from ALIAS_DB_URL_OLD]") || strings.Contains(buf.String(), "deprecated") {
+		t.Errorf("Expected the alias in the report:\n%s", buf.String())
+	}
+	if v, ok := g.Value("ALIAS_DATABASE_URL"); !ok || v != "postgres://old" {
+		t.Errorf("Expected the value under the primary name, got %q", v)
+	}
+
+	// Earlier aliases win over later ones, the primary name over all
+	t.Setenv("ALIAS_LEGACY_DB_URL", "postgres://legacy")
+	if res := envreq.New().Check(req); res.From != "ALIAS_LEGACY_DB_URL" {
+		t.Errorf("Expected the first alias to win, got %q", res.From)
+	}
+	t.Setenv("ALIAS_DATABASE_URL", "postgres://new")
+	if res := envreq.New().Check(req); res.Value != "postgres://new" || res.From != "" {
+		t.Errorf("Expected the primary name to win, got %q from %q", res.Value, res.From)
+	}
+
+	// Registrations merge their aliases
+	g = envreq.New()
+	g.Check(envreq.Requirement{Name: "ALIAS_MERGED", Aliases: []string{"A1"}})
+	g.Check(envreq.Requirement{Name: "ALIAS_MERGED", Aliases: []string{"A2", "A1"}})
+	for _, v := range g.Describe().Vars {
+		if v.Name == "ALIAS_MERGED" && strings.Join(v.Aliases, ",") != "A1,A2" {
+			t.Errorf("Expected merged aliases A1,A2, got %v", v.Aliases)
+		}
+	}
+
+	bad := envreq.Requirement{Name: "ALIAS_OK", Aliases: []string{"BAD ALIAS"}}
+	if err := bad.Verify(); !errors.Is(err, envreq.ErrInvalidName) {
+		t.Errorf("Expected ErrInvalidName for a malformed alias, got %v", err)
+	}
+}
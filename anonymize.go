@@ -0,0 +1,63 @@
+package envreq
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+)
+
+// errAnonymized replaces validator errors in anonymized results, since
+// validator messages can quote the offending value.
+var errAnonymized = errors.New("validation failed")
+
+// fingerprint returns a short, stable, non-reversible digest of s.
+func fingerprint(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:6])
+}
+
+// Anonymize returns copies of results that are safe to share outside the
+// organization, e.g. with a vendor or in a public bug report. Variable names
+// are replaced with VAR_xxxx tokens keyed with a random key per call, so
+// the same name maps to the same token within one report but tokens cannot
+// be matched against a list of common names. Sources, descriptions,
+// defaults, values and validator messages are stripped. Status information (required, sensitive, present,
+// valid) is preserved so the report remains useful for diagnosis.
+//
+//	envreq.Report(os.Stdout, envreq.Anonymize(envreq.CheckAll()))
+func Anonymize(results []Result) []Result {
+	key := make([]byte, 32)
+	rand.Read(key)
+	token := func(name string) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(name))
+		return "VAR_" + hex.EncodeToString(mac.Sum(nil)[:6])
+	}
+
+	out := make([]Result, len(results))
+	for i, res := range results {
+		var err error
+		if res.Err != nil {
+			err = errAnonymized
+		}
+		out[i] = Result{
+			Requirement: Requirement{
+				Name:      token(res.Name),
+				Optional:  res.Optional,
+				Sensitive: res.Sensitive,
+			},
+			Present:    res.Present,
+			Provenance: res.Provenance,
+			Err:        err,
+		}
+	}
+
+	// Re-sort so the original alphabetical order doesn't leak either
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
@@ -0,0 +1,50 @@
+package envreq_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestAnonymize(t *testing.T) {
+	envreq.Reset()
+	defer envreq.Reset()
+	t.Setenv("INTERNAL_BILLING_URL", "not-a-url")
+
+	envreq.Check(envreq.Requirement{
+		Name:        "INTERNAL_BILLING_URL",
+		Source:      "billing",
+		Description: "Billing ledger endpoint",
+		Validate:    envreq.URL,
+	})
+	envreq.Check(envreq.Requirement{Name: "INTERNAL_MISSING", Source: "billing"})
+
+	anon := envreq.Anonymize(envreq.CheckAll())
+	again := envreq.Anonymize(envreq.CheckAll())
+	if dup := envreq.Anonymize([]envreq.Result{anon[0], anon[0]}); dup[0].Name != dup[1].Name {
+		t.Errorf("Expected one token per name within a report, got %s and %s", dup[0].Name, dup[1].Name)
+	}
+
+	var buf bytes.Buffer
+	missing := envreq.Report(&buf, anon)
+	out := buf.String()
+
+	for _, leak := range []string{"INTERNAL", "billing", "Billing", "not-a-url"} {
+		if strings.Contains(out, leak) {
+			t.Errorf("Anonymized report leaks %q:\n%s", leak, out)
+		}
+	}
+	if missing != 2 {
+		t.Errorf("Expected 2 missing in anonymized report, got %d", missing)
+	}
+	for i := range anon {
+		if anon[i].Name == again[i].Name {
+			t.Errorf("Expected tokens keyed per call, got %s twice", anon[i].Name)
+		}
+		if !strings.HasPrefix(anon[i].Name, "VAR_") {
+			t.Errorf("Expected VAR_ prefix, got %s", anon[i].Name)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package envreq
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+)
+
+// APIPrefix is the path prefix of the versioned introspection API.
+const APIPrefix = "/envreq/v1/"
+
+// OpenAPI is the OpenAPI 3 document describing the endpoints served by API.
+//
+//go:embed openapi.json
+var OpenAPI []byte
+
+// ReportEntry is the JSON form of one report row. It never carries a value.
+type ReportEntry struct {
+	Name        string   `json:"name"`
+	Source      string   `json:"source,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Required    bool     `json:"required"`
+	Sensitive   bool     `json:"sensitive"`
+	Status      string   `json:"status"` // one of the Status constants, e.g. StatusOK
+	Provenance  string   `json:"provenance,omitempty"`
+	Consulted   []string `json:"consulted,omitempty"` // lookups made, e.g. "vault DB_URL: permission denied"
+	Error       string   `json:"error,omitempty"`
+	ResolvedAt  string   `json:"resolvedAt,omitempty"` // formatted with SetTimeFormat
+	// Remediation is how to fix the variable; absent when it is ok.
+	Remediation *Remediation `json:"remediation,omitempty"`
+	// Shape describes the value without revealing it; absent when the
+	// variable is unset or NeverShow.
+	Shape *ValueShape `json:"shape,omitempty"`
+}
+
+// reportEntry converts res to its JSON form.
+func reportEntry(res Result) ReportEntry {
+	e := ReportEntry{
+		Name:        res.Name,
+		Source:      res.Source,
+		Description: res.Description,
+		Required:    !res.Optional,
+		Sensitive:   res.Sensitive,
+		Status:      resultStatus(res),
+		Provenance:  res.Provenance,
+		ResolvedAt:  formatTime(res.ResolvedAt),
+	}
+	for _, c := range res.Consulted {
+		e.Consulted = append(e.Consulted, c.String())
+	}
+	if res.Err != nil {
+		e.Error = res.Err.Error()
+	}
+	e.Remediation = remediation(res, e.Status, "")
+	if shape, ok := res.Shape(); ok {
+		e.Shape = &shape
+	}
+	return e
+}
+
+// API returns an http.Handler serving the versioned introspection API,
+// to be mounted at APIPrefix:
+//
+//	GET /envreq/v1/report        report entries as JSON
+//	GET /envreq/v1/schema        the Schema of registered requirements
+//	GET /envreq/v1/hotpaths      HotPaths, Check counts after Freeze
+//	GET /envreq/v1/openapi.json  the OpenAPI document of this API
+//
+// It is guarded like Handler, including the refusal of proxied requests
+// without a token or authentication; see HandlerOption.
+func API(opts ...HandlerOption) http.Handler {
+	return std.API(opts...)
+}
+
+// API returns an http.Handler serving the registry's introspection API.
+// See the package-level API.
+func (g *Registry) API(opts ...HandlerOption) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(APIPrefix+"report", readOnly(func(w http.ResponseWriter, r *http.Request) {
+		results := g.CheckAll()
+		entries := make([]ReportEntry, len(results))
+		for i, res := range results {
+			entries[i] = reportEntry(res)
+		}
+		writeJSON(w, entries)
+	}))
+	mux.Handle(APIPrefix+"schema", readOnly(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, g.Describe())
+	}))
+	mux.Handle(APIPrefix+"hotpaths", readOnly(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, g.HotPaths())
+	}))
+	mux.Handle(APIPrefix+"openapi.json", readOnly(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(OpenAPI)
+	}))
+	return guard(mux, opts)
+}
+
+// writeJSON writes v as an indented JSON response.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,91 @@
+package envreq
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// awsRegionPattern matches AWS region names such as "us-east-1",
+// "ap-southeast-2", "us-gov-west-1" and, in the European Sovereign Cloud
+// partition, "eusc-de-east-1".
+var awsRegionPattern = regexp.MustCompile(`^(eusc-)?[a-z]{2}(-(gov|iso|isob|isoe|isof))?-(central|north|south|east|west|northeast|northwest|southeast|southwest)-[1-9][0-9]?$`)
+
+// awsPartitions are the partitions an ARN may name.
+var awsPartitions = map[string]bool{
+	"aws": true, "aws-cn": true, "aws-us-gov": true, "aws-iso": true,
+	"aws-iso-b": true, "aws-iso-e": true, "aws-iso-f": true, "aws-eusc": true,
+}
+
+// AWSRegion validates an AWS region name such as "eu-central-1", by its
+// form rather than a fixed list, so that new regions are accepted. An
+// availability zone ("us-east-1a") is rejected with a hint.
+func AWSRegion(v string) error {
+	if awsRegionPattern.MatchString(v) {
+		return nil
+	}
+	if zone := strings.TrimRight(v, "abcdefghijklmnopqrstuvwxyz"); zone != v && awsRegionPattern.MatchString(zone) {
+		return fmt.Errorf("is an availability zone; use its region, e.g. %s", zone)
+	}
+	return fmt.Errorf("must be an AWS region such as us-east-1")
+}
+
+// AWSARN validates an Amazon Resource Name,
+// "arn:partition:service:region:account-id:resource", e.g.
+// "arn:aws:sqs:us-east-1:123456789012:orders". The region and account may
+// be empty, as for global services like IAM and S3; the account may also
+// be "aws" for AWS managed resources.
+func AWSARN(v string) error {
+	parts := strings.SplitN(v, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" {
+		return fmt.Errorf("must be an ARN, arn:partition:service:region:account-id:resource")
+	}
+	partition, service, region, account, resource := parts[1], parts[2], parts[3], parts[4], parts[5]
+	if !awsPartitions[partition] {
+		return fmt.Errorf("unknown ARN partition %q", partition)
+	}
+	if service == "" || strings.Trim(service, "abcdefghijklmnopqrstuvwxyz0123456789-") != "" {
+		return fmt.Errorf("ARN service must be a lower-case name such as sqs")
+	}
+	if region != "" && AWSRegion(region) != nil {
+		return fmt.Errorf("ARN region must be empty or an AWS region such as us-east-1")
+	}
+	if account != "" && account != "aws" && (len(account) != 12 || strings.Trim(account, "0123456789") != "") {
+		return fmt.Errorf("ARN account ID must be empty or 12 digits")
+	}
+	if resource == "" {
+		return fmt.Errorf("ARN resource cannot be empty")
+	}
+	return nil
+}
+
+// S3URI validates an S3 location "s3://bucket" or "s3://bucket/key/prefix",
+// checking the bucket name rules: 3-63 lower-case letters, digits, dots and
+// hyphens, starting and ending with a letter or digit, and not an IP
+// address.
+func S3URI(v string) error {
+	rest, ok := strings.CutPrefix(v, "s3://")
+	if !ok {
+		return fmt.Errorf("must be an S3 URI, s3://bucket/key")
+	}
+	bucket, _, _ := strings.Cut(rest, "/")
+	switch {
+	case len(bucket) < 3 || len(bucket) > 63:
+		return fmt.Errorf("S3 bucket name must be 3-63 characters")
+	case strings.Trim(bucket, "abcdefghijklmnopqrstuvwxyz0123456789.-") != "":
+		return fmt.Errorf("S3 bucket name may only contain lower-case letters, digits, dots and hyphens")
+	case !s3BucketEdge(bucket[0]) || !s3BucketEdge(bucket[len(bucket)-1]):
+		return fmt.Errorf("S3 bucket name must start and end with a letter or digit")
+	case strings.Contains(bucket, ".."):
+		return fmt.Errorf("S3 bucket name must not contain adjacent dots")
+	case net.ParseIP(bucket) != nil:
+		return fmt.Errorf("S3 bucket name must not be an IP address")
+	}
+	return nil
+}
+
+// s3BucketEdge reports whether c may start or end a bucket name.
+func s3BucketEdge(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,265 @@
+// Package awssource resolves envreq requirements from AWS Systems Manager
+// Parameter Store and Secrets Manager, so services no longer need an init
+// container that copies parameters into the environment.
+//
+// Requirements name what they need with a SecretRef:
+//
+//	"ssm:/prod/payments/database_url"
+//	"secretsmanager:arn:aws:secretsmanager:eu-west-1:123456789012:secret:payments-AbCdEf#stripe_key"
+//
+// and the sources go after Env, so a set environment variable still wins:
+//
+//	cfg := awssource.Config{} // AWS_REGION, AWS_ACCESS_KEY_ID, ...
+//	params := awssource.NewSSM(cfg)
+//	params.Prefetch(ctx, "/prod/payments/database_url", "/prod/payments/redis_url")
+//	envreq.SetSources(envreq.Env, params, awssource.NewSecretsManager(cfg))
+//
+// Prefetch loads parameters with batched GetParameters calls (ten names per
+// request) before the first Check, keeping startup to a few round trips.
+// Values are cached for the life of the source.
+//
+// The package talks to the AWS JSON APIs directly with Signature Version 4;
+// it does not depend on the AWS SDK. Credentials come from, in order,
+// Config.Credentials, static keys in Config or AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY, a web identity token (AWS_WEB_IDENTITY_TOKEN_FILE
+// and AWS_ROLE_ARN, as set for EKS IAM roles for service accounts), and
+// the container credentials endpoint (AWS_CONTAINER_CREDENTIALS_FULL_URI or
+// _RELATIVE_URI, as set for ECS task roles and EKS Pod Identity). Shared
+// config files, SSO and EC2 instance profiles (IMDS) are not read; supply
+// those through Config.Credentials.
+package awssource
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config configures the AWS sources. Zero values fall back to the standard
+// AWS environment variables.
+type Config struct {
+	Region          string // default $AWS_REGION, then $AWS_DEFAULT_REGION
+	AccessKeyID     string // default $AWS_ACCESS_KEY_ID
+	SecretAccessKey string // default $AWS_SECRET_ACCESS_KEY
+	SessionToken    string // default $AWS_SESSION_TOKEN
+	// Credentials, if set, supplies the credentials instead of the static
+	// keys and the role providers, e.g. from an instance profile. They are
+	// cached until a minute before Expires.
+	Credentials func(ctx context.Context) (Credentials, error)
+	// Endpoint overrides the service URL, e.g. for VPC endpoints or
+	// LocalStack. It is used for both services.
+	Endpoint    string
+	STSEndpoint string        // web identity exchange; default the regional STS endpoint
+	Timeout     time.Duration // per-request timeout; default 10s
+	Client      *http.Client  // default http.DefaultClient
+}
+
+// withDefaults fills the zero fields of c.
+func (c Config) withDefaults() Config {
+	env := func(dst *string, names ...string) {
+		for _, n := range names {
+			if *dst == "" {
+				*dst = os.Getenv(n)
+			}
+		}
+	}
+	env(&c.Region, "AWS_REGION", "AWS_DEFAULT_REGION")
+	env(&c.AccessKeyID, "AWS_ACCESS_KEY_ID")
+	env(&c.SecretAccessKey, "AWS_SECRET_ACCESS_KEY")
+	env(&c.SessionToken, "AWS_SESSION_TOKEN")
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	return c
+}
+
+// client calls one AWS JSON 1.1 service.
+type client struct {
+	cfg     Config
+	service string // signing name and endpoint prefix, e.g. "ssm"
+	target  string // X-Amz-Target prefix, e.g. "AmazonSSM"
+	now     func() time.Time
+
+	credsMu sync.Mutex
+	creds   Credentials // cached role or Config.Credentials credentials
+}
+
+func newClient(cfg Config, service, target string) *client {
+	return &client{cfg: cfg.withDefaults(), service: service, target: target, now: time.Now}
+}
+
+// apiError is the error body of the AWS JSON protocol.
+type apiError struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+// call invokes action with in as the request body and decodes the response
+// into out.
+func (c *client) call(ctx context.Context, action string, in, out any) error {
+	if c.cfg.Region == "" {
+		return errors.New("awssource: no region (set Config.Region or AWS_REGION)")
+	}
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	endpoint := c.cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://" + c.service + "." + c.cfg.Region + ".amazonaws.com"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", c.target+"."+action)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	sign(req, body, creds.AccessKeyID, creds.SecretAccessKey, c.cfg.Region, c.service, c.now())
+
+	resp, err := c.cfg.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("awssource: %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("awssource: %s: %w", action, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e apiError
+		json.Unmarshal(data, &e)
+		if _, typ, ok := strings.Cut(e.Type, "#"); ok {
+			e.Type = typ
+		}
+		return &Error{Action: action, Status: resp.StatusCode, Code: e.Type, Message: e.Message}
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("awssource: %s: %w", action, err)
+	}
+	return nil
+}
+
+// Error is an error response from an AWS API.
+type Error struct {
+	Action  string // e.g. "GetParameters"
+	Status  int    // HTTP status
+	Code    string // AWS error code, e.g. "AccessDeniedException"
+	Message string
+}
+
+func (e *Error) Error() string {
+	code := e.Code
+	if code == "" {
+		code = http.StatusText(e.Status)
+	}
+	if e.Message == "" {
+		return fmt.Sprintf("awssource: %s: %s", e.Action, code)
+	}
+	return fmt.Sprintf("awssource: %s: %s: %s", e.Action, code, e.Message)
+}
+
+// authCodes are the error codes of rejected credentials.
+var authCodes = map[string]bool{
+	"AccessDeniedException":               true,
+	"ExpiredTokenException":               true,
+	"IncompleteSignatureException":        true,
+	"InvalidSignatureException":           true,
+	"MissingAuthenticationTokenException": true,
+	"UnrecognizedClientException":         true,
+}
+
+// Is reports rejected credentials as fs.ErrPermission, which
+// envreq.Providers shows as an authentication failure.
+func (e *Error) Is(target error) bool {
+	return target == fs.ErrPermission &&
+		(e.Status == http.StatusUnauthorized || e.Status == http.StatusForbidden || authCodes[e.Code])
+}
+
+// sign adds a Signature Version 4 Authorization header to req, signing the
+// host and every header already set.
+func sign(req *http.Request, body []byte, keyID, secret, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonHeaders strings.Builder
+	for _, k := range names {
+		canonHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonical := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonHeaders.String(),
+		signed,
+		hexSHA256(body),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256([]byte(canonical))
+
+	key := []byte("AWS4" + secret)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	sig := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+keyID+"/"+scope+
+		", SignedHeaders="+signed+", Signature="+sig)
+}
+
+func hexSHA256(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,232 @@
+package awssource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestSign(t *testing.T) {
+	// "get-vanilla" from the AWS Signature Version 4 test suite
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	sign(req, nil, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "service", now)
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization:\n got %s\nwant %s", got, want)
+	}
+}
+
+// fakeAWS serves GetParameters and GetSecretValue and records the batches.
+type fakeAWS struct {
+	params  map[string]string
+	secrets map[string]string
+	batches [][]string
+}
+
+func (f *fakeAWS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"__type":"com.amazon.coral.service#MissingAuthenticationTokenException"}`))
+		return
+	}
+	var in struct {
+		Names    []string
+		SecretId string
+	}
+	json.NewDecoder(r.Body).Decode(&in)
+
+	switch r.Header.Get("X-Amz-Target") {
+	case "AmazonSSM.GetParameters":
+		f.batches = append(f.batches, in.Names)
+		out := map[string]any{"Parameters": []any{}, "InvalidParameters": []string{}}
+		for _, n := range in.Names {
+			if v, ok := f.params[n]; ok {
+				out["Parameters"] = append(out["Parameters"].([]any), map[string]string{"Name": n, "Value": v})
+			} else {
+				out["InvalidParameters"] = append(out["InvalidParameters"].([]string), n)
+			}
+		}
+		json.NewEncoder(w).Encode(out)
+	case "secretsmanager.GetSecretValue":
+		v, ok := f.secrets[in.SecretId]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"SecretString": v})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func testConfig(t *testing.T, f *fakeAWS) Config {
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return Config{Region: "eu-west-1", AccessKeyID: "AKID", SecretAccessKey: "secret", Endpoint: srv.URL}
+}
+
+func TestSSMPrefetch(t *testing.T) {
+	f := &fakeAWS{params: map[string]string{}}
+	var names []string
+	for i := range 12 {
+		n := "/prod/app/P" + string(rune('A'+i))
+		f.params[n] = "value-" + string(rune('A'+i))
+		names = append(names, n)
+	}
+	s := NewSSM(testConfig(t, f))
+
+	if err := s.Prefetch(context.Background(), append(names, "/prod/app/MISSING")...); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.batches) != 2 || len(f.batches[0]) != 10 || len(f.batches[1]) != 3 {
+		t.Fatalf("Expected batches of 10 and 3 names, got %v", f.batches)
+	}
+
+	g := envreq.New()
+	g.SetSources(envreq.Env, s)
+	res := g.Check(envreq.Requirement{Name: "AWS_TEST_PA", Source: "test", SecretRef: "ssm:/prod/app/PA"})
+	if res.Value != "value-A" || res.Provenance != "ssm" {
+		t.Errorf("Expected the prefetched parameter, got %q from %q (err %v)", res.Value, res.Provenance, res.Err)
+	}
+	if res := g.Check(envreq.Requirement{Name: "AWS_TEST_MISSING", Source: "test", Optional: true, SecretRef: "ssm:/prod/app/MISSING"}); res.Present {
+		t.Errorf("Expected the missing parameter to be absent, got %q", res.Value)
+	}
+	if len(f.batches) != 2 {
+		t.Errorf("Expected Checks to be served from the cache, got batches %v", f.batches)
+	}
+
+	// Uncached parameters are fetched on demand, and Path serves plain names
+	f.params["/prod/app/DATABASE_URL"] = "postgres://db/app"
+	s.Path = "/prod/app/"
+	if v, ok, err := s.Lookup("DATABASE_URL"); err != nil || !ok || v != "postgres://db/app" {
+		t.Errorf("Expected Lookup under Path, got %q, %v, %v", v, ok, err)
+	}
+}
+
+func TestSecretsManager(t *testing.T) {
+	f := &fakeAWS{secrets: map[string]string{
+		"payments": `{"stripe_key":"sk_test_123","retries":3}`,
+		"plain":    "hunter2",
+	}}
+	s := NewSecretsManager(testConfig(t, f))
+
+	for ref, want := range map[string]string{
+		"payments#stripe_key": "sk_test_123",
+		"payments#retries":    "3",
+		"plain":               "hunter2",
+	} {
+		if v, ok, err := s.LookupRef(ref); err != nil || !ok || v != want {
+			t.Errorf("LookupRef(%q) = %q, %v, %v; want %q", ref, v, ok, err, want)
+		}
+	}
+	if _, ok, err := s.LookupRef("nope#key"); ok || err != nil {
+		t.Errorf("Expected a missing secret to be not found, got %v, %v", ok, err)
+	}
+	if _, _, err := s.LookupRef("plain#key"); err == nil {
+		t.Error("Expected an error reading a field of a non-JSON secret")
+	}
+
+	bad := testConfig(t, f)
+	bad.AccessKeyID = "OTHER"
+	_, _, err := NewSecretsManager(bad).LookupRef("plain")
+	if err == nil || !strings.Contains(err.Error(), "MissingAuthenticationTokenException") {
+		t.Errorf("Expected the AWS error code, got %v", err)
+	}
+}
+
+func TestRoleCredentials(t *testing.T) {
+	for _, name := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN",
+		"AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN", "AWS_CONTAINER_CREDENTIALS_FULL_URI",
+		"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "AWS_CONTAINER_AUTHORIZATION_TOKEN", "AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"} {
+		t.Setenv(name, "")
+	}
+	f := &fakeAWS{params: map[string]string{"/prod/app/A": "a"}}
+	cfg := testConfig(t, f)
+	cfg.AccessKeyID, cfg.SecretAccessKey = "", ""
+	expires := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	if _, _, err := NewSSM(cfg).LookupRef("/prod/app/A"); err == nil || !strings.Contains(err.Error(), "no credentials") {
+		t.Errorf("Expected a missing credentials error, got %v", err)
+	}
+
+	// ECS task roles and EKS Pod Identity
+	var auths []string
+	container := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"AccessKeyId":"AKID","SecretAccessKey":"secret","Token":"tok","Expiration":"` + expires + `"}`))
+	}))
+	defer container.Close()
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", container.URL+"/v1/credentials")
+	t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN", "pod-token")
+	s := NewSSM(cfg)
+	for _, ref := range []string{"/prod/app/A", "/prod/app/B"} {
+		if _, _, err := s.LookupRef(ref); err != nil {
+			t.Fatalf("Expected container credentials to sign the call, got %v", err)
+		}
+	}
+	if len(auths) != 1 || auths[0] != "pod-token" {
+		t.Errorf("Expected one authorized credentials request, got %q", auths)
+	}
+
+	// EKS IAM roles for service accounts
+	token := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(token, []byte("jwt\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var form url.Values
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+		w.Write([]byte(`<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>AKID</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>session</SessionToken>
+      <Expiration>` + expires + `</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`))
+	}))
+	defer sts.Close()
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", token)
+	t.Setenv("AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/payments")
+	cfg.STSEndpoint = sts.URL
+	if v, ok, err := NewSSM(cfg).LookupRef("/prod/app/A"); err != nil || !ok || v != "a" {
+		t.Errorf("Expected web identity credentials to sign the call, got %q, %v, %v", v, ok, err)
+	}
+	if form.Get("Action") != "AssumeRoleWithWebIdentity" || form.Get("WebIdentityToken") != "jwt" ||
+		form.Get("RoleArn") != "arn:aws:iam::123456789012:role/payments" {
+		t.Errorf("Unexpected AssumeRoleWithWebIdentity request: %v", form)
+	}
+
+	// Anything else, e.g. an instance profile, through the hook
+	calls := 0
+	cfg.Credentials = func(context.Context) (Credentials, error) {
+		calls++
+		return Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", Expires: time.Now().Add(30 * time.Second)}, nil
+	}
+	s = NewSSM(cfg)
+	for _, ref := range []string{"/prod/app/A", "/prod/app/B"} {
+		if _, _, err := s.LookupRef(ref); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected credentials expiring within a minute to be refreshed, got %d calls", calls)
+	}
+}
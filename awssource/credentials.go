@@ -0,0 +1,182 @@
+package awssource
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Credentials are AWS credentials, as returned by Config.Credentials.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expires         time.Time // zero for credentials that do not expire
+}
+
+// containerCredentialsHost serves AWS_CONTAINER_CREDENTIALS_RELATIVE_URI on
+// ECS tasks.
+const containerCredentialsHost = "http://169.254.170.2"
+
+// credentials returns the credentials to sign with, from the first of
+// Config.Credentials, the static keys, a web identity token (EKS IAM roles
+// for service accounts) and the container credentials endpoint (ECS task
+// roles, EKS Pod Identity). Expiring credentials are cached and refreshed
+// a minute before they expire.
+func (c *client) credentials(ctx context.Context) (Credentials, error) {
+	if c.cfg.Credentials == nil && c.cfg.AccessKeyID != "" && c.cfg.SecretAccessKey != "" {
+		return Credentials{AccessKeyID: c.cfg.AccessKeyID, SecretAccessKey: c.cfg.SecretAccessKey, SessionToken: c.cfg.SessionToken}, nil
+	}
+
+	c.credsMu.Lock()
+	defer c.credsMu.Unlock()
+	if c.creds.AccessKeyID != "" && (c.creds.Expires.IsZero() || c.now().Add(time.Minute).Before(c.creds.Expires)) {
+		return c.creds, nil
+	}
+
+	var creds Credentials
+	var err error
+	switch {
+	case c.cfg.Credentials != nil:
+		creds, err = c.cfg.Credentials(ctx)
+	case os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE") != "" && os.Getenv("AWS_ROLE_ARN") != "":
+		creds, err = c.webIdentityCredentials(ctx)
+	case os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI") != "" || os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI") != "":
+		creds, err = c.containerCredentials(ctx)
+	default:
+		return Credentials{}, errors.New("awssource: no credentials (set Config, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, " +
+			"or run with a web identity or container role; for instance profiles set Config.Credentials)")
+	}
+	if err != nil {
+		return Credentials{}, err
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return Credentials{}, errors.New("awssource: credentials without an access key")
+	}
+	c.creds = creds
+	return creds, nil
+}
+
+// webIdentityCredentials exchanges the token in AWS_WEB_IDENTITY_TOKEN_FILE
+// for credentials of AWS_ROLE_ARN with STS AssumeRoleWithWebIdentity. The
+// call is not signed; the token authenticates it.
+func (c *client) webIdentityCredentials(ctx context.Context) (Credentials, error) {
+	token, err := os.ReadFile(os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"))
+	if err != nil {
+		return Credentials{}, fmt.Errorf("awssource: web identity token: %w", err)
+	}
+	session := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if session == "" {
+		session = fmt.Sprintf("envreq-%d", c.now().Unix())
+	}
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {os.Getenv("AWS_ROLE_ARN")},
+		"RoleSessionName":  {session},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+
+	endpoint := c.cfg.STSEndpoint
+	if endpoint == "" {
+		if c.cfg.Region == "" {
+			return Credentials{}, errors.New("awssource: no region for STS (set Config.Region or AWS_REGION)")
+		}
+		endpoint = "https://sts." + c.cfg.Region + ".amazonaws.com"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", strings.NewReader(form.Encode()))
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	data, err := c.fetchCredentials(req, "AssumeRoleWithWebIdentity")
+	if err != nil {
+		return Credentials{}, err
+	}
+	var out struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.Unmarshal(data, &out); err != nil {
+		return Credentials{}, fmt.Errorf("awssource: AssumeRoleWithWebIdentity: %w", err)
+	}
+	cr := out.Credentials
+	return Credentials{AccessKeyID: cr.AccessKeyID, SecretAccessKey: cr.SecretAccessKey, SessionToken: cr.SessionToken, Expires: cr.Expiration}, nil
+}
+
+// containerCredentials reads credentials from the endpoint named by
+// AWS_CONTAINER_CREDENTIALS_FULL_URI or, relative to the ECS agent,
+// AWS_CONTAINER_CREDENTIALS_RELATIVE_URI, authorized by
+// AWS_CONTAINER_AUTHORIZATION_TOKEN or the contents of
+// AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE when set.
+func (c *client) containerCredentials(ctx context.Context) (Credentials, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if rel := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); rel != "" {
+		endpoint = containerCredentialsHost + rel
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	auth := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if file := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); file != "" {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("awssource: container authorization token: %w", err)
+		}
+		auth = strings.TrimSpace(string(b))
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+
+	data, err := c.fetchCredentials(req, "container credentials")
+	if err != nil {
+		return Credentials{}, err
+	}
+	var out struct {
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string
+		Token           string
+		Expiration      time.Time
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return Credentials{}, fmt.Errorf("awssource: container credentials: %w", err)
+	}
+	return Credentials{AccessKeyID: out.AccessKeyID, SecretAccessKey: out.SecretAccessKey, SessionToken: out.Token, Expires: out.Expiration}, nil
+}
+
+// fetchCredentials sends a credentials request and returns the response
+// body. what names the request in errors.
+func (c *client) fetchCredentials(req *http.Request, what string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), c.cfg.Timeout)
+	defer cancel()
+
+	resp, err := c.cfg.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("awssource: %s: %w", what, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("awssource: %s: %w", what, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &Error{Action: what, Status: resp.StatusCode}
+	}
+	return data, nil
+}
@@ -0,0 +1,116 @@
+package awssource
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// SecretsManager resolves SecretRefs of the form
+// "secretsmanager:<secret id>[#<field>]" from Secrets Manager. The secret
+// id is a name or ARN; with a field, the secret string is decoded as a JSON
+// object and the field's value is returned. It implements
+// envreq.RefSource.
+type SecretsManager struct {
+	c *client
+
+	mu      sync.Mutex
+	secrets map[string]*string // secret id -> value; nil when not found
+	hits    uint64             // lookups served from secrets
+	misses  uint64             // lookups that called GetSecretValue
+}
+
+// NewSecretsManager returns a Secrets Manager source for cfg.
+func NewSecretsManager(cfg Config) *SecretsManager {
+	return &SecretsManager{
+		c:       newClient(cfg, "secretsmanager", "secretsmanager"),
+		secrets: map[string]*string{},
+	}
+}
+
+// Name returns "secretsmanager", recorded as the provenance of values.
+func (s *SecretsManager) Name() string {
+	return "secretsmanager"
+}
+
+// Scheme returns "secretsmanager".
+func (s *SecretsManager) Scheme() string {
+	return "secretsmanager"
+}
+
+// Lookup never finds a value: secrets are only read through SecretRefs.
+func (s *SecretsManager) Lookup(name string) (string, bool, error) {
+	return "", false, nil
+}
+
+// LookupRef returns the secret, or one field of it, named by ref.
+func (s *SecretsManager) LookupRef(ref string) (string, bool, error) {
+	id, field, _ := strings.Cut(ref, "#")
+	secret, err := s.secret(id)
+	if err != nil || secret == nil {
+		return "", false, err
+	}
+	if field == "" {
+		return *secret, true, nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(*secret), &fields); err != nil {
+		return "", false, fmt.Errorf("awssource: secret %s is not a JSON object, cannot read field %q", id, field)
+	}
+	v, ok := fields[field]
+	if !ok {
+		return "", false, nil
+	}
+	if str, ok := v.(string); ok {
+		return str, true, nil
+	}
+	b, _ := json.Marshal(v)
+	return string(b), true, nil
+}
+
+// CacheStats returns how many lookups were served from the cache and how
+// many called Secrets Manager, for envreq.Providers.
+func (s *SecretsManager) CacheStats() (hits, misses uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits, s.misses
+}
+
+// secret returns the value of the secret id, fetched once.
+func (s *SecretsManager) secret(id string) (*string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if v, ok := s.secrets[id]; ok {
+		s.hits++
+		return v, nil
+	}
+	s.misses++
+
+	var out struct {
+		SecretString *string
+		SecretBinary []byte // base64 in the JSON body
+	}
+	err := s.c.call(context.Background(), "GetSecretValue", map[string]string{"SecretId": id}, &out)
+	var aerr *Error
+	if errors.As(err, &aerr) && aerr.Code == "ResourceNotFoundException" {
+		s.secrets[id] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	v := out.SecretString
+	if v == nil {
+		b := base64.StdEncoding.EncodeToString(out.SecretBinary)
+		v = &b
+	}
+	s.secrets[id] = v
+	return v, nil
+}
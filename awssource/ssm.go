@@ -0,0 +1,128 @@
+package awssource
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// maxBatch is the GetParameters limit on names per request.
+const maxBatch = 10
+
+// SSM resolves SecretRefs of the form "ssm:<parameter name>" from Systems
+// Manager Parameter Store. SecureString parameters are decrypted. It
+// implements envreq.RefSource.
+type SSM struct {
+	// Path, if set, serves requirements without a SecretRef from the
+	// parameter Path/NAME, e.g. Path "/prod/payments" serves DATABASE_URL
+	// from "/prod/payments/DATABASE_URL".
+	Path string
+
+	c *client
+
+	mu     sync.Mutex
+	values map[string]string // fetched parameters
+	absent map[string]bool   // parameters reported as invalid (not found)
+	hits   uint64            // lookups served from values or absent
+	misses uint64            // lookups that called GetParameters
+}
+
+// NewSSM returns a Parameter Store source for cfg.
+func NewSSM(cfg Config) *SSM {
+	return &SSM{
+		c:      newClient(cfg, "ssm", "AmazonSSM"),
+		values: map[string]string{},
+		absent: map[string]bool{},
+	}
+}
+
+// Name returns "ssm", recorded as the provenance of values.
+func (s *SSM) Name() string {
+	return "ssm"
+}
+
+// Scheme returns "ssm".
+func (s *SSM) Scheme() string {
+	return "ssm"
+}
+
+// Lookup reads Path/name, if Path is configured.
+func (s *SSM) Lookup(name string) (string, bool, error) {
+	if s.Path == "" {
+		return "", false, nil
+	}
+	return s.LookupRef(strings.TrimSuffix(s.Path, "/") + "/" + name)
+}
+
+// LookupRef returns the parameter named ref, fetching it unless it was
+// prefetched.
+func (s *SSM) LookupRef(ref string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[ref]; ok || s.absent[ref] {
+		s.hits++
+	} else {
+		s.misses++
+	}
+	if err := s.fetch(context.Background(), []string{ref}); err != nil {
+		return "", false, err
+	}
+	v, ok := s.values[ref]
+	return v, ok, nil
+}
+
+// CacheStats returns how many lookups were served from the cache and how
+// many called Parameter Store, for envreq.Providers.
+func (s *SSM) CacheStats() (hits, misses uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits, s.misses
+}
+
+// Prefetch loads the named parameters in batches of ten, so the Checks that
+// follow are served from the cache. Parameters that do not exist are
+// remembered as missing.
+func (s *SSM) Prefetch(ctx context.Context, names ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.fetch(ctx, names)
+}
+
+// fetch loads the names that are not cached yet. s.mu must be held.
+func (s *SSM) fetch(ctx context.Context, names []string) error {
+	var todo []string
+	seen := map[string]bool{}
+	for _, n := range names {
+		if _, ok := s.values[n]; ok || s.absent[n] || seen[n] {
+			continue
+		}
+		seen[n] = true
+		todo = append(todo, n)
+	}
+
+	for len(todo) > 0 {
+		batch := todo[:min(len(todo), maxBatch)]
+		todo = todo[len(batch):]
+
+		var out struct {
+			Parameters []struct {
+				Name  string
+				Value string
+			}
+			InvalidParameters []string
+		}
+		in := map[string]any{"Names": batch, "WithDecryption": true}
+		if err := s.c.call(ctx, "GetParameters", in, &out); err != nil {
+			return err
+		}
+		for _, p := range out.Parameters {
+			s.values[p.Name] = p.Value
+		}
+		for _, n := range out.InvalidParameters {
+			s.absent[n] = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package benchmarks
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+// setup registers n requirements, half of them present in the environment.
+func setup(b *testing.B, n int) []envreq.Requirement {
+	b.Helper()
+	envreq.Reset()
+	b.Cleanup(envreq.Reset)
+
+	reqs := make([]envreq.Requirement, n)
+	for i := range reqs {
+		name := fmt.Sprintf("BENCH_VAR_%05d", i)
+		if i%2 == 0 {
+			b.Setenv(name, fmt.Sprintf("https://svc-%d.example.com", i))
+		}
+		reqs[i] = envreq.Requirement{
+			Name:        name,
+			Source:      fmt.Sprintf("pkg%d", i%20),
+			Description: "benchmark variable",
+			Optional:    i%3 == 0,
+			Sensitive:   i%5 == 0,
+			Validate:    envreq.URL,
+		}
+	}
+	return reqs
+}
+
+// BenchmarkCheckCold measures first-time registration and resolution.
+func BenchmarkCheckCold(b *testing.B) {
+	reqs := setup(b, 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if i%len(reqs) == 0 {
+			b.StopTimer()
+			envreq.Reset()
+			b.StartTimer()
+		}
+		envreq.Check(reqs[i%len(reqs)])
+	}
+}
+
+// BenchmarkCheckCached measures repeated Check of registered variables.
+func BenchmarkCheckCached(b *testing.B) {
+	reqs := setup(b, 1000)
+	for _, r := range reqs {
+		envreq.Check(r)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		envreq.Check(reqs[i%len(reqs)])
+	}
+}
+
+// BenchmarkCheckParallel measures lock contention under concurrent Checks.
+func BenchmarkCheckParallel(b *testing.B) {
+	reqs := setup(b, 1000)
+	for _, r := range reqs {
+		envreq.Check(r)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			envreq.Check(reqs[i%len(reqs)])
+			i++
+		}
+	})
+}
+
+// BenchmarkCheckAll measures snapshotting a 1k-variable registry.
+func BenchmarkCheckAll(b *testing.B) {
+	for _, r := range setup(b, 1000) {
+		envreq.Check(r)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		envreq.CheckAll()
+	}
+}
+
+// BenchmarkReport measures rendering the table report for 1k variables.
+func BenchmarkReport(b *testing.B) {
+	for _, r := range setup(b, 1000) {
+		envreq.Check(r)
+	}
+	results := envreq.CheckAll()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		envreq.Report(io.Discard, results)
+	}
+}
+
+// BenchmarkReportLarge profiles rendering a 10k-entry fleet-sized report.
+func BenchmarkReportLarge(b *testing.B) {
+	for _, r := range setup(b, 10000) {
+		envreq.Check(r)
+	}
+	results := envreq.CheckAll()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		envreq.Report(io.Discard, results)
+	}
+}
+
+// BenchmarkReportPaged measures paginated rendering of 10k entries.
+func BenchmarkReportPaged(b *testing.B) {
+	for _, r := range setup(b, 10000) {
+		envreq.Check(r)
+	}
+	results := envreq.CheckAll()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		envreq.ReportPaged(io.Discard, results, 500, nil)
+	}
+}
@@ -0,0 +1,33 @@
+#!/bin/sh
+# Compare benchmark results of a baseline git revision against the working
+# tree with benchstat.
+#
+# Usage: benchmarks/compare.sh [base-ref] [count]
+#
+#   base-ref  revision to compare against (default: HEAD)
+#   count     -count passed to go test (default: 10)
+#
+# Exits non-zero if benchstat is not installed:
+#   go install golang.org/x/perf/cmd/benchstat@latest
+set -eu
+
+base=${1:-HEAD}
+count=${2:-10}
+root=$(git rev-parse --show-toplevel)
+tmp=$(mktemp -d)
+trap 'git -C "$root" worktree remove --force "$tmp/base" >/dev/null 2>&1; rm -rf "$tmp"' EXIT
+
+command -v benchstat >/dev/null || {
+	echo "benchstat not found: go install golang.org/x/perf/cmd/benchstat@latest" >&2
+	exit 1
+}
+
+run() {
+	(cd "$1" && go test ./benchmarks -run '^$' -bench . -benchmem -count "$count")
+}
+
+git -C "$root" worktree add --detach "$tmp/base" "$base" >/dev/null
+run "$tmp/base" > "$tmp/old.txt"
+run "$root" | tee "$root/bench_output.txt" > "$tmp/new.txt"
+
+benchstat "$tmp/old.txt" "$tmp/new.txt"
@@ -0,0 +1,9 @@
+// Package benchmarks holds representative envreq workloads used to evaluate
+// changes to locking, resolution and reporting against a baseline.
+//
+// Run them with:
+//
+//	go test ./benchmarks -run '^$' -bench . -benchmem -count 10
+//
+// and compare two revisions with compare.sh, which wraps benchstat.
+package benchmarks
@@ -0,0 +1,272 @@
+package envreq
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tagValidators maps the validate= names of envreq struct tags to
+// validators.
+var tagValidators = map[string]func(string) error{
+	"url":           URL,
+	"duration":      Duration,
+	"notempty":      NotEmpty,
+	"ascii":         ASCIIOnly,
+	"nowhitespace":  NoWhitespace,
+	"strongsecret":  StrongSecret,
+	"port":          Port,
+	"unprivport":    UnprivilegedPort,
+	"base64":        Base64,
+	"base64std":     Base64Std,
+	"base64url":     Base64URL,
+	"nocredentials": URLNoCredentials,
+	"hostport":      HostPort,
+	"ip":            IP,
+	"cidr":          CIDR,
+	"hostname":      Hostname,
+	"mac":           MAC,
+	"uuid":          UUID,
+	"ulid":          ULID,
+	"postgres":      PostgresDSN,
+	"mysql":         MySQLDSN,
+	"redis":         RedisURL,
+	"mongo":         MongoURI,
+	"arn":           AWSARN,
+	"awsregion":     AWSRegion,
+	"s3":            S3URI,
+	"rfc3339":       RFC3339,
+	"timezone":      Timezone,
+	"cron":          CronExpr,
+	"file":          FileExists,
+	"dir":           DirExists,
+	"readable":      FileReadable,
+	"executable":    Executable,
+	"pemcert":       PEMCertificate,
+	"pemkey":        PEMPrivateKey,
+	"bool":          Bool,
+	"int":           Int,
+	"float":         Float,
+	"bytes":         ByteSize,
+}
+
+// tagTransforms maps the transform= names of envreq struct tags to
+// transforms.
+var tagTransforms = map[string]func(string) string{
+	"trim":    strings.TrimSpace,
+	"lower":   strings.ToLower,
+	"unquote": TrimSpaceAndQuotes,
+	"noslash": TrimTrailingSlash,
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	urlPtrType   = reflect.TypeOf((*url.URL)(nil))
+	stringsType  = reflect.TypeOf([]string(nil))
+)
+
+// Bind registers a Requirement for every tagged field of the struct cfg
+// points to and sets the fields from the resolved values:
+//
+//	type Config struct {
+//	    APIKey  string        `envreq:"STRIPE_API_KEY,sensitive" desc:"Stripe secret key"`
+//	    BaseURL *url.URL      `envreq:"STRIPE_URL,optional,default=https://api.stripe.com,validate=url"`
+//	    Timeout time.Duration `envreq:"STRIPE_TIMEOUT,optional,default=10s"`
+//	}
+//
+// The first tag element is the variable name; the others are required
+// (the default), optional, sensitive, external, default=VALUE,
+// validate=NAME, each=NAME (validates every element of a list),
+// oneof=A|B|C, transform=A|B (trim, lower, unquote, noslash; applied in
+// order), source=NAME, owner=NAME, example=VALUE and docs=URL. A desc tag
+// sets the Description. The Source defaults to the name of the struct's
+// package. Untagged struct fields are bound recursively; fields tagged "-"
+// are skipped.
+//
+// The validator names are url, duration, notempty, ascii, nowhitespace,
+// strongsecret, port, unprivport, base64, base64std, base64url,
+// nocredentials, hostport, ip, cidr, hostname, mac, uuid, ulid, postgres,
+// mysql, redis, mongo, arn, awsregion, s3, rfc3339, timezone, cron, file,
+// dir, readable, executable, pemcert, pemkey, bool, int, float and bytes.
+//
+// Supported field types are string, bool, integers, floats, time.Duration,
+// *url.URL and []string (a comma-separated List). Bind returns the joined
+// errors of all missing, invalid or unparsable fields; fields without a
+// value are left unchanged.
+func Bind(cfg any) error {
+	return std.bind(cfg)
+}
+
+// Bind binds the struct cfg points to against the registry.
+// See the package-level Bind.
+func (g *Registry) Bind(cfg any) error {
+	return g.bind(cfg)
+}
+
+func (g *Registry) bind(cfg any) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("envreq: Bind needs a non-nil pointer to a struct, got %T", cfg)
+	}
+	return g.bindStruct(v.Elem(), path.Base(v.Elem().Type().PkgPath()), 3)
+}
+
+// bindStruct binds the fields of v. skip is the number of stack frames
+// between the caller of Bind and check, for hot path call sites.
+func (g *Registry) bindStruct(v reflect.Value, source string, skip int) error {
+	var errs []error
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, ok := f.Tag.Lookup("envreq")
+		if tag == "-" {
+			continue
+		}
+		if !ok {
+			if f.Type.Kind() == reflect.Struct && f.Type != durationType {
+				errs = append(errs, g.bindStruct(v.Field(i), source, skip+1))
+			}
+			continue
+		}
+
+		r, err := parseTag(tag, source)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("envreq: field %s: %w", f.Name, err))
+			continue
+		}
+		r.Description = f.Tag.Get("desc")
+		r.List = r.List || f.Type == stringsType
+
+		res := g.check(r, skip)
+		if err := newMissingError(res); err != nil {
+			errs = append(errs, err)
+		} else if res.Present {
+			if err := setField(v.Field(i), res.Value); err != nil {
+				errs = append(errs, fmt.Errorf("envreq: %s: %w", r.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// parseTag builds a Requirement from an envreq struct tag.
+func parseTag(tag, source string) (Requirement, error) {
+	parts := strings.Split(tag, ",")
+	r := Requirement{Name: strings.TrimSpace(parts[0]), Source: source}
+	if r.Name == "" {
+		return r, ErrEmptyName
+	}
+	for _, opt := range parts[1:] {
+		key, val, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch key {
+		case "required":
+			r.Optional = false
+		case "optional":
+			r.Optional = true
+		case "sensitive":
+			r.Sensitive = true
+		case "external":
+			r.External = true
+		case "default":
+			r.Default = val
+		case "source":
+			r.Source = val
+		case "owner":
+			r.Owner = val
+		case "example":
+			r.Example = val
+		case "docs":
+			r.DocsURL = val
+		case "oneof":
+			r.Validate = OneOf(strings.Split(val, "|")...)
+		case "validate":
+			fn, ok := tagValidators[val]
+			if !ok {
+				return r, fmt.Errorf("unknown validator %q", val)
+			}
+			r.Validate = fn
+		case "each":
+			fn, ok := tagValidators[val]
+			if !ok {
+				return r, fmt.Errorf("unknown validator %q", val)
+			}
+			r.List, r.ElementValidator = true, fn
+		case "transform":
+			var fs []func(string) string
+			for _, name := range strings.Split(val, "|") {
+				fn, ok := tagTransforms[name]
+				if !ok {
+					return r, fmt.Errorf("unknown transform %q", name)
+				}
+				fs = append(fs, fn)
+			}
+			r.Transform = Transforms(fs...)
+		default:
+			return r, fmt.Errorf("unknown tag option %q", key)
+		}
+	}
+	return r, nil
+}
+
+// setField parses s into the field fv.
+func setField(fv reflect.Value, s string) error {
+	switch fv.Type() {
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	case urlPtrType:
+		u, err := url.Parse(s)
+		if err != nil {
+			return redactURLError(err)
+		}
+		fv.Set(reflect.ValueOf(u))
+		return nil
+	case stringsType:
+		fv.Set(reflect.ValueOf(splitList(s)))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := parseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package envreq_test
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bbmumford/envreq"
+)
+
+type bindConfig struct {
+	APIKey  string        `envreq:"BIND_API_KEY,required,sensitive" desc:"Payment provider key"`
+	BaseURL *url.URL      `envreq:"BIND_URL,optional,default=https://api.example.com,validate=url"`
+	Timeout time.Duration `envreq:"BIND_TIMEOUT,optional,default=10s"`
+	Mode    string        `envreq:"BIND_MODE,optional,oneof=live|test,default=test,transform=trim|lower"`
+	Brokers []string      `envreq:"BIND_BROKERS,optional,each=hostport"`
+	Ignored string        `envreq:"-"`
+	DB      struct {
+		MaxConns int  `envreq:"BIND_DB_MAX_CONNS,optional,default=4"`
+		Debug    bool `envreq:"BIND_DB_DEBUG,optional"`
+	}
+}
+
+func TestBind(t *testing.T) {
+	g := envreq.New()
+	t.Setenv("BIND_API_KEY", "sk_test_123")
+	t.Setenv("BIND_DB_MAX_CONNS", "16")
+	t.Setenv("BIND_MODE", " Live ")
+	t.Setenv("BIND_BROKERS", "k1:9092, k2:9092")
+
+	var cfg bindConfig
+	if err := g.Bind(&cfg); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if cfg.APIKey != "sk_test_123" || cfg.BaseURL.Host != "api.example.com" ||
+		cfg.Timeout != 10*time.Second || cfg.Mode != "live" || cfg.DB.MaxConns != 16 || cfg.DB.Debug ||
+		len(cfg.Brokers) != 2 || cfg.Brokers[1] != "k2:9092" {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+
+	var key envreq.Result
+	for _, res := range g.CheckAll() {
+		if res.Name == "BIND_API_KEY" {
+			key = res
+		}
+	}
+	if !key.Sensitive || key.Optional || key.Description != "Payment provider key" || key.Source != "envreq_test" {
+		t.Errorf("Unexpected requirement from tags: %+v", key.Requirement)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	g := envreq.New()
+	t.Setenv("BIND_DB_MAX_CONNS", "lots")
+	t.Setenv("BIND_MODE", "staging")
+
+	var cfg bindConfig
+	err := g.Bind(&cfg)
+	if !errors.Is(err, envreq.ErrMissing) {
+		t.Errorf("Expected ErrMissing for BIND_API_KEY, got %v", err)
+	}
+	for _, name := range []string{"BIND_MODE", "BIND_DB_MAX_CONNS"} {
+		if err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("Expected an error for %s, got %v", name, err)
+		}
+	}
+
+	if err := g.Bind(cfg); err == nil {
+		t.Error("Expected an error for a non-pointer argument")
+	}
+	var bad struct {
+		X string `envreq:"BIND_X,validate=nope"`
+	}
+	if err := g.Bind(&bad); err == nil {
+		t.Error("Expected an error for an unknown validator")
+	}
+	var badTransform struct {
+		X string `envreq:"BIND_X,transform=trim|nope"`
+	}
+	if err := g.Bind(&badTransform); err == nil {
+		t.Error("Expected an error for an unknown transform")
+	}
+}
@@ -0,0 +1,116 @@
+package envreq
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"runtime/debug"
+	"strings"
+)
+
+// BuildInfo identifies the running build in reports and exports, so
+// configuration reports collected from a fleet can be attributed to exact
+// builds.
+type BuildInfo struct {
+	Service   string `json:"service,omitempty"`   // e.g. "payments-svc"
+	Version   string `json:"version,omitempty"`   // e.g. "1.4.2"
+	Commit    string `json:"commit,omitempty"`    // VCS revision, e.g. a git SHA
+	BuildTime string `json:"buildTime,omitempty"` // e.g. "2024-03-10T01:30:00Z"
+}
+
+// IsZero reports whether no field is set.
+func (b BuildInfo) IsZero() bool {
+	return b == BuildInfo{}
+}
+
+// String returns e.g. "payments-svc 1.4.2 (commit 3d45ed4, built
+// 2024-03-10T01:30:00Z)", leaving out unset fields.
+func (b BuildInfo) String() string {
+	var details []string
+	if b.Commit != "" {
+		details = append(details, "commit "+b.Commit)
+	}
+	if b.BuildTime != "" {
+		details = append(details, "built "+b.BuildTime)
+	}
+	s := strings.TrimSpace(b.Service + " " + b.Version)
+	if len(details) > 0 {
+		s = strings.TrimSpace(s + " (" + strings.Join(details, ", ") + ")")
+	}
+	return s
+}
+
+// SetBuildInfo attaches b to the reports and exports of the default
+// registry: the Report header, the JSON report, the debug handler and
+// snapshots. Fields left empty are filled from runtime/debug.ReadBuildInfo
+// where the toolchain recorded them (main module version, VCS revision and
+// time, main package name), so
+//
+//	envreq.SetBuildInfo(envreq.BuildInfo{Version: version}) // set with -ldflags -X
+//
+// is usually enough. The zero BuildInfo detaches it again.
+func SetBuildInfo(b BuildInfo) {
+	std.SetBuildInfo(b)
+}
+
+// SetBuildInfo attaches b to the registry's reports and exports. See the
+// package-level SetBuildInfo.
+func (g *Registry) SetBuildInfo(b BuildInfo) {
+	if !b.IsZero() {
+		b = withModuleInfo(b)
+	}
+	g.ioMu.Lock()
+	g.build = b
+	g.ioMu.Unlock()
+}
+
+// BuildInfo returns the build information set with SetBuildInfo.
+func (g *Registry) BuildInfo() BuildInfo {
+	g.ioMu.RLock()
+	defer g.ioMu.RUnlock()
+
+	return g.build
+}
+
+// withModuleInfo fills the empty fields of b from the binary's build
+// information.
+func withModuleInfo(b BuildInfo) BuildInfo {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+	if b.Service == "" && bi.Path != "" {
+		b.Service = path.Base(bi.Path)
+	}
+	if b.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		b.Version = bi.Main.Version
+	}
+	var revision string
+	modified := false
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.time":
+			if b.BuildTime == "" {
+				b.BuildTime = s.Value
+			}
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if b.Commit == "" && revision != "" {
+		b.Commit = revision
+		if modified {
+			b.Commit += "-dirty"
+		}
+	}
+	return b
+}
+
+// reportBuild writes the build header of a report.
+func reportBuild(w io.Writer, b BuildInfo) {
+	if !b.IsZero() {
+		fmt.Fprintf(w, "Build: %s\n\n", b)
+	}
+}
@@ -0,0 +1,77 @@
+// Package client is a Go client for the envreq introspection API served by
+// envreq.API. It follows the contract in envreq.OpenAPI.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bbmumford/envreq"
+)
+
+// Client calls the introspection API of one process.
+type Client struct {
+	BaseURL string       // scheme and host, e.g. "http://10.0.3.7:9090"
+	Token   string       // bearer token, if the server uses RequireToken
+	HTTP    *http.Client // nil means http.DefaultClient
+}
+
+// New returns a client for the API at baseURL.
+func New(baseURL, token string) *Client {
+	return &Client{BaseURL: baseURL, Token: token}
+}
+
+// Report returns the status of every registered variable.
+func (c *Client) Report(ctx context.Context) ([]envreq.ReportEntry, error) {
+	var entries []envreq.ReportEntry
+	err := c.get(ctx, "report", &entries)
+	return entries, err
+}
+
+// Schema returns the registered requirements.
+func (c *Client) Schema(ctx context.Context) (envreq.Schema, error) {
+	var s envreq.Schema
+	err := c.get(ctx, "schema", &s)
+	return s, err
+}
+
+// HotPaths returns the per-variable Check counts recorded after Freeze,
+// flagged entries first; empty unless the process enabled DetectHotPaths.
+func (c *Client) HotPaths(ctx context.Context) ([]envreq.HotPath, error) {
+	var paths []envreq.HotPath
+	err := c.get(ctx, "hotpaths", &paths)
+	return paths, err
+}
+
+// get fetches APIPrefix+endpoint and decodes the JSON response into v.
+func (c *Client) get(ctx context.Context, endpoint string, v any) error {
+	url := strings.TrimSuffix(c.BaseURL, "/") + envreq.APIPrefix + endpoint
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	hc := c.HTTP
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("envreq client: GET %s: %s: %s", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
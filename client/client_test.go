@@ -0,0 +1,70 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+	"github.com/bbmumford/envreq/client"
+)
+
+func TestClient(t *testing.T) {
+	t.Setenv("CLIENT_SECRET", "hunter2hunter2")
+	t.Setenv("CLIENT_TOKEN", "t0ken")
+
+	g := envreq.New()
+	g.Check(envreq.Requirement{Name: "CLIENT_SECRET", Source: "test", Sensitive: true})
+	g.Check(envreq.Requirement{Name: "CLIENT_MISSING", Source: "test"})
+
+	srv := httptest.NewServer(g.API(envreq.RequireToken("CLIENT_TOKEN")))
+	defer srv.Close()
+
+	c := client.New(srv.URL, "t0ken")
+	entries, err := c.Report(context.Background())
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	status := map[string]string{}
+	for _, e := range entries {
+		status[e.Name] = e.Status
+	}
+	if status["CLIENT_SECRET"] != envreq.StatusOK || status["CLIENT_MISSING"] != envreq.StatusMissing {
+		t.Errorf("Unexpected statuses: %v", status)
+	}
+
+	s, err := c.Schema(context.Background())
+	if err != nil || len(s.Vars) != 2 {
+		t.Errorf("Schema = %+v, %v", s, err)
+	}
+
+	g.DetectHotPaths(2)
+	g.Freeze()
+	for range 5 {
+		g.Check(envreq.Requirement{Name: "CLIENT_SECRET"})
+	}
+	paths, err := c.HotPaths(context.Background())
+	if err != nil || len(paths) != 1 || paths[0].Name != "CLIENT_SECRET" || paths[0].Calls != 5 || !paths[0].Flagged || paths[0].CallSite == "" {
+		t.Errorf("HotPaths = %+v, %v", paths, err)
+	}
+
+	if _, err := client.New(srv.URL, "wrong").Report(context.Background()); err == nil {
+		t.Error("Expected an error with a wrong token")
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	var doc struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(envreq.OpenAPI, &doc); err != nil {
+		t.Fatalf("OpenAPI document is not valid JSON: %v", err)
+	}
+	for _, p := range []string{"report", "schema", "hotpaths", "openapi.json"} {
+		if _, ok := doc.Paths[envreq.APIPrefix+p]["get"]; !ok {
+			t.Errorf("OpenAPI document does not describe GET %s%s", envreq.APIPrefix, p)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+)
+
+// runAccessors implements "envreq accessors [-pkg config] [-o env.go] schema.json".
+func runAccessors(args []string) error {
+	fs := flag.NewFlagSet("accessors", flag.ExitOnError)
+	pkg := fs.String("pkg", "config", "package name of the generated file")
+	out := fs.String("o", "", "write to this file instead of stdout")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("expected exactly one schema file (or - for stdin)")
+	}
+
+	schema, err := loadSchema(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return schema.WriteAccessors(os.Stdout, *pkg)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := schema.WriteAccessors(f, *pkg); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/bbmumford/envreq"
+)
+
+// runCompare implements
+// "envreq compare -schema schema.json [-all] env1.json env2.json ...".
+//
+// Each environment file is a JSON object of variable names to values, e.g.
+// an exported env dump of staging or production. Every schema variable is
+// evaluated against each environment and a matrix of differences is
+// printed: missing, invalid, or set with a value fingerprint, so differing
+// values stand out without revealing them. Values are validated with the
+// schema's validators where SchemaVar.Requirement can resolve them.
+func runCompare(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	schemaPath := fs.String("schema", "", "schema file (or - for stdin)")
+	all := fs.Bool("all", false, "show every variable, not only those that differ")
+	fs.Parse(args)
+
+	if *schemaPath == "" || fs.NArg() < 2 {
+		return errors.New("expected -schema and at least two environment files")
+	}
+	schema, err := loadSchema(*schemaPath)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, fs.NArg())
+	cells := make([]map[string]string, fs.NArg())
+	for i, path := range fs.Args() {
+		env, err := loadEnvMap(path)
+		if err != nil {
+			return err
+		}
+		names[i] = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		cells[i] = evaluate(schema, env)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "VARIABLE\tREQUIRED\t%s\n", strings.Join(names, "\t"))
+	differ := 0
+	for _, v := range schema.Vars {
+		row := make([]string, len(cells))
+		same := true
+		for i, c := range cells {
+			row[i] = c[v.Name]
+			same = same && row[i] == row[0]
+		}
+		if !same {
+			differ++
+		}
+		if same && !*all {
+			continue
+		}
+		required := "no"
+		if v.Required {
+			required = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", v.Name, required, strings.Join(row, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d of %d variable(s) differ\n", differ, len(schema.Vars))
+	return nil
+}
+
+// evaluate resolves the schema against env alone and returns a cell per
+// variable: "missing", "invalid", "default", or "set <fingerprint>".
+func evaluate(schema envreq.Schema, env map[string]string) map[string]string {
+	g := envreq.New()
+	g.SetSources(envreq.NamedSource("file", envreq.MapSource(env)))
+	for _, v := range schema.Vars {
+		g.Check(v.Requirement())
+	}
+
+	cells := map[string]string{}
+	for _, v := range g.TakeSnapshot().Vars {
+		switch {
+		case v.Status != envreq.StatusOK:
+			cells[v.Name] = v.Status
+		case v.Provenance == "":
+			cells[v.Name] = "unset"
+		case v.Provenance == envreq.ProvenanceDefault:
+			cells[v.Name] = "default"
+		default:
+			value, _ := g.Value(v.Name)
+			cells[v.Name] = "set " + digest(value)
+		}
+	}
+	return cells
+}
+
+// runKey keys the fingerprints of one run: they can be compared across the
+// columns of its output, but not matched against guessed values later.
+var runKey = sync.OnceValue(func() []byte {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+})
+
+// digest returns a short fingerprint of v under runKey.
+func digest(v string) string {
+	mac := hmac.New(sha256.New, runKey())
+	mac.Write([]byte(v))
+	return hex.EncodeToString(mac.Sum(nil)[:3])
+}
+
+// loadEnvMap reads a JSON object of variable names to values.
+func loadEnvMap(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var env map[string]string
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("%s: expected a JSON object of strings: %w", path, err)
+	}
+	return env, nil
+}
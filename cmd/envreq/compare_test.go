@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestEvaluate(t *testing.T) {
+	schema := envreq.Schema{Vars: []envreq.SchemaVar{
+		{Name: "CMP_URL", Required: true, Validator: "envreq.URL"},
+		{Name: "CMP_PORT", Default: "8080", Validator: "envreq.Port"},
+		{Name: "CMP_TOKEN", Required: true},
+		{Name: "CMP_LABEL"},
+	}}
+	cells := evaluate(schema, map[string]string{"CMP_URL": "nope", "CMP_PORT": "9090"})
+	for _, tc := range []struct {
+		name, want string
+	}{
+		{"CMP_URL", envreq.StatusInvalid},
+		{"CMP_TOKEN", envreq.StatusMissing},
+		{"CMP_LABEL", "unset"},
+	} {
+		if got := cells[tc.name]; got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	if port := cells["CMP_PORT"]; !strings.HasPrefix(port, "set ") || strings.Contains(port, "9090") {
+		t.Errorf("CMP_PORT: expected a fingerprint, got %q", port)
+	}
+
+	again := evaluate(schema, map[string]string{"CMP_URL": "https://api.example.com", "CMP_PORT": "9090", "CMP_TOKEN": "t"})
+	if !strings.HasPrefix(again["CMP_URL"], "set ") || again["CMP_PORT"] != cells["CMP_PORT"] {
+		t.Errorf("Expected a valid URL and the same port fingerprint, got %v", again)
+	}
+	if cells := evaluate(schema, map[string]string{"CMP_PORT": "http"}); cells["CMP_PORT"] != envreq.StatusInvalid {
+		t.Errorf("Expected an invalid port, got %q", cells["CMP_PORT"])
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bbmumford/envreq"
+)
+
+// shells maps the -shell names of the env command to syntaxes.
+var shells = map[string]envreq.Shell{
+	"sh":         envreq.ShellPOSIX,
+	"bash":       envreq.ShellPOSIX,
+	"zsh":        envreq.ShellPOSIX,
+	"fish":       envreq.ShellFish,
+	"powershell": envreq.ShellPowerShell,
+	"pwsh":       envreq.ShellPowerShell,
+}
+
+// runEnv implements
+// "envreq env [-profile dev] [-dotenv .env.local,.env] [-shell sh] schema.json".
+//
+// The schema's variables are resolved from the environment and dotenv
+// files, validated with the validators SchemaVar.Requirement resolves by
+// name, and written as commands for the shell to evaluate:
+//
+//	eval "$(envreq env -profile dev schema.json)"
+//	envreq env -shell fish -profile dev schema.json | source
+//	envreq env -shell powershell -profile dev schema.json | Out-String | Invoke-Expression
+//
+// With -profile dev the dotenv files are .env.dev.local, .env.dev,
+// .env.local and .env, highest precedence first. Sensitive variables are
+// left out. Validators that cannot be resolved (custom functions and
+// factories) are named on stderr and not checked. When a required
+// variable is missing or invalid the report goes to stderr and nothing is
+// exported.
+func runEnv(args []string) error {
+	fs := flag.NewFlagSet("env", flag.ExitOnError)
+	profile := fs.String("profile", "", "deployment profile, e.g. dev; selects .env.<profile> files")
+	dotenv := fs.String("dotenv", "", "comma-separated dotenv files, highest precedence first (default: by profile)")
+	shell := fs.String("shell", "sh", "output syntax: sh (also bash, zsh), fish or powershell (also pwsh)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("expected exactly one schema file (or - for stdin)")
+	}
+	syntax, ok := shells[*shell]
+	if !ok {
+		return fmt.Errorf("unknown shell %q", *shell)
+	}
+	schema, err := loadSchema(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *profile != "" {
+		envreq.SetProfile(*profile)
+	}
+	files := envreq.DefaultDotenvFiles
+	switch {
+	case *dotenv != "":
+		files = strings.Split(*dotenv, ",")
+	case *profile != "":
+		files = append([]string{".env." + *profile + ".local", ".env." + *profile}, files...)
+	}
+
+	g := envreq.New()
+	if err := g.LoadDotenv(files...); err != nil {
+		return err
+	}
+	for _, v := range schema.Vars {
+		r := v.Requirement()
+		if v.Validator != "" && r.Validate == nil {
+			fmt.Fprintf(os.Stderr, "envreq: %s: validator %s not checked\n", v.Name, v.Validator)
+		}
+		if v.ElementValidator != "" && r.ElementValidator == nil {
+			fmt.Fprintf(os.Stderr, "envreq: %s: element validator %s not checked\n", v.Name, v.ElementValidator)
+		}
+		g.Check(r)
+	}
+
+	if err := g.ExportShellAs(os.Stdout, syntax); err != nil {
+		g.Report(os.Stderr)
+		return err
+	}
+	return nil
+}
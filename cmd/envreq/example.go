@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+)
+
+// runExample implements "envreq example [-o .env.example] schema.json".
+func runExample(args []string) error {
+	fs := flag.NewFlagSet("example", flag.ExitOnError)
+	out := fs.String("o", "", "write to this file instead of stdout")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("expected exactly one schema file (or - for stdin)")
+	}
+
+	schema, err := loadSchema(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return schema.WriteExample(os.Stdout)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := schema.WriteExample(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
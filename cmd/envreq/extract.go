@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bbmumford/envreq/extract"
+)
+
+// runExtract implements "envreq extract [-tests] [-strict] [-names pkg] [dir]":
+// it prints the schema found statically in the source tree, without running
+// anything. With -names, it fails unless every variable name is a constant
+// of that package and resolves to a value.
+func runExtract(args []string) error {
+	fs := flag.NewFlagSet("extract", flag.ExitOnError)
+	tests := fs.Bool("tests", false, "include _test.go files")
+	strict := fs.Bool("strict", false, "fail when a Requirement cannot be resolved statically")
+	names := fs.String("names", "", "import path of the package whose constants must be used as variable names")
+	fs.Parse(args)
+
+	root := "."
+	if fs.NArg() > 0 {
+		root = fs.Arg(0)
+	}
+
+	schema, issues, err := extract.Dir(root, *tests)
+	if err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stderr, "envreq extract: %s\n", issue)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(schema); err != nil {
+		return err
+	}
+
+	if *strict && len(issues) > 0 {
+		return fmt.Errorf("%d requirement(s) could not be resolved statically", len(issues))
+	}
+
+	if *names != "" {
+		skipped := 0
+		for _, issue := range issues {
+			if issue.Skipped {
+				skipped++
+			}
+		}
+		if skipped > 0 {
+			return fmt.Errorf("%d requirement(s) left out of the schema because their name could not be resolved", skipped)
+		}
+		nameIssues, err := extract.CheckNames(root, *names, *tests)
+		if err != nil {
+			return err
+		}
+		for _, issue := range nameIssues {
+			fmt.Fprintf(os.Stderr, "envreq extract: %s\n", issue)
+		}
+		if len(nameIssues) > 0 {
+			return fmt.Errorf("%d variable name(s) are not constants from %s", len(nameIssues), *names)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Command envreq inspects envreq schemas outside of the running service.
+//
+// Usage:
+//
+//	envreq <command> [flags] [args]
+//
+// Commands:
+//
+//	accessors  generate a Go package of typed accessors from a schema
+//	compare    compare environments against a schema
+//	env        print the validated environment as shell export lines
+//	example    render a schema as a .env.example file
+//	extract    print the schema found statically in Go source (no execution)
+//	markdown   render a schema as Markdown documentation tables
+//	score      compute the configuration completeness score of a schema
+//	workspace  merge the environment inventory of every module in a workspace
+//
+// Schemas are the JSON documents written by envreq.WriteSchema.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bbmumford/envreq"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"accessors", "generate a Go package of typed accessors from a schema", runAccessors},
+	{"compare", "compare environments against a schema", runCompare},
+	{"env", "print the validated environment as shell export lines", runEnv},
+	{"example", "render a schema as a .env.example file", runExample},
+	{"extract", "print the schema found statically in Go source (no execution)", runExtract},
+	{"markdown", "render a schema as Markdown documentation tables", runMarkdown},
+	{"score", "compute the configuration completeness score of a schema", runScore},
+	{"workspace", "merge the environment inventory of every module in a workspace", runWorkspace},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	// The CLI only handles schemas; intern their repetitive metadata
+	envreq.CompactMemory(true)
+
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "envreq %s: %v\n", c.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "envreq: unknown command %q\n\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: envreq <command> [flags] [args]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.summary)
+	}
+}
+
+// loadSchema reads a schema file, or stdin when path is "-".
+func loadSchema(path string) (envreq.Schema, error) {
+	if path == "-" {
+		return envreq.ReadSchema(os.Stdin)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return envreq.Schema{}, err
+	}
+	defer f.Close()
+
+	return envreq.ReadSchema(f)
+}
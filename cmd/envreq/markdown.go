@@ -0,0 +1,23 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+)
+
+// runMarkdown implements "envreq markdown schema.json".
+func runMarkdown(args []string) error {
+	fs := flag.NewFlagSet("markdown", flag.ExitOnError)
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("expected exactly one schema file (or - for stdin)")
+	}
+
+	schema, err := loadSchema(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return schema.WriteMarkdown(os.Stdout)
+}
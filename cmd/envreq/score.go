@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runScore implements "envreq score [-format text|badge] [-min 0.8] schema.json".
+func runScore(args []string) error {
+	fs := flag.NewFlagSet("score", flag.ExitOnError)
+	format := fs.String("format", "text", "output format: text or badge (shields.io endpoint JSON)")
+	minRatio := fs.Float64("min", 0, "fail when the score ratio is below this value (0-1), for CI gates")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("expected exactly one schema file (or - for stdin)")
+	}
+
+	schema, err := loadSchema(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	sc := schema.Score()
+
+	switch *format {
+	case "text":
+		fmt.Println(sc)
+	case "badge":
+		if err := sc.WriteBadge(os.Stdout); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	if sc.Ratio() < *minRatio {
+		return fmt.Errorf("score %.2f is below the required %.2f", sc.Ratio(), *minRatio)
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bbmumford/envreq"
+	"github.com/bbmumford/envreq/extract"
+)
+
+// errNotDescribed reports a main package that exited without writing a schema.
+var errNotDescribed = errors.New("no schema written")
+
+// inventoryVar is a schema variable merged across modules.
+type inventoryVar struct {
+	envreq.SchemaVar
+	Modules []string `json:"modules"`
+}
+
+// runWorkspace implements "envreq workspace [-format table|json] [-static] [root]".
+//
+// It discovers the modules of a Go workspace (go.work "use" directives, or
+// every go.mod below root), runs each main package in describe mode (or,
+// with -static, extracts requirements from source) and merges the resulting
+// schemas into one inventory attributed per module.
+func runWorkspace(args []string) error {
+	fs := flag.NewFlagSet("workspace", flag.ExitOnError)
+	format := fs.String("format", "table", "output format: table or json")
+	timeout := fs.Duration("timeout", time.Minute, "time limit for each describe run")
+	static := fs.Bool("static", false, "extract requirements from source instead of running describe mode")
+	fs.Parse(args)
+
+	root := "."
+	if fs.NArg() > 0 {
+		root = fs.Arg(0)
+	}
+
+	modules, err := findModules(root)
+	if err != nil {
+		return err
+	}
+	if len(modules) == 0 {
+		return fmt.Errorf("no Go modules found under %s", root)
+	}
+
+	inv := map[string]*inventoryVar{}
+	for _, dir := range modules {
+		var schemas []envreq.Schema
+		if *static {
+			var s envreq.Schema
+			s, _, err = extract.Dir(dir, false)
+			schemas = []envreq.Schema{s}
+		} else {
+			schemas, err = describeModule(dir, *timeout)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "envreq workspace: %s: %v\n", dir, err)
+			continue
+		}
+		rel, _ := filepath.Rel(root, dir)
+		for _, s := range schemas {
+			mergeInventory(inv, filepath.ToSlash(rel), s)
+		}
+	}
+
+	vars := make([]*inventoryVar, 0, len(inv))
+	for _, v := range inv {
+		sort.Strings(v.Modules)
+		vars = append(vars, v)
+	}
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].Name < vars[j].Name
+	})
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(vars)
+	case "table":
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ENV\tREQUIRED\tSENSITIVE\tMODULES\tDESCRIPTION")
+		for _, v := range vars {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", v.Name, yesNo(v.Required), yesNo(v.Sensitive),
+				strings.Join(v.Modules, ","), v.Description)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+}
+
+// mergeInventory folds one module's schema into inv using the same
+// stricter-wins rules as the runtime registry.
+func mergeInventory(inv map[string]*inventoryVar, module string, s envreq.Schema) {
+	for _, sv := range s.Vars {
+		v, ok := inv[sv.Name]
+		if !ok {
+			inv[sv.Name] = &inventoryVar{SchemaVar: sv, Modules: []string{module}}
+			continue
+		}
+		v.Required = v.Required || sv.Required
+		v.Sensitive = v.Sensitive || sv.Sensitive
+		v.Validated = v.Validated || sv.Validated
+		if v.Description == "" {
+			v.Description = sv.Description
+		}
+		if v.Owner == "" {
+			v.Owner = sv.Owner
+		}
+		if v.Example == "" {
+			v.Example = sv.Example
+		}
+		if v.Default == "" && !v.Sensitive {
+			v.Default = sv.Default
+		}
+		if v.Sensitive {
+			v.Default = ""
+		}
+		if !slices.Contains(v.Modules, module) {
+			v.Modules = append(v.Modules, module)
+		}
+	}
+}
+
+// findModules returns module directories listed in root/go.work, or every
+// directory below root containing a go.mod.
+func findModules(root string) ([]string, error) {
+	if mods, err := workModules(root); err == nil {
+		return mods, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	var mods []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != root {
+			name := d.Name()
+			if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata" {
+				return filepath.SkipDir
+			}
+		}
+		if !d.IsDir() && d.Name() == "go.mod" {
+			mods = append(mods, filepath.Dir(path))
+		}
+		return nil
+	})
+	return mods, err
+}
+
+// workModules parses the "use" directives of root/go.work.
+func workModules(root string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(root, "go.work"))
+	if err != nil {
+		return nil, err
+	}
+
+	var mods []string
+	inBlock := false
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		switch {
+		case line == "use (":
+			inBlock = true
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			mods = append(mods, filepath.Join(root, strings.Trim(line, `"`)))
+		case strings.HasPrefix(line, "use "):
+			mods = append(mods, filepath.Join(root, strings.Trim(strings.TrimSpace(line[4:]), `"`)))
+		}
+	}
+	return mods, sc.Err()
+}
+
+// describeModule runs every main package of the module in describe mode and
+// returns their schemas.
+func describeModule(dir string, timeout time.Duration) ([]envreq.Schema, error) {
+	out, err := exec.Command("go", "list", "-C", dir, "-f", `{{if eq .Name "main"}}{{.ImportPath}}{{end}}`, "./...").Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w", err)
+	}
+
+	var schemas []envreq.Schema
+	for _, pkg := range strings.Fields(string(out)) {
+		s, err := describePackage(dir, pkg, timeout)
+		if errors.Is(err, errNotDescribed) {
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "envreq workspace: %s: %v\n", pkg, err)
+			continue
+		}
+		schemas = append(schemas, s)
+	}
+	return schemas, nil
+}
+
+// describePackage runs one main package with ENVREQ_DESCRIBE pointing at a
+// temporary file and reads back the schema it wrote.
+func describePackage(dir, pkg string, timeout time.Duration) (envreq.Schema, error) {
+	tmp, err := os.CreateTemp("", "envreq-describe-*.json")
+	if err != nil {
+		return envreq.Schema{}, err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "go", "run", pkg)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "ENVREQ_DESCRIBE="+tmp.Name())
+	out, runErr := cmd.CombinedOutput()
+
+	data, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return envreq.Schema{}, err
+	}
+	if len(data) == 0 {
+		if runErr != nil && ctx.Err() != nil {
+			return envreq.Schema{}, fmt.Errorf("describe run timed out after %s", timeout)
+		}
+		// The program never reached MustValidate: not an envreq user
+		return envreq.Schema{}, errNotDescribed
+	}
+	if runErr != nil {
+		fmt.Fprintf(os.Stderr, "envreq workspace: %s: %v\n%s", pkg, runErr, out)
+	}
+
+	return envreq.ReadSchema(bytes.NewReader(data))
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
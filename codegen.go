@@ -0,0 +1,149 @@
+package envreq
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// accessorInitialisms are name parts written in upper case in generated
+// identifiers, following Go naming conventions.
+var accessorInitialisms = map[string]bool{
+	"API": true, "AWS": true, "CPU": true, "DB": true, "DNS": true, "GCP": true,
+	"HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true, "JWT": true,
+	"SQL": true, "SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true,
+	"UI": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// WriteAccessors generates the Go source of package pkg with one accessor
+// function per variable, so call sites use compile-time-checked names
+// instead of strings:
+//
+//	//go:generate envreq accessors -pkg config -o config/env.go schema.json
+//
+//	key := config.StripeAPIKey() // registered by the generated package
+//
+// The generated init registers every variable with its schema metadata, so
+// it is merged with the owning package's Requirement like any other
+// registration; validators stay with the owning package. Accessors return
+// the cached value ("" when unset), and a constant holds each name, e.g.
+// StripeAPIKeyName. Variable names that map to the same identifier are an
+// error.
+func (s Schema) WriteAccessors(w io.Writer, pkg string) error {
+	vars := append([]SchemaVar(nil), s.Vars...)
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].Name < vars[j].Name
+	})
+
+	idents := make([]string, len(vars))
+	seen := map[string]string{}
+	for i, v := range vars {
+		id := accessorIdent(v.Name)
+		if prev, ok := seen[id]; ok {
+			return fmt.Errorf("envreq: accessors: %s and %s both map to %s", prev, v.Name, id)
+		}
+		seen[id] = v.Name
+		idents[i] = id
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by envreq accessors; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	fmt.Fprintf(&b, "import %q\n\n", "github.com/bbmumford/envreq")
+
+	b.WriteString("// Variable names.\nconst (\n")
+	for i, v := range vars {
+		fmt.Fprintf(&b, "%sName = %q\n", idents[i], v.Name)
+	}
+	b.WriteString(")\n\n")
+
+	b.WriteString("func init() {\n")
+	for i, v := range vars {
+		fmt.Fprintf(&b, "envreq.Check(envreq.Requirement{\nName: %sName,\n", idents[i])
+		writeField(&b, "Source", v.Source)
+		writeField(&b, "Description", v.Description)
+		writeField(&b, "Owner", v.Owner)
+		writeField(&b, "Example", v.Example)
+		writeField(&b, "DocsURL", v.DocsURL)
+		writeField(&b, "Default", v.Default)
+		if !v.Required {
+			b.WriteString("Optional: true,\n")
+		}
+		if v.Sensitive {
+			b.WriteString("Sensitive: true,\n")
+		}
+		if v.NeverShow {
+			b.WriteString("NeverShow: true,\n")
+		}
+		if v.Deprecated {
+			b.WriteString("Deprecated: true,\n")
+		}
+		if v.External {
+			b.WriteString("External: true,\n")
+		}
+		writeField(&b, "ReplacedBy", v.ReplacedBy)
+		if len(v.Aliases) > 0 {
+			quoted := make([]string, len(v.Aliases))
+			for j, a := range v.Aliases {
+				quoted[j] = strconv.Quote(a)
+			}
+			fmt.Fprintf(&b, "Aliases: []string{%s},\n", strings.Join(quoted, ", "))
+		}
+		b.WriteString("})\n")
+	}
+	b.WriteString("}\n")
+
+	for i, v := range vars {
+		fmt.Fprintf(&b, "\n// %s returns the value of %s.\n", idents[i], v.Name)
+		for _, line := range strings.Split(v.Description, "\n") {
+			if line != "" {
+				b.WriteString("// " + line + "\n")
+			}
+		}
+		fmt.Fprintf(&b, "func %s() string {\nv, _ := envreq.Value(%sName)\nreturn v\n}\n", idents[i], idents[i])
+	}
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("envreq: accessors: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// writeField writes a string field of a generated Requirement literal,
+// omitting empty values.
+func writeField(b *bytes.Buffer, field, value string) {
+	if value != "" {
+		fmt.Fprintf(b, "%s: %q,\n", field, value)
+	}
+}
+
+// accessorIdent converts a variable name to an exported Go identifier,
+// e.g. STRIPE_API_KEY to StripeAPIKey.
+func accessorIdent(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, p := range parts {
+		up := strings.ToUpper(p)
+		if accessorInitialisms[up] {
+			b.WriteString(up)
+			continue
+		}
+		lower := []rune(strings.ToLower(p))
+		lower[0] = unicode.ToUpper(lower[0])
+		b.WriteString(string(lower))
+	}
+	id := b.String()
+	if id == "" || !unicode.IsLetter([]rune(id)[0]) {
+		id = "Var" + id
+	}
+	return id
+}
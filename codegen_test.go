@@ -0,0 +1,43 @@
+package envreq_test
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestWriteAccessors(t *testing.T) {
+	schema := envreq.Schema{Vars: []envreq.SchemaVar{
+		{Name: "STRIPE_API_KEY", Source: "payments", Description: "Stripe secret key", Required: true, Sensitive: true},
+		{Name: "PUBLIC_BASE_URL", Default: "http://localhost", Aliases: []string{"BASE_URL"}},
+	}}
+
+	var buf bytes.Buffer
+	if err := schema.WriteAccessors(&buf, "config"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "env.go", buf.Bytes(), 0); err != nil {
+		t.Fatalf("Generated code does not parse: %v\n%s", err, buf.String())
+	}
+	for _, want := range []string{
+		"package config",
+		`StripeAPIKeyName  = "STRIPE_API_KEY"`,
+		"func StripeAPIKey() string",
+		"func PublicBaseURL() string",
+		`Aliases:  []string{"BASE_URL"}`,
+		"// Stripe secret key",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected %q in the generated code:\n%s", want, buf.String())
+		}
+	}
+
+	clash := envreq.Schema{Vars: []envreq.SchemaVar{{Name: "DB_URL"}, {Name: "DB__URL"}}}
+	if err := clash.WriteAccessors(&buf, "config"); err == nil {
+		t.Error("Expected an error for names mapping to the same identifier")
+	}
+}
@@ -0,0 +1,27 @@
+package envreq
+
+// concealedError hides the message of a validator error for a NeverShow
+// variable, since validators may quote the value ("invalid URL: parse
+// \"postgres://...\""). errors.Is and errors.As still see the original.
+type concealedError struct {
+	err error
+}
+
+func (e concealedError) Error() string {
+	return "value rejected by its validator (details withheld: NeverShow)"
+}
+
+func (e concealedError) Unwrap() error {
+	return e.err
+}
+
+// conceal wraps err for NeverShow requirements.
+func conceal(r Requirement, err error) error {
+	if err == nil || !r.NeverShow {
+		return err
+	}
+	if _, ok := err.(concealedError); ok {
+		return err
+	}
+	return concealedError{err}
+}
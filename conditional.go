@@ -0,0 +1,108 @@
+package envreq
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Condition is a predicate over other variables that makes a Requirement
+// required, set as Requirement.RequiredIf. Build one with Equals or IsSet.
+type Condition struct {
+	desc  string
+	holds func(lookup func(name string) (string, bool)) bool
+}
+
+// Equals returns a Condition that holds when the variable name has one of
+// values, e.g. Equals("APP_ENV", "production", "staging"). The variable is
+// resolved like any other, including its registered Default.
+func Equals(name string, values ...string) Condition {
+	return Condition{
+		desc: name + "=" + strings.Join(values, "|"),
+		holds: func(lookup func(string) (string, bool)) bool {
+			v, ok := lookup(name)
+			if !ok {
+				return false
+			}
+			for _, want := range values {
+				if v == want {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// IsSet returns a Condition that holds when the variable name has a
+// non-empty value, e.g. a TLS key that is required once the certificate is
+// configured.
+func IsSet(name string) Condition {
+	return Condition{
+		desc: name + " is set",
+		holds: func(lookup func(string) (string, bool)) bool {
+			v, ok := lookup(name)
+			return ok && v != ""
+		},
+	}
+}
+
+// String describes the condition, e.g. "APP_ENV=production".
+func (c Condition) String() string {
+	return c.desc
+}
+
+// conditional reports whether r is required only under a condition.
+func (r Requirement) conditional() bool {
+	return r.RequiredIf.holds != nil || r.RequiredWhen != nil
+}
+
+// condition describes when a conditional r is required, e.g.
+// "APP_ENV=production or envreq.IsDevelopment", or "" when r is not
+// conditional.
+func (r Requirement) condition() string {
+	var parts []string
+	if r.RequiredIf.holds != nil {
+		parts = append(parts, r.RequiredIf.desc)
+	}
+	if r.RequiredWhen != nil {
+		name := funcName(reflect.ValueOf(r.RequiredWhen).Pointer())
+		if name == "" {
+			name = "RequiredWhen"
+		}
+		parts = append(parts, name)
+	}
+	return strings.Join(parts, " or ")
+}
+
+// required reports whether r is currently required: for a conditional
+// requirement, whether RequiredIf or RequiredWhen holds; otherwise whether
+// it is not Optional.
+func (g *Registry) required(r Requirement) bool {
+	if !r.conditional() {
+		return !r.Optional
+	}
+	if r.RequiredWhen != nil && r.RequiredWhen() {
+		return true
+	}
+	return r.RequiredIf.holds != nil && r.RequiredIf.holds(g.conditionValue)
+}
+
+// conditionValue resolves the variable name for a Condition, using its
+// registered requirement (and so its Default) when there is one.
+func (g *Registry) conditionValue(name string) (string, bool) {
+	rs := g.resolve(g.requirement(name))
+	return rs.val, rs.ok
+}
+
+// mergeCondition merges the conditions of a new registration r into the
+// registry entry merged (stricter wins): an unconditionally required
+// registration makes the variable unconditionally required.
+func mergeCondition(merged *Requirement, existing, r Requirement) {
+	switch {
+	case !existing.Optional && !existing.conditional(), !r.Optional && !r.conditional():
+		merged.RequiredIf, merged.RequiredWhen = Condition{}, nil
+		merged.Optional = false
+	case !merged.conditional():
+		merged.RequiredIf, merged.RequiredWhen = r.RequiredIf, r.RequiredWhen
+	}
+}
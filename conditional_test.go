@@ -0,0 +1,83 @@
+package envreq_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestRequiredIf(t *testing.T) {
+	webhook := envreq.Requirement{
+		Name:       "COND_WEBHOOK_SECRET",
+		Source:     "payments",
+		Optional:   true,
+		RequiredIf: envreq.Equals("COND_APP_ENV", "production", "staging"),
+	}
+
+	// The condition variable's registered default applies
+	g := envreq.New()
+	g.Check(envreq.Requirement{Name: "COND_APP_ENV", Source: "app", Default: "development"})
+	res := g.Check(webhook)
+	if err := g.Validate(); !res.Optional || err != nil {
+		t.Errorf("Expected COND_WEBHOOK_SECRET to be optional in development, got %+v (%v)", res, err)
+	}
+
+	t.Setenv("COND_APP_ENV", "production")
+	g = envreq.New()
+	res = g.Check(webhook)
+	if res.Optional {
+		t.Error("Expected COND_WEBHOOK_SECRET to be required in production")
+	}
+	var verr *envreq.ValidationError
+	if err := g.Validate(); !errors.As(err, &verr) || len(verr.Problems) != 1 {
+		t.Errorf("Expected COND_WEBHOOK_SECRET to fail validation, got %v", err)
+	}
+	if cached := g.Check(webhook); cached.Optional {
+		t.Error("Expected the cached result to keep the condition outcome")
+	}
+
+	var buf bytes.Buffer
+	g.Report(&buf)
+	if !strings.Contains(buf.String(), "[required if COND_APP_ENV=production|staging]") {
+		t.Errorf("Expected the condition in the report:\n%s", buf.String())
+	}
+
+	s := g.Describe()
+	if v := s.Vars[0]; v.Name != "COND_WEBHOOK_SECRET" || v.Required || v.RequiredIf != "COND_APP_ENV=production|staging" {
+		t.Errorf("Expected a conditional schema entry, got %+v", v)
+	}
+}
+
+func TestRequiredWhen(t *testing.T) {
+	tlsOn := false
+	g := envreq.New()
+	key := envreq.Requirement{
+		Name:         "COND_TLS_KEY",
+		Source:       "server",
+		RequiredWhen: func() bool { return tlsOn },
+		RequiredIf:   envreq.IsSet("COND_TLS_CERT"),
+	}
+	if res := g.Check(key); !res.Optional {
+		t.Error("Expected COND_TLS_KEY to be optional without TLS")
+	}
+
+	tlsOn = true
+	if _, err := g.Revalidate(); err == nil {
+		t.Error("Expected COND_TLS_KEY to be required once RequiredWhen holds")
+	}
+
+	tlsOn = false
+	t.Setenv("COND_TLS_CERT", "/etc/tls/cert.pem")
+	if _, err := g.Revalidate(); err == nil {
+		t.Error("Expected COND_TLS_KEY to be required once COND_TLS_CERT is set")
+	}
+
+	// An unconditionally required registration wins
+	g.Check(envreq.Requirement{Name: "COND_TLS_KEY", Source: "other"})
+	if v := g.Describe().Vars[0]; !v.Required || v.RequiredIf != "" {
+		t.Errorf("Expected COND_TLS_KEY to become unconditionally required, got %+v", v)
+	}
+}
@@ -0,0 +1,448 @@
+package envreq
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConstraintError is a violated registry-level constraint over several
+// variables, e.g. a TLS certificate configured without its key.
+type ConstraintError struct {
+	Names []string // variables involved
+	Err   error    // what is wrong
+	Unset []string // for AllOrNone: the variables of the partially set group that are not set
+}
+
+func (e *ConstraintError) Error() string {
+	return "envreq: " + e.Err.Error()
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// constraint is a check over several variables, run by Validate and
+// Report. names is nil for cross-checks, which are attributed to the
+// variables they look up. Deep checks, of stage StageDeep, are only run
+// by Validate; the zero stage means StageConstraints.
+type constraint struct {
+	names    []string
+	check    func(lookup func(name string) Result) error
+	stage    Stage
+	timeout  time.Duration // deep checks: budget, see DeepTimeout
+	severity Severity      // deep checks: see DeepSeverity; 0 means SeverityError
+}
+
+// AtLeastOneOf requires at least one of the variables to be set, e.g.
+// AtLeastOneOf("DATABASE_URL", "DB_HOST") for alternative configurations.
+// Like Check, it is meant to be called during initialization; the
+// constraint is evaluated by Validate, MustValidate and the registry's
+// Report. The variables need not be registered.
+func AtLeastOneOf(names ...string) {
+	std.AtLeastOneOf(names...)
+}
+
+// AtLeastOneOf adds an at-least-one-of constraint to the registry.
+func (g *Registry) AtLeastOneOf(names ...string) {
+	g.addConstraint(names, func(set func(string) bool) error {
+		for _, n := range names {
+			if set(n) {
+				return nil
+			}
+		}
+		return fmt.Errorf("at least one of %s must be set", strings.Join(names, ", "))
+	})
+}
+
+// ExactlyOneOf requires exactly one of the variables to be set, e.g.
+// ExactlyOneOf("DATABASE_URL", "DB_HOST") when setting both is ambiguous.
+func ExactlyOneOf(names ...string) {
+	std.ExactlyOneOf(names...)
+}
+
+// ExactlyOneOf adds an exactly-one-of constraint to the registry.
+func (g *Registry) ExactlyOneOf(names ...string) {
+	g.addConstraint(names, func(set func(string) bool) error {
+		have := setNames(names, set)
+		if len(have) == 1 {
+			return nil
+		}
+		if len(have) == 0 {
+			return fmt.Errorf("exactly one of %s must be set (none is)", strings.Join(names, ", "))
+		}
+		return fmt.Errorf("exactly one of %s must be set (%s are)", strings.Join(names, ", "), strings.Join(have, ", "))
+	})
+}
+
+// AllOrNone requires the variables to be set together or not at all,
+// e.g. AllOrNone("SMTP_HOST", "SMTP_PORT", "SMTP_USER", "SMTP_PASSWORD")
+// for a feature configured by a group of variables. The registry's
+// reports show every member of a partially set group as incomplete rather
+// than a mix of ok and missing rows.
+func AllOrNone(names ...string) {
+	std.AllOrNone(names...)
+}
+
+// AllOrNone adds an all-or-none constraint to the registry.
+func (g *Registry) AllOrNone(names ...string) {
+	g.addConstraint(names, func(set func(string) bool) error {
+		have := setNames(names, set)
+		if len(have) == 0 || len(have) == len(names) {
+			return nil
+		}
+		e := &incompleteGroup{names: names}
+		for _, n := range names {
+			if !set(n) {
+				e.unset = append(e.unset, n)
+			}
+		}
+		return e
+	})
+}
+
+// Implies requires the variables in required to be set whenever name is,
+// e.g. Implies("TLS_CERT_FILE", "TLS_KEY_FILE") or Implies("DB_USER",
+// "DB_PASSWORD"). Unlike AllOrNone, the required variables may be set on
+// their own. A violation reads "TLS_CERT_FILE is set but TLS_KEY_FILE is
+// not".
+func Implies(name string, required ...string) {
+	std.Implies(name, required...)
+}
+
+// Implies adds an implication constraint to the registry.
+func (g *Registry) Implies(name string, required ...string) {
+	g.addConstraint(append([]string{name}, required...), func(set func(string) bool) error {
+		if !set(name) {
+			return nil
+		}
+		var missing []string
+		for _, n := range required {
+			if !set(n) {
+				missing = append(missing, n)
+			}
+		}
+		switch len(missing) {
+		case 0:
+			return nil
+		case 1:
+			return fmt.Errorf("%s is set but %s is not", name, missing[0])
+		}
+		return fmt.Errorf("%s is set but %s are not", name, strings.Join(missing, ", "))
+	})
+}
+
+// incompleteGroup is the error of a violated AllOrNone constraint.
+type incompleteGroup struct {
+	names []string
+	unset []string
+}
+
+func (e *incompleteGroup) Error() string {
+	return fmt.Sprintf("%s must be set together (%s not set)", strings.Join(e.names, ", "), strings.Join(e.unset, ", "))
+}
+
+// incompleteGroups maps the members of the partially set groups among errs
+// to the report detail of their group, e.g. "group incomplete (2 of 4
+// set): SMTP_USER, SMTP_PASSWORD not set".
+func incompleteGroups(errs []ConstraintError) map[string]string {
+	var groups map[string]string
+	for _, e := range errs {
+		if len(e.Unset) == 0 {
+			continue
+		}
+		if groups == nil {
+			groups = make(map[string]string)
+		}
+		detail := fmt.Sprintf("group incomplete (%d of %d set): %s not set",
+			len(e.Names)-len(e.Unset), len(e.Names), strings.Join(e.Unset, ", "))
+		for _, n := range e.Names {
+			if _, ok := groups[n]; !ok {
+				groups[n] = detail
+			}
+		}
+	}
+	return groups
+}
+
+// LessThan requires the number in a to be less than the number in b, e.g.
+// LessThan("MIN_POOL", "MAX_POOL"). Values are parsed like Result.Float.
+// The constraint only applies when both variables are set and valid:
+// missing and invalid values are reported per variable.
+func LessThan(a, b string) {
+	std.LessThan(a, b)
+}
+
+// LessThan adds a less-than constraint to the registry.
+func (g *Registry) LessThan(a, b string) {
+	g.addNumericConstraint([]string{a, b}, func(nums []number) error {
+		if nums[0].value < nums[1].value {
+			return nil
+		}
+		return fmt.Errorf("%s must be less than %s", nums[0], nums[1])
+	})
+}
+
+// SumAtMost requires the numbers in the variables to add up to max at
+// most, e.g. SumAtMost(100, "WEIGHT_A", "WEIGHT_B") for traffic split
+// percentages. Like LessThan, it applies when all variables are set and
+// valid.
+func SumAtMost(max float64, names ...string) {
+	std.SumAtMost(max, names...)
+}
+
+// SumAtMost adds a sum constraint to the registry.
+func (g *Registry) SumAtMost(max float64, names ...string) {
+	g.addNumericConstraint(names, func(nums []number) error {
+		var sum float64
+		terms := make([]string, len(nums))
+		shown := true
+		for i, n := range nums {
+			sum += n.value
+			terms[i] = n.name
+			shown = shown && !n.sensitive
+		}
+		if sum <= max {
+			return nil
+		}
+		if !shown {
+			return fmt.Errorf("%s must add up to at most %g", strings.Join(terms, " + "), max)
+		}
+		return fmt.Errorf("%s must add up to at most %g (is %g)", strings.Join(terms, " + "), max, sum)
+	})
+}
+
+// number is a parsed variable of a numeric constraint.
+type number struct {
+	name      string
+	value     float64
+	sensitive bool // the value is not shown in errors
+}
+
+// String returns e.g. "MIN_POOL (20)", or the name alone for sensitive
+// variables.
+func (n number) String() string {
+	if n.sensitive {
+		return n.name
+	}
+	return fmt.Sprintf("%s (%g)", n.name, n.value)
+}
+
+// addNumericConstraint registers a constraint over the numbers in names,
+// checked only when all of them are set and pass their own validation.
+// A value that is not a number violates the constraint.
+func (g *Registry) addNumericConstraint(names []string, check func(nums []number) error) {
+	g.addGroup(names, func(lookup func(string) Result) error {
+		nums := make([]number, len(names))
+		for i, name := range names {
+			res := lookup(name)
+			if !res.Present || res.Value == "" || res.Err != nil {
+				return nil
+			}
+			f, err := res.Float()
+			if err != nil {
+				return fmt.Errorf("%s must be a number", name)
+			}
+			nums[i] = number{name: name, value: f, sensitive: res.Sensitive}
+		}
+		return check(nums)
+	})
+}
+
+// addConstraint registers a constraint over which of names are set.
+func (g *Registry) addConstraint(names []string, check func(set func(string) bool) error) {
+	g.addGroup(names, func(lookup func(string) Result) error {
+		return check(func(name string) bool {
+			res := lookup(name)
+			return res.Present && res.Value != ""
+		})
+	})
+}
+
+// addGroup registers a constraint over names. It panics with fewer than
+// two names, which is a programming error.
+func (g *Registry) addGroup(names []string, check func(lookup func(string) Result) error) {
+	if len(names) < 2 {
+		panic(fmt.Sprintf("envreq: a group constraint needs at least two variables, got %q", names))
+	}
+	g.mu.Lock()
+	g.constraints = append(g.constraints, constraint{names: names, check: check})
+	g.mu.Unlock()
+}
+
+// AddCrossCheck registers a validation over several variables that
+// per-variable validators cannot express, run by Validate, MustValidate
+// and the registry's Report:
+//
+//	envreq.AddCrossCheck(func(lookup func(string) envreq.Result) error {
+//	    min, _ := lookup("MIN_CONN").Int()
+//	    max, _ := lookup("MAX_CONN").Int()
+//	    if max < min {
+//	        return fmt.Errorf("MAX_CONN (%d) must be >= MIN_CONN (%d)", max, min)
+//	    }
+//	    return nil
+//	})
+//
+// lookup returns the result of any variable, registered or not. An error
+// is reported as a ConstraintError attributed to the variables the check
+// looked up.
+func AddCrossCheck(check func(lookup func(name string) Result) error) {
+	std.AddCrossCheck(check)
+}
+
+// AddCrossCheck registers a cross-variable check with the registry. See
+// the package-level AddCrossCheck.
+func (g *Registry) AddCrossCheck(check func(lookup func(name string) Result) error) {
+	g.mu.Lock()
+	g.constraints = append(g.constraints, constraint{check: check})
+	g.mu.Unlock()
+}
+
+// setNames returns the names that set reports as set.
+func setNames(names []string, set func(string) bool) []string {
+	var have []string
+	for _, n := range names {
+		if set(n) {
+			have = append(have, n)
+		}
+	}
+	return have
+}
+
+// constraintErrors evaluates the registry's constraints and cross-checks
+// against results. Variables that are not in results are resolved from the
+// sources.
+func (g *Registry) constraintErrors(results []Result) []ConstraintError {
+	return g.stageErrors(StageConstraints, results)
+}
+
+// stageErrors evaluates the constraints of stage against results, like
+// constraintErrors.
+func (g *Registry) stageErrors(stage Stage, results []Result) []ConstraintError {
+	constraints := g.stageConstraints(stage)
+	if len(constraints) == 0 {
+		return nil
+	}
+
+	t := g.lookupTable(results)
+	var errs []ConstraintError
+	for _, c := range constraints {
+		if ce, failed := t.evaluate(c); failed {
+			errs = append(errs, ce)
+		}
+	}
+	return errs
+}
+
+// stageConstraints returns the registry's constraints of stage.
+func (g *Registry) stageConstraints(stage Stage) []constraint {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var out []constraint
+	for _, c := range g.constraints {
+		if max(c.stage, StageConstraints) == stage {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// lookupTable resolves the variables constraints look up: from the results
+// it was built with, then from the sources. It is safe for concurrent use,
+// so that deep checks can run in parallel.
+type lookupTable struct {
+	g      *Registry
+	mu     sync.Mutex
+	byName map[string]Result
+}
+
+// lookupTable returns a lookupTable over results.
+func (g *Registry) lookupTable(results []Result) *lookupTable {
+	byName := make(map[string]Result, len(results))
+	for _, res := range results {
+		byName[res.Name] = res
+	}
+	return &lookupTable{g: g, byName: byName}
+}
+
+// get returns the result of name.
+func (t *lookupTable) get(name string) Result {
+	t.mu.Lock()
+	res, ok := t.byName[name]
+	t.mu.Unlock()
+	if ok {
+		return res
+	}
+
+	res = t.g.evaluate(t.g.requirement(name))
+	t.mu.Lock()
+	t.byName[name] = res
+	t.mu.Unlock()
+	return res
+}
+
+// evaluate runs c and reports whether it failed, with the error
+// attributed to the variables it names or looked up.
+func (t *lookupTable) evaluate(c constraint) (ConstraintError, bool) {
+	var seen []string
+	err := c.check(func(name string) Result {
+		if !slices.Contains(seen, name) {
+			seen = append(seen, name)
+		}
+		return t.get(name)
+	})
+	switch {
+	case err == nil:
+		return ConstraintError{}, false
+	case c.names != nil:
+		ce := ConstraintError{Names: c.names, Err: err}
+		var group *incompleteGroup
+		if errors.As(err, &group) {
+			ce.Unset = group.unset
+		}
+		return ce, true
+	case len(seen) > 0:
+		return ConstraintError{Names: seen, Err: fmt.Errorf("%s: %w", strings.Join(seen, ", "), err)}, true
+	default:
+		return ConstraintError{Err: err}, true
+	}
+}
+
+// requirement returns the registered requirement name, or a bare one for
+// variables that are not registered.
+func (g *Registry) requirement(name string) Requirement {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if r, ok := g.reg[name]; ok {
+		return r
+	}
+	return Requirement{Name: name, Optional: true}
+}
+
+// reportConstraints writes the violated constraints to w.
+func reportConstraints(w io.Writer, errs []ConstraintError) {
+	if len(errs) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nConstraints violated:\n")
+	for _, e := range errs {
+		fmt.Fprintf(w, "  %v\n", e.Err)
+	}
+}
+
+// reportWarnings writes the deep check warnings of a validation pass.
+func reportWarnings(w io.Writer, warnings []ConstraintError) {
+	if len(warnings) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nWarnings:\n")
+	for _, e := range warnings {
+		fmt.Fprintf(w, "  %v\n", e.Err)
+	}
+}
@@ -0,0 +1,204 @@
+package envreq_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestGroupConstraints(t *testing.T) {
+	t.Setenv("GRP_TLS_CERT", "/etc/tls/cert.pem")
+	t.Setenv("GRP_DB_URL", "postgres://db/app")
+	t.Setenv("GRP_DB_HOST", "db")
+
+	g := envreq.New()
+	g.Check(envreq.Requirement{Name: "GRP_TLS_CERT", Source: "server", Optional: true})
+	g.Check(envreq.Requirement{Name: "GRP_TLS_KEY", Source: "server", Optional: true})
+	g.AllOrNone("GRP_TLS_CERT", "GRP_TLS_KEY")
+	g.ExactlyOneOf("GRP_DB_URL", "GRP_DB_HOST") // neither is registered
+	g.AtLeastOneOf("GRP_DB_URL", "GRP_DB_SOCKET")
+
+	var verr *envreq.ValidationError
+	if err := g.Validate(); !errors.As(err, &verr) {
+		t.Fatalf("Expected a *ValidationError, got %v", err)
+	}
+	if len(verr.Problems) != 0 || len(verr.Constraints) != 2 {
+		t.Fatalf("Expected 2 violated constraints, got %+v", verr)
+	}
+	if got := verr.Constraints[0].Err.Error(); got != "GRP_TLS_CERT, GRP_TLS_KEY must be set together (GRP_TLS_KEY not set)" {
+		t.Errorf("Unexpected AllOrNone error %q", got)
+	}
+	if got := verr.Constraints[1].Err.Error(); got != "exactly one of GRP_DB_URL, GRP_DB_HOST must be set (GRP_DB_URL, GRP_DB_HOST are)" {
+		t.Errorf("Unexpected ExactlyOneOf error %q", got)
+	}
+	if names := verr.Constraints[0].Names; len(names) != 2 || names[1] != "GRP_TLS_KEY" {
+		t.Errorf("Expected the constraint to name its variables, got %v", names)
+	}
+
+	var buf bytes.Buffer
+	g.Report(&buf)
+	if !strings.Contains(buf.String(), "Constraints violated:\n  GRP_TLS_CERT, GRP_TLS_KEY must be set together") {
+		t.Errorf("Expected the constraints in the report:\n%s", buf.String())
+	}
+
+	t.Setenv("GRP_TLS_KEY", "/etc/tls/key.pem")
+	t.Setenv("GRP_DB_HOST", "")
+	if _, err := g.Revalidate(); err != nil {
+		t.Errorf("Expected the constraints to hold, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for a single-variable group")
+		}
+	}()
+	g.AllOrNone("GRP_TLS_CERT")
+}
+
+func TestAllOrNoneReport(t *testing.T) {
+	t.Setenv("AON_SMTP_HOST", "smtp.example.com")
+	t.Setenv("AON_SMTP_PORT", "587")
+
+	g := envreq.New()
+	for _, name := range []string{"AON_SMTP_HOST", "AON_SMTP_PORT", "AON_SMTP_USER", "AON_SMTP_PASSWORD"} {
+		g.Check(envreq.Requirement{Name: name, Source: "mailer", Optional: true})
+	}
+	g.AllOrNone("AON_SMTP_HOST", "AON_SMTP_PORT", "AON_SMTP_USER", "AON_SMTP_PASSWORD")
+
+	var verr *envreq.ValidationError
+	if err := g.Validate(); !errors.As(err, &verr) || len(verr.Constraints) != 1 {
+		t.Fatalf("Expected one violated constraint, got %v", err)
+	}
+	if unset := verr.Constraints[0].Unset; len(unset) != 2 || unset[0] != "AON_SMTP_USER" || unset[1] != "AON_SMTP_PASSWORD" {
+		t.Errorf("Expected the unset members, got %v", unset)
+	}
+
+	var buf bytes.Buffer
+	g.Report(&buf)
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "AON_SMTP_") && !strings.Contains(line, "incomplete Error: group incomplete (2 of 4 set): AON_SMTP_USER, AON_SMTP_PASSWORD not set") {
+			t.Errorf("Expected every member to be reported incomplete, got %q", line)
+		}
+	}
+
+	buf.Reset()
+	g.ReportJSON(&buf)
+	if !strings.Contains(buf.String(), `"name":"AON_SMTP_HOST","source":"mailer","required":false,"sensitive":false,"status":"incomplete"`) {
+		t.Errorf("Expected the JSON report to mark the group incomplete:\n%s", buf.String())
+	}
+
+	if _, err := g.Revalidate(); !errors.As(err, &verr) || len(verr.Constraints[0].Unset) != 2 {
+		t.Errorf("Expected Revalidate to report the unset members, got %v", err)
+	}
+
+	t.Setenv("AON_SMTP_HOST", "")
+	t.Setenv("AON_SMTP_PORT", "")
+	g = envreq.New()
+	g.Check(envreq.Requirement{Name: "AON_SMTP_HOST", Source: "mailer", Optional: true})
+	g.AllOrNone("AON_SMTP_HOST", "AON_SMTP_PORT")
+	buf.Reset()
+	g.Report(&buf)
+	if strings.Contains(buf.String(), "incomplete") {
+		t.Errorf("Expected an unset group to be fine:\n%s", buf.String())
+	}
+}
+
+func TestImplies(t *testing.T) {
+	t.Setenv("IMP_TLS_CERT", "/etc/tls/cert.pem")
+	t.Setenv("IMP_DB_PASSWORD", "hunter2")
+
+	g := envreq.New()
+	g.Implies("IMP_TLS_CERT", "IMP_TLS_KEY")
+	g.Implies("IMP_DB_USER", "IMP_DB_PASSWORD") // only the password is set
+	g.Implies("IMP_SMTP_USER", "IMP_SMTP_PASSWORD", "IMP_SMTP_HOST")
+
+	var verr *envreq.ValidationError
+	if err := g.Validate(); !errors.As(err, &verr) || len(verr.Constraints) != 1 {
+		t.Fatalf("Expected one violated constraint, got %v", err)
+	}
+	if got := verr.Constraints[0].Err.Error(); got != "IMP_TLS_CERT is set but IMP_TLS_KEY is not" {
+		t.Errorf("Unexpected Implies error %q", got)
+	}
+
+	t.Setenv("IMP_SMTP_USER", "mailer")
+	if _, err := g.Revalidate(); !errors.As(err, &verr) || len(verr.Constraints) != 2 {
+		t.Fatalf("Expected two violated constraints, got %v", err)
+	}
+	if got := verr.Constraints[1].Err.Error(); got != "IMP_SMTP_USER is set but IMP_SMTP_PASSWORD, IMP_SMTP_HOST are not" {
+		t.Errorf("Unexpected Implies error %q", got)
+	}
+}
+
+func TestNumericConstraints(t *testing.T) {
+	t.Setenv("NUM_MIN_POOL", "20")
+	t.Setenv("NUM_MAX_POOL", "10")
+	t.Setenv("NUM_WEIGHT_A", "70")
+	t.Setenv("NUM_WEIGHT_B", "50.5")
+	t.Setenv("NUM_BUDGET", "900")
+	t.Setenv("NUM_RESERVED", "200")
+	t.Setenv("NUM_LOW", "five")
+
+	g := envreq.New()
+	g.Check(envreq.Requirement{Name: "NUM_BUDGET", Source: "billing", Sensitive: true})
+	g.Check(envreq.Requirement{Name: "NUM_HIGH", Source: "billing", Optional: true, Validate: envreq.Int})
+	g.LessThan("NUM_MIN_POOL", "NUM_MAX_POOL")
+	g.SumAtMost(100, "NUM_WEIGHT_A", "NUM_WEIGHT_B")
+	g.SumAtMost(1000, "NUM_BUDGET", "NUM_RESERVED")
+	g.LessThan("NUM_LOW", "NUM_MAX_POOL")
+	g.LessThan("NUM_MAX_POOL", "NUM_HIGH") // NUM_HIGH is not set
+
+	var verr *envreq.ValidationError
+	if err := g.Validate(); !errors.As(err, &verr) || len(verr.Constraints) != 4 {
+		t.Fatalf("Expected 4 violated constraints, got %v", err)
+	}
+	for i, want := range []string{
+		"NUM_MIN_POOL (20) must be less than NUM_MAX_POOL (10)",
+		"NUM_WEIGHT_A + NUM_WEIGHT_B must add up to at most 100 (is 120.5)",
+		"NUM_BUDGET + NUM_RESERVED must add up to at most 1000",
+		"NUM_LOW must be a number",
+	} {
+		if got := verr.Constraints[i].Err.Error(); got != want {
+			t.Errorf("Constraint %d: expected %q, got %q", i, want, got)
+		}
+	}
+}
+
+func TestCrossCheck(t *testing.T) {
+	t.Setenv("XC_MIN_CONN", "10")
+	t.Setenv("XC_MAX_CONN", "5")
+
+	g := envreq.New()
+	g.Check(envreq.Requirement{Name: "XC_MIN_CONN", Source: "db"})
+	g.AddCrossCheck(func(lookup func(string) envreq.Result) error {
+		min, _ := lookup("XC_MIN_CONN").Int()
+		max, _ := lookup("XC_MAX_CONN").Int() // not registered
+		if max < min {
+			return fmt.Errorf("XC_MAX_CONN (%d) must be >= XC_MIN_CONN (%d)", max, min)
+		}
+		return nil
+	})
+
+	var verr *envreq.ValidationError
+	if err := g.Validate(); !errors.As(err, &verr) || len(verr.Constraints) != 1 {
+		t.Fatalf("Expected a failed cross-check, got %v", err)
+	}
+	c := verr.Constraints[0]
+	if strings.Join(c.Names, ",") != "XC_MIN_CONN,XC_MAX_CONN" {
+		t.Errorf("Expected the looked up variables, got %v", c.Names)
+	}
+
+	var buf bytes.Buffer
+	g.Report(&buf)
+	if !strings.Contains(buf.String(), "  XC_MIN_CONN, XC_MAX_CONN: XC_MAX_CONN (5) must be >= XC_MIN_CONN (10)\n") {
+		t.Errorf("Expected the cross-check attributed in the report:\n%s", buf.String())
+	}
+
+	t.Setenv("XC_MAX_CONN", "50")
+	if _, err := g.Revalidate(); err != nil {
+		t.Errorf("Expected the cross-check to pass, got %v", err)
+	}
+}
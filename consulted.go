@@ -0,0 +1,47 @@
+package envreq
+
+import "strings"
+
+// Consulted records one lookup made while resolving a variable, so that
+// "why did it use the default" can be answered precisely.
+type Consulted struct {
+	Provenance string // layer consulted, e.g. ProvenanceEnv, "vault" or ProvenanceDefault
+	Name       string // variable name looked up, e.g. an alias
+	Outcome    string // one of the Outcome constants
+	Err        error  // why the source could not be consulted, for OutcomeError
+	Cached     bool   // answered from the lookup cache of a remote source, without a round trip
+}
+
+// Outcomes of a Consulted lookup.
+const (
+	OutcomeUsed     = "used"     // supplied the value
+	OutcomeUnset    = "unset"    // not set in this layer
+	OutcomeError    = "error"    // the source failed, see Consulted.Err
+	OutcomeShadowed = "shadowed" // set, but hidden by a higher-precedence layer with a different value
+	OutcomeSame     = "same"     // set to the value already found
+)
+
+func (c Consulted) String() string {
+	s := c.Provenance
+	if c.Name != "" {
+		s += " " + c.Name
+	}
+	if c.Err != nil {
+		return s + ": " + c.Err.Error()
+	}
+	if c.Cached {
+		return s + ": " + c.Outcome + " (cached)"
+	}
+	return s + ": " + c.Outcome
+}
+
+// Explain describes how the value was resolved, lookup by lookup, e.g.
+// "env DATABASE_URL: unset, vault DATABASE_URL: permission denied,
+// default DATABASE_URL: used". Values are never included.
+func (r Result) Explain() string {
+	parts := make([]string, len(r.Consulted))
+	for i, c := range r.Consulted {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, ", ")
+}
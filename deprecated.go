@@ -0,0 +1,49 @@
+package envreq
+
+import "slices"
+
+// names returns the variable names searched for r, in order: the
+// replacement of a deprecated variable, its own name, then its aliases.
+func (r Requirement) names() []string {
+	names := make([]string, 0, 2+len(r.Aliases))
+	if r.ReplacedBy != "" {
+		names = append(names, r.ReplacedBy)
+	}
+	names = append(names, r.Name)
+	return append(names, r.Aliases...)
+}
+
+// mergeAliases returns a followed by the names of b not already in a.
+func mergeAliases(a, b []string) []string {
+	merged := slices.Clone(a)
+	for _, name := range b {
+		if !slices.Contains(merged, name) {
+			merged = append(merged, name)
+		}
+	}
+	return merged
+}
+
+// deprecatedInUse reports whether res got its value from a deprecated
+// name, i.e. from anything but the replacement.
+func deprecatedInUse(res Result) bool {
+	return res.Deprecated && res.Present && (res.From == "" || res.From != res.ReplacedBy) &&
+		res.Provenance != ProvenanceDefault && res.Provenance != ProvenanceGenerated
+}
+
+// warnDeprecated logs, once per variable, that the deprecated r is set.
+func (g *Registry) warnDeprecated(r Requirement) {
+	g.mu.Lock()
+	warned := g.deprecWarned[r.Name]
+	g.deprecWarned[r.Name] = true
+	g.mu.Unlock()
+	if warned {
+		return
+	}
+
+	if r.ReplacedBy != "" {
+		g.logf("⚠️  envreq: %s is deprecated (from %s); rename it to %s", r.Name, r.Source, r.ReplacedBy)
+	} else {
+		g.logf("⚠️  envreq: %s is deprecated (from %s) and will be removed", r.Name, r.Source)
+	}
+}
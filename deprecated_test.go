@@ -0,0 +1,66 @@
+package envreq_test
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestDeprecated(t *testing.T) {
+	var logs bytes.Buffer
+	g := envreq.New()
+	g.SetLogger(log.New(&logs, "", 0))
+
+	old := envreq.Requirement{
+		Name:       "DEP_DB_URI",
+		Source:     "db",
+		Deprecated: true,
+		ReplacedBy: "DEP_DATABASE_URL",
+	}
+
+	// Only the old name is set: used, with a warning and status deprecated
+	t.Setenv("DEP_DB_URI", "postgres://old")
+	res := g.Check(old)
+	if res.Value != "postgres://old" || res.From != "" {
+		t.Errorf("Expected the deprecated name to supply the value, got %q from %q", res.Value, res.From)
+	}
+	g.Revalidate()
+	if n := strings.Count(logs.String(), "DEP_DB_URI is deprecated (from db); rename it to DEP_DATABASE_URL"); n != 1 {
+		t.Errorf("Expected one deprecation warning, got %d:\n%s", n, logs.String())
+	}
+
+	var buf bytes.Buffer
+	g.Report(&buf)
+	if !strings.Contains(buf.String(), "deprecated  [deprecated: rename to DEP_DATABASE_URL]") ||
+		!strings.Contains(buf.String(), "1 deprecated variable(s) in use") {
+		t.Errorf("Expected a deprecated status in the report:\n%s", buf.String())
+	}
+	if err := g.Validate(); err != nil {
+		t.Errorf("Deprecated variables must not fail validation, got %v", err)
+	}
+
+	// The new name wins once set
+	t.Setenv("DEP_DATABASE_URL", "postgres://new")
+	g2 := envreq.New()
+	g2.SetLogger(log.New(&logs, "", 0))
+	logs.Reset()
+	res = g2.Check(old)
+	if res.Value != "postgres://new" || res.From != "DEP_DATABASE_URL" {
+		t.Errorf("Expected the replacement to supply the value, got %q from %q", res.Value, res.From)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("Expected no warning once migrated, got %s", logs.String())
+	}
+	buf.Reset()
+	g2.Report(&buf)
+	if !strings.Contains(buf.String(), "ok          [from DEP_DATABASE_URL]") {
+		t.Errorf("Expected the supplying name in the report:\n%s", buf.String())
+	}
+
+	if v := g2.Describe().Vars[0]; !v.Deprecated || v.ReplacedBy != "DEP_DATABASE_URL" {
+		t.Errorf("Expected deprecation in the schema, got %+v", v)
+	}
+}
@@ -0,0 +1,120 @@
+package envreq
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// ErrNotDevelopment is returned by GenerateDevValues outside the
+// development profile.
+var ErrNotDevelopment = errors.New("envreq: generated values are only allowed in the development profile")
+
+// GenerateDevValues makes Check fabricate plausible values for variables
+// that are not set and have no default, so a service can boot locally
+// without real credentials. Values are derived from the validator (URL,
+// Port, Duration, Base64), the Example, and name suffixes such as _URL,
+// _PORT, _TIMEOUT or _KEY, and must pass the variable's validator. The same
+// seed always yields the same values.
+//
+// Generated values carry ProvenanceGenerated and are flagged in reports. It
+// returns ErrNotDevelopment unless the profile is development (see
+// IsDevelopment), and values are never generated if the profile changes
+// later.
+func GenerateDevValues(seed uint64) error {
+	return std.GenerateDevValues(seed)
+}
+
+// GenerateDevValues enables generated values in the registry. See the
+// package-level GenerateDevValues.
+func (g *Registry) GenerateDevValues(seed uint64) error {
+	if !IsDevelopment() {
+		return ErrNotDevelopment
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.devSeed = seed
+	g.devGenerate = true
+	// Missing values resolved so far may now be generated
+	for name, v := range g.cache {
+		if !v.present {
+			delete(g.cache, name)
+		}
+	}
+	g.logf("⚠️  envreq: generating development values for missing variables (seed %d)", seed)
+	return nil
+}
+
+// generated returns a fabricated value for r, if generation is enabled and
+// a candidate passes r's validator.
+func (g *Registry) generated(r Requirement) (string, bool) {
+	g.mu.RLock()
+	enabled, seed := g.devGenerate, g.devSeed
+	g.mu.RUnlock()
+	if !enabled || !IsDevelopment() {
+		return "", false
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(r.Name))
+	rng := rand.New(rand.NewPCG(seed, h.Sum64()))
+
+	for _, v := range devCandidates(r, rng) {
+		if r.Validate == nil || r.Validate(v) == nil {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// devCandidates returns plausible values for r, most specific first.
+func devCandidates(r Requirement, rng *rand.Rand) []string {
+	port := fmt.Sprint(1024 + rng.IntN(64511))
+	secret := func() string {
+		b := make([]byte, 32)
+		for i := range b {
+			b[i] = byte(rng.UintN(256))
+		}
+		return base64.StdEncoding.EncodeToString(b)
+	}
+	url := "http://localhost:" + port
+	duration := (time.Duration(1+rng.IntN(60)) * time.Second).String()
+	word := "dev-" + strings.ToLower(strings.ReplaceAll(r.Name, "_", "-"))
+
+	var out []string
+	switch validatorName(r.Validate) {
+	case "envreq.URL":
+		out = append(out, url)
+	case "envreq.Port":
+		out = append(out, port)
+	case "envreq.Duration":
+		out = append(out, duration)
+	case "envreq.Base64", "envreq.Base64Std":
+		out = append(out, secret())
+	case "envreq.Base64URL":
+		out = append(out, strings.TrimRight(strings.NewReplacer("+", "-", "/", "_").Replace(secret()), "="))
+	}
+	if r.Example != "" {
+		out = append(out, r.Example)
+	}
+
+	name := strings.ToUpper(r.Name)
+	switch {
+	case strings.HasSuffix(name, "_URL") || strings.HasSuffix(name, "_URI") || strings.HasSuffix(name, "_ENDPOINT"):
+		out = append(out, url)
+	case strings.HasSuffix(name, "_PORT"):
+		out = append(out, port)
+	case strings.HasSuffix(name, "_TIMEOUT") || strings.HasSuffix(name, "_INTERVAL") || strings.HasSuffix(name, "_TTL"):
+		out = append(out, duration)
+	case strings.HasSuffix(name, "_HOST"):
+		out = append(out, "localhost")
+	case strings.HasSuffix(name, "_KEY") || strings.HasSuffix(name, "_SECRET") || strings.HasSuffix(name, "_TOKEN") || strings.HasSuffix(name, "_PASSWORD"):
+		out = append(out, secret())
+	}
+	return append(out, word, url, port, duration, "true", "1")
+}
@@ -0,0 +1,75 @@
+package envreq_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestGenerateDevValues(t *testing.T) {
+	envreq.SetProfile("production")
+	defer envreq.SetProfile("")
+	g := envreq.New()
+	g.SetLogger(&captureLogger{})
+	if err := g.GenerateDevValues(42); !errors.Is(err, envreq.ErrNotDevelopment) {
+		t.Fatalf("Expected ErrNotDevelopment, got %v", err)
+	}
+
+	envreq.SetProfile("development")
+	missing := g.Check(envreq.Requirement{Name: "GEN_API_URL", Source: "test", Validate: envreq.URL})
+	if missing.Present {
+		t.Fatal("Expected GEN_API_URL to be missing before generation")
+	}
+	if err := g.GenerateDevValues(42); err != nil {
+		t.Fatal(err)
+	}
+
+	reqs := []envreq.Requirement{
+		{Name: "GEN_API_URL", Source: "test", Validate: envreq.URL},
+		{Name: "GEN_LISTEN", Source: "test", Validate: envreq.Port},
+		{Name: "GEN_POLL", Source: "test", Validate: envreq.Duration},
+		{Name: "GEN_SIGNING_KEY", Source: "test", Validate: envreq.Base64, Sensitive: true},
+		{Name: "GEN_MODE", Source: "test", Example: "sandbox", Validate: envreq.OneOf("live", "sandbox")},
+		{Name: "GEN_DB_PORT", Source: "test"},
+		{Name: "GEN_WITH_DEFAULT", Source: "test", Optional: true, Default: "kept"},
+	}
+	first := map[string]string{}
+	for _, r := range reqs {
+		res := g.Check(r)
+		if !res.Present || res.Err != nil {
+			t.Errorf("%s: expected a valid generated value, got %q (%v)", r.Name, res.Value, res.Err)
+		}
+		if r.Default == "" && res.Provenance != envreq.ProvenanceGenerated {
+			t.Errorf("%s: expected generated provenance, got %s", r.Name, res.Provenance)
+		}
+		first[r.Name] = res.Value
+	}
+	if first["GEN_WITH_DEFAULT"] != "kept" || first["GEN_MODE"] != "sandbox" {
+		t.Errorf("Unexpected values: %v", first)
+	}
+
+	// Same seed, same values
+	g2 := envreq.New()
+	g2.SetLogger(&captureLogger{})
+	g2.GenerateDevValues(42)
+	for _, r := range reqs {
+		if v := g2.Check(r).Value; v != first[r.Name] {
+			t.Errorf("%s: seed 42 gave %q then %q", r.Name, first[r.Name], v)
+		}
+	}
+
+	var buf bytes.Buffer
+	g.Report(&buf)
+	if !strings.Contains(buf.String(), "[generated]") || !strings.Contains(buf.String(), "generated placeholders") {
+		t.Errorf("Expected generated values to be flagged:\n%s", buf.String())
+	}
+
+	// Never outside development, even if enabled earlier
+	envreq.SetProfile("production")
+	if res := g.Check(envreq.Requirement{Name: "GEN_LATER", Source: "test"}); res.Present {
+		t.Errorf("Generated %q outside development", res.Value)
+	}
+}
@@ -0,0 +1,163 @@
+package envreq
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// waitPollInterval is how often WaitValid looks for changed files.
+const waitPollInterval = 250 * time.Millisecond
+
+// envFiles is one LoadDotenv or LoadLocalOverrides call.
+type envFiles struct {
+	paths []string
+	local bool // loaded with LoadLocalOverrides
+}
+
+// WaitOnFailure makes MustValidate wait for the configuration to be fixed
+// instead of exiting, in the development profile only: it watches the
+// files read by LoadDotenv and LoadLocalOverrides (DefaultDotenvFiles when
+// none were loaded), re-validates whenever one changes and returns once
+// the configuration is valid, so the code after MustValidate, typically
+// starting the server, runs without a restart:
+//
+//	envreq.LoadDotenv()
+//	envreq.WaitOnFailure(true)
+//	envreq.MustValidate() // in development: blocks until .env is fixed
+//	srv.ListenAndServe()
+//
+// Outside development MustValidate still exits.
+func WaitOnFailure(on bool) {
+	std.WaitOnFailure(on)
+}
+
+// WaitOnFailure sets whether the registry's MustValidate waits for a fix
+// in development. See the package-level WaitOnFailure.
+func (g *Registry) WaitOnFailure(on bool) {
+	g.devWait.Store(on)
+}
+
+// WaitValid blocks until the configuration of the default registry is
+// valid or ctx is done, reloading the dotenv and local override files
+// whenever one of them changes. It returns the last results with nil, or
+// with the last validation error once ctx is done. Progress is written to
+// the output set with SetOutput.
+func WaitValid(ctx context.Context) ([]Result, error) {
+	return std.WaitValid(ctx)
+}
+
+// WaitValid waits for the registry's configuration to become valid. See
+// the package-level WaitValid.
+func (g *Registry) WaitValid(ctx context.Context) ([]Result, error) {
+	results, err := g.ValidateResultsContext(ctx)
+	if err == nil {
+		return results, nil
+	}
+
+	out := g.output()
+	paths := g.watchedFiles()
+	fmt.Fprintf(out, "\nWaiting for changes to %s to validate again...\n", strings.Join(paths, ", "))
+	seen := filesState(paths)
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return results, err
+		case <-ticker.C:
+		}
+		state := filesState(paths)
+		if state == seen {
+			continue
+		}
+		seen = state
+
+		if rerr := g.reload(); rerr != nil {
+			fmt.Fprintf(out, "\n%v\n", rerr)
+			continue
+		}
+		results, err = g.ValidateResultsContext(ctx)
+		if err == nil {
+			fmt.Fprintf(out, "\nConfiguration is valid\n")
+			return results, nil
+		}
+		fmt.Fprintf(out, "\nStill invalid: %v\n", err)
+	}
+}
+
+// watchedFiles returns the files WaitValid watches.
+func (g *Registry) watchedFiles() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if len(g.loads) == 0 {
+		return DefaultDotenvFiles
+	}
+	var paths []string
+	for _, l := range g.loads {
+		paths = append(paths, l.paths...)
+	}
+	return paths
+}
+
+// filesState summarizes the size and modification time of paths, so that
+// any change, creation or removal changes the summary.
+func filesState(paths []string) string {
+	var b strings.Builder
+	for _, p := range paths {
+		if fi, err := statFile(p); err == nil {
+			fmt.Fprintf(&b, "%s %d %d\n", p, fi.Size(), fi.ModTime().UnixNano())
+		}
+	}
+	return b.String()
+}
+
+// recordLoad remembers a load call for reload.
+func (g *Registry) recordLoad(l envFiles) {
+	g.mu.Lock()
+	g.loads = append(g.loads, l)
+	g.mu.Unlock()
+}
+
+// reload drops the file layers and every cached result, then repeats the
+// recorded load calls, or loads DefaultDotenvFiles when there were none.
+func (g *Registry) reload() error {
+	g.mu.Lock()
+	loads := g.loads
+	g.localVars = map[string]string{}
+	g.dotenvVars = map[string]string{}
+	g.cache = map[string]resolved{}
+	g.mu.Unlock()
+
+	if len(loads) == 0 {
+		return g.loadDotenv(DefaultDotenvFiles)
+	}
+	for _, l := range loads {
+		load := g.loadDotenv
+		if l.local {
+			load = g.loadLocalOverrides
+		}
+		if err := load(l.paths); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForFix implements WaitOnFailure for mustValidate: it reports whether
+// the configuration became valid, after writing the fresh report.
+func (g *Registry) waitForFix(ctx context.Context, out io.Writer) bool {
+	if !g.devWait.Load() || !IsDevelopment() {
+		return false
+	}
+	results, err := g.WaitValid(ctx)
+	if err != nil {
+		return false
+	}
+	reportBuild(out, g.BuildInfo())
+	Report(out, results)
+	return true
+}
@@ -0,0 +1,59 @@
+//go:build !(js && wasm) && !envreq_nofiles
+
+package envreq_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestWaitValid(t *testing.T) {
+	dotenv := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(dotenv, []byte("WAIT_PORT=8080\nWAIT_STALE=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	g := envreq.New()
+	g.SetOutput(&out)
+	if err := g.LoadDotenv(dotenv); err != nil {
+		t.Fatal(err)
+	}
+	g.Check(envreq.Requirement{Name: "WAIT_PORT", Source: "server", Validate: envreq.Port})
+	g.Check(envreq.Requirement{Name: "WAIT_DB_URL", Source: "db"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	var verr *envreq.ValidationError
+	if _, err := g.WaitValid(ctx); !errors.As(err, &verr) || len(verr.Problems) != 1 {
+		t.Fatalf("Expected the missing variable once the wait times out, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		os.WriteFile(dotenv, []byte("WAIT_PORT=8080\nWAIT_DB_URL=postgres://db/app\n"), 0o600)
+	}()
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	results, err := g.WaitValid(ctx)
+	if err != nil {
+		t.Fatalf("Expected the fixed file to validate, got %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "WAIT_DB_URL" || results[0].Value != "postgres://db/app" {
+		t.Errorf("Expected the reloaded value, got %+v", results)
+	}
+	if res := g.Check(envreq.Requirement{Name: "WAIT_STALE", Source: "server", Optional: true}); res.Present {
+		t.Error("Expected the reload to drop removed entries")
+	}
+	if !strings.Contains(out.String(), "Waiting for changes to "+dotenv) || !strings.Contains(out.String(), "Configuration is valid") {
+		t.Errorf("Expected progress in the output:\n%s", out.String())
+	}
+}
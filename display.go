@@ -0,0 +1,40 @@
+package envreq
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Display functions for Requirement.DisplayFunc. They summarize a value in
+// debug reports without printing it.
+
+// DisplayHost shows only the host (and port) of a URL value, e.g.
+// "host db.internal:5432", or "not a URL".
+func DisplayHost(v string) string {
+	u, err := url.Parse(v)
+	if err != nil || u.Host == "" {
+		return "not a URL"
+	}
+	return "host " + u.Host
+}
+
+// DisplayLength shows only the length of the value, e.g. "64 chars".
+func DisplayLength(v string) string {
+	return fmt.Sprintf("%d chars", utf8.RuneCountInString(v))
+}
+
+// DisplayCount returns a DisplayFunc showing only the number of sep
+// separated items in a list value, e.g. "3 items".
+func DisplayCount(sep string) func(string) string {
+	return func(v string) string {
+		n := 0
+		for _, item := range strings.Split(v, sep) {
+			if strings.TrimSpace(item) != "" {
+				n++
+			}
+		}
+		return fmt.Sprintf("%d items", n)
+	}
+}
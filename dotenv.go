@@ -0,0 +1,73 @@
+package envreq
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// DefaultDotenvFiles are the files LoadDotenv reads when given no paths,
+// highest precedence first.
+var DefaultDotenvFiles = []string{".env.local", ".env"}
+
+// LoadDotenv loads KEY=VALUE files as a value layer below the process
+// environment, so that the precedence is process env > .env.local > .env
+// with the default paths. Files earlier in paths win over later ones, and
+// missing files are skipped. With no arguments DefaultDotenvFiles are read.
+//
+// Loaded values go through the same resolution, validation and redaction as
+// environment variables and are reported with ProvenanceDotenv. Unlike
+// LoadLocalOverrides, a dotenv file never overrides a variable set in the
+// environment.
+//
+// On targets without file support (js/wasm, envreq_nofiles) it returns an
+// error wrapping errors.ErrUnsupported.
+func LoadDotenv(paths ...string) error {
+	return std.LoadDotenv(paths...)
+}
+
+// LoadDotenv loads dotenv files into the registry. See the package-level
+// LoadDotenv.
+func (g *Registry) LoadDotenv(paths ...string) error {
+	if len(paths) == 0 {
+		paths = DefaultDotenvFiles
+	}
+	g.recordLoad(envFiles{paths: paths})
+	return g.loadDotenv(paths)
+}
+
+// loadDotenv implements LoadDotenv.
+func (g *Registry) loadDotenv(paths []string) error {
+	merged := map[string]string{}
+	for _, p := range paths {
+		vars, err := readEnvFile(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for k, v := range vars {
+			if _, ok := merged[k]; !ok {
+				merged[k] = v
+			}
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for k, v := range merged {
+		g.dotenvVars[k] = v
+		// Drop any cached result so the value is picked up
+		delete(g.cache, k)
+	}
+	return nil
+}
+
+// dotenvValue returns the dotenv value for name, if any.
+func (g *Registry) dotenvValue(name string) (string, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	v, ok := g.dotenvVars[name]
+	return v, ok
+}
@@ -0,0 +1,56 @@
+//go:build !envreq_nofiles
+
+package envreq_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestLoadDotenv(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, ".env.local")
+	shared := filepath.Join(dir, ".env")
+	if err := os.WriteFile(local, []byte("DOTENV_PORT=9000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(shared, []byte("DOTENV_PORT=8080\nDOTENV_HOST=db.internal\nDOTENV_TOKEN=abcd1234\nDOTENV_URL=nope\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOTENV_HOST", "db.prod")
+
+	g := envreq.New()
+	if err := g.LoadDotenv(local, shared, filepath.Join(dir, "missing.env")); err != nil {
+		t.Fatalf("LoadDotenv: %v", err)
+	}
+
+	port := g.Check(envreq.Requirement{Name: "DOTENV_PORT", Source: "test"})
+	if port.Value != "9000" || port.Provenance != envreq.ProvenanceDotenv {
+		t.Errorf("Expected .env.local to win over .env, got %q from %s", port.Value, port.Provenance)
+	}
+
+	host := g.Check(envreq.Requirement{Name: "DOTENV_HOST", Source: "test"})
+	if host.Value != "db.prod" || host.Provenance != envreq.ProvenanceEnv {
+		t.Errorf("Expected process env to win over dotenv, got %q from %s", host.Value, host.Provenance)
+	}
+	if len(host.Shadowed) != 1 || host.Shadowed[0].Provenance != envreq.ProvenanceDotenv {
+		t.Errorf("Expected the dotenv value to be reported as shadowed, got %+v", host.Shadowed)
+	}
+
+	if res := g.Check(envreq.Requirement{Name: "DOTENV_URL", Source: "test", Validate: envreq.URL}); res.Err == nil {
+		t.Error("Expected dotenv values to be validated")
+	}
+
+	t.Setenv("ENVREQ_SHOW_VALUES", "1")
+	g.Check(envreq.Requirement{Name: "DOTENV_TOKEN", Source: "test", Sensitive: true})
+	var buf bytes.Buffer
+	g.Report(&buf)
+	if strings.Contains(buf.String(), "abcd1234") {
+		t.Errorf("Sensitive dotenv value leaked into report:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,259 @@
+package envreq
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PostgresDSN validates a PostgreSQL connection string without connecting:
+// a URL such as "postgres://app@db:5432/orders?sslmode=verify-full" (scheme
+// postgres or postgresql) or a libpq key/value string such as
+// "host=db port=5432 dbname=orders". The port must be valid, and sslmode
+// one of the libpq modes.
+func PostgresDSN(v string) error {
+	if v == "" {
+		return fmt.Errorf("DSN cannot be empty")
+	}
+	if !strings.Contains(v, "://") {
+		return postgresKeyValue(v)
+	}
+	u, err := dsnURL(v, "postgres", "postgresql")
+	if err != nil {
+		return err
+	}
+	// Several hosts are allowed for failover: "db-1:5432,db-2:5432"
+	if u.Host != "" {
+		for _, hp := range strings.Split(u.Host, ",") {
+			if err := dsnHost(hp); err != nil {
+				return err
+			}
+		}
+	}
+	q, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return fmt.Errorf("invalid DSN parameters")
+	}
+	return postgresParams(q.Get("sslmode"), q.Get("port"))
+}
+
+// postgresKeyValue validates a libpq key/value connection string.
+func postgresKeyValue(v string) error {
+	params := map[string]string{}
+	s := strings.TrimSpace(v)
+	for s != "" {
+		key, rest, ok := strings.Cut(s, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return fmt.Errorf("DSN must be a postgres:// URL or key=value pairs")
+		}
+		rest = strings.TrimLeft(rest, " \t")
+		var val string
+		if strings.HasPrefix(rest, "'") {
+			end := 1
+			for end < len(rest) && rest[end] != '\'' {
+				if rest[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(rest) {
+				return fmt.Errorf("unterminated quoted value for %s", key)
+			}
+			val, rest = rest[1:end], rest[end+1:]
+		} else {
+			val, rest, _ = strings.Cut(rest, " ")
+		}
+		params[key] = val
+		s = strings.TrimSpace(rest)
+	}
+	if len(params) == 0 {
+		return fmt.Errorf("DSN must be a postgres:// URL or key=value pairs")
+	}
+	return postgresParams(params["sslmode"], params["port"])
+}
+
+// postgresParams validates the sslmode and port parameters, if set.
+func postgresParams(sslmode, port string) error {
+	switch sslmode {
+	case "", "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		return fmt.Errorf("sslmode must be one of: disable, allow, prefer, require, verify-ca, verify-full")
+	}
+	if port != "" {
+		return Port(port)
+	}
+	return nil
+}
+
+// MySQLDSN validates a MySQL connection string in the
+// github.com/go-sql-driver/mysql form
+// "user:password@tcp(db:3306)/orders?parseTime=true", or a mysql:// URL.
+// The network must be tcp, tcp6 or unix, and a tcp address a valid
+// host:port.
+func MySQLDSN(v string) error {
+	if v == "" {
+		return fmt.Errorf("DSN cannot be empty")
+	}
+	if strings.HasPrefix(v, "mysql://") {
+		u, err := dsnURL(v, "mysql")
+		if err != nil {
+			return err
+		}
+		return dsnHost(u.Host)
+	}
+
+	// The password may contain '@' and '/', so split at the last '@'
+	// before the last '/'
+	slash := strings.LastIndexByte(v, '/')
+	if slash < 0 {
+		return fmt.Errorf("DSN must contain /dbname, e.g. user@tcp(db:3306)/orders")
+	}
+	addr := v[:slash]
+	if at := strings.LastIndexByte(addr, '@'); at >= 0 {
+		addr = addr[at+1:]
+	}
+	if addr != "" {
+		network, address, ok := strings.Cut(addr, "(")
+		if ok && !strings.HasSuffix(address, ")") {
+			return fmt.Errorf("DSN address must be network(address), e.g. tcp(db:3306)")
+		}
+		address = strings.TrimSuffix(address, ")")
+		switch network {
+		case "tcp", "tcp6":
+			if address != "" {
+				if err := dsnHost(address); err != nil {
+					return err
+				}
+			}
+		case "unix":
+			if address == "" {
+				return fmt.Errorf("DSN unix network needs a socket path")
+			}
+		default:
+			return fmt.Errorf("DSN network must be tcp, tcp6 or unix")
+		}
+	}
+	if _, query, ok := strings.Cut(v[slash:], "?"); ok {
+		if _, err := url.ParseQuery(query); err != nil {
+			return fmt.Errorf("invalid DSN parameters")
+		}
+	}
+	return nil
+}
+
+// RedisURL validates a Redis URL such as "rediss://:token@cache:6380/2":
+// scheme redis, rediss (TLS) or unix, a host for the network schemes, and
+// a numeric database in the path.
+func RedisURL(v string) error {
+	if v == "" {
+		return fmt.Errorf("URL cannot be empty")
+	}
+	u, err := dsnURL(v, "redis", "rediss", "unix")
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "unix" {
+		if u.Path == "" {
+			return fmt.Errorf("unix URL needs a socket path")
+		}
+		return nil
+	}
+	if u.Host == "" {
+		return fmt.Errorf("URL must have a host")
+	}
+	if err := dsnHost(u.Host); err != nil {
+		return err
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if n, err := strconv.Atoi(db); err != nil || n < 0 {
+			return fmt.Errorf("database must be a number, e.g. /0")
+		}
+	}
+	return nil
+}
+
+// MongoURI validates a MongoDB connection string such as
+// "mongodb://app@db-1:27017,db-2:27017/orders?replicaSet=rs0" or
+// "mongodb+srv://app@cluster0.example.net/orders". A mongodb+srv URI names
+// exactly one host, without a port.
+func MongoURI(v string) error {
+	if v == "" {
+		return fmt.Errorf("URI cannot be empty")
+	}
+	scheme, rest, ok := strings.Cut(v, "://")
+	if !ok || (scheme != "mongodb" && scheme != "mongodb+srv") {
+		return fmt.Errorf("URI scheme must be mongodb or mongodb+srv")
+	}
+	// url.Parse rejects host lists, so split by hand
+	end := strings.IndexAny(rest, "/?")
+	if end < 0 {
+		end = len(rest)
+	}
+	hosts, tail := rest[:end], rest[end:]
+	if at := strings.LastIndexByte(hosts, '@'); at >= 0 {
+		hosts = hosts[at+1:]
+	}
+	if hosts == "" {
+		return fmt.Errorf("URI must have a host")
+	}
+	list := strings.Split(hosts, ",")
+	if scheme == "mongodb+srv" {
+		if len(list) != 1 {
+			return fmt.Errorf("mongodb+srv URI must name exactly one host")
+		}
+		if Hostname(hosts) != nil {
+			return fmt.Errorf("mongodb+srv URI needs a host name without a port")
+		}
+	}
+	for _, hp := range list {
+		if err := dsnHost(hp); err != nil {
+			return err
+		}
+	}
+	if _, query, ok := strings.Cut(tail, "?"); ok {
+		if _, err := url.ParseQuery(query); err != nil {
+			return fmt.Errorf("invalid URI options")
+		}
+	}
+	return nil
+}
+
+// dsnURL parses v as a URL with one of schemes, without echoing v in
+// errors.
+func dsnURL(v string, schemes ...string) (*url.URL, error) {
+	u, err := url.Parse(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", redactURLError(err))
+	}
+	for _, s := range schemes {
+		if strings.EqualFold(u.Scheme, s) {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("URL scheme must be %s", strings.Join(schemes, " or "))
+}
+
+// dsnHost validates a host with an optional port, e.g. "db:5432" or
+// "[::1]". Underscores are allowed in host names, as in Docker Compose
+// service names.
+func dsnHost(hp string) error {
+	host, port := hp, ""
+	if h, p, err := net.SplitHostPort(hp); err == nil {
+		host, port = h, p
+	} else {
+		host = strings.TrimSuffix(strings.TrimPrefix(hp, "["), "]")
+	}
+	if host == "" {
+		return fmt.Errorf("host cannot be empty")
+	}
+	if net.ParseIP(host) == nil && Hostname(strings.ReplaceAll(host, "_", "-")) != nil {
+		return fmt.Errorf("invalid host name")
+	}
+	if port != "" {
+		return Port(port)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package envreq
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// DetectDuplicateSecrets makes MustValidate of the default registry warn
+// when two different Sensitive variables resolve to the same value, which
+// is usually a copy-paste mistake such as reusing the signing key as the
+// encryption key. Values are compared by digest and never printed. It is
+// off by default.
+func DetectDuplicateSecrets(enabled bool) {
+	std.DetectDuplicateSecrets(enabled)
+}
+
+// DetectDuplicateSecrets enables the duplicate secret warning of the
+// registry. See the package-level DetectDuplicateSecrets.
+func (g *Registry) DetectDuplicateSecrets(enabled bool) {
+	g.dupSecrets.Store(enabled)
+}
+
+// DuplicateSecrets returns the groups of Sensitive variables in results
+// that share a value, each sorted by name, ordered by their first name.
+// Missing and empty values are ignored.
+func DuplicateSecrets(results []Result) [][]string {
+	byDigest := map[[sha256.Size]byte][]string{}
+	for _, res := range results {
+		if !res.Sensitive || !res.Present || res.Value == "" {
+			continue
+		}
+		d := sha256.Sum256([]byte(res.Value))
+		byDigest[d] = append(byDigest[d], res.Name)
+	}
+
+	var groups [][]string
+	for _, names := range byDigest {
+		if len(names) > 1 {
+			sort.Strings(names)
+			groups = append(groups, names)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i][0] < groups[j][0]
+	})
+	return groups
+}
+
+// reportDuplicateSecrets warns on w about Sensitive variables sharing a
+// value.
+func reportDuplicateSecrets(w io.Writer, results []Result) {
+	for _, names := range DuplicateSecrets(results) {
+		fmt.Fprintf(w, "\nWARNING: sensitive variables %s have the same value; each secret should be distinct\n",
+			strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,46 @@
+package envreq_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestDuplicateSecrets(t *testing.T) {
+	t.Setenv("DUP_SIGNING_KEY", "c2VjcmV0LWtleS0x")
+	t.Setenv("DUP_ENCRYPTION_KEY", "c2VjcmV0LWtleS0x")
+	t.Setenv("DUP_SESSION_KEY", "c2VjcmV0LWtleS0y")
+	t.Setenv("DUP_PUBLIC_ID", "c2VjcmV0LWtleS0x")
+
+	g := envreq.New()
+	for _, name := range []string{"DUP_SIGNING_KEY", "DUP_ENCRYPTION_KEY", "DUP_SESSION_KEY"} {
+		g.Check(envreq.Requirement{Name: name, Source: "crypto", Sensitive: true})
+	}
+	// Not sensitive: not compared
+	g.Check(envreq.Requirement{Name: "DUP_PUBLIC_ID", Source: "crypto"})
+
+	want := [][]string{{"DUP_ENCRYPTION_KEY", "DUP_SIGNING_KEY"}}
+	if got := envreq.DuplicateSecrets(g.CheckAll()); !reflect.DeepEqual(got, want) {
+		t.Errorf("DuplicateSecrets = %v, want %v", got, want)
+	}
+
+	var out bytes.Buffer
+	g.SetOutput(&out)
+	g.MustValidate()
+	if strings.Contains(out.String(), "same value") {
+		t.Error("Expected no duplicate warning unless enabled")
+	}
+
+	out.Reset()
+	g.DetectDuplicateSecrets(true)
+	g.MustValidate()
+	if !strings.Contains(out.String(), "WARNING: sensitive variables DUP_ENCRYPTION_KEY, DUP_SIGNING_KEY have the same value") {
+		t.Errorf("Expected a duplicate warning, got:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "c2VjcmV0LWtleS0x") {
+		t.Error("The shared value must never be printed")
+	}
+}
@@ -12,67 +12,215 @@
 //     - Re-accessing already registered vars: allowed (normal caching)
 //     - New OPTIONAL vars: allowed with warning logged
 //     - New REQUIRED vars: immediate panic with full environment report
+//
+// The package-level functions operate on a process-wide default Registry.
+// Libraries, multi-tenant servers and parallel tests can create isolated
+// registries with New.
 package envreq
 
 import (
+    "bytes"
+    "context"
     "fmt"
     "io"
     "log"
     "os"
     "sort"
-    "strings"
     "sync"
     "sync/atomic"
+    "time"
 )
 
 // Requirement declares an environment variable need with validation and metadata.
 type Requirement struct {
-    Name        string             // ENV var name, e.g. "STRIPE_API_KEY"
-    Source      string             // Owning package/component for reporting
-    Description string             // Short help text for humans
-    Optional    bool               // Default is required
-    Default     string             // Optional default if missing
-    Validate    func(string) error // Optional value validator
-    Sensitive   bool               // If true, never show value, redact in reports
+    Name        string                 // ENV var name, e.g. "STRIPE_API_KEY"
+    Source      string                 // Owning package/component for reporting
+    Description string                 // Short help text for humans
+    Optional    bool                   // Default is required
+    Default     string                 // Optional default if missing
+    DefaultFunc func() (string, error) // Computes the default at Check time when Default is empty
+    Validate    func(string) error     // Optional value validator
+    Transform   func(string) string    // Applied to the value before validation and caching, e.g. strings.TrimSpace
+    // List marks a comma-separated value, e.g. "host1:9092,host2:9092".
+    // Elements are trimmed and each is checked with ElementValidator (after
+    // Validate, which sees the whole value); Result.Values splits it.
+    List             bool
+    ElementValidator func(string) error
+    // DeepValidate is a slow check of the value, e.g. that a broker
+    // address is reachable. It does not run in Check but in the deep stage
+    // of Validate and MustValidate, after Validate and the group
+    // constraints; see Stage. Its failures do not change what Check returns.
+    DeepValidate func(string) error
+    // DeepTimeout is the budget of DeepValidate: a check still running
+    // after it fails with ErrTimeout while the other deep checks go on.
+    // Zero means no budget beyond the context of ValidateContext.
+    DeepTimeout time.Duration
+    // DeepSeverity SeverityWarning reports DeepValidate failures in
+    // ValidationError.Warnings without failing validation. The zero value
+    // means SeverityError.
+    DeepSeverity Severity
+    NoExpand     bool                // Take "${VAR}" in the value and default literally instead of expanding it
+    Sensitive    bool                // If true, never show value, redact in reports
+    NeverShow    bool                // Stricter than Sensitive: no suffix, fingerprint or validator detail even in debug output
+    Owner        string              // Owning team or contact, e.g. "team-payments"
+    Example      string              // Example value for docs (never a real secret)
+    DocsURL      string              // Link to documentation on how to obtain/set the value
+    SecretRef    string              // Reference resolved by a RefSource, e.g. "vault:secret/data/app#key"
+    Aliases      []string            // Names tried in order when Name is unset, e.g. "DATABASE_URL_OLD"
+    DisplayFunc  func(string) string // Summarizes a present value in debug reports, e.g. DisplayHost; not used for NeverShow
+    // External marks a variable this binary never reads but the tools it
+    // spawns do, e.g. GIT_SSH_COMMAND or AWS_PROFILE: it is validated and
+    // documented like any other, and its validated value is exported to
+    // the process environment so that child processes inherit it.
+    External bool
+    // RequiredIf and RequiredWhen make the variable required only while
+    // the condition holds, e.g. RequiredIf: Equals("APP_ENV", "production");
+    // Optional is then ignored. Either one holding is enough.
+    RequiredIf   Condition
+    RequiredWhen func() bool
+    // RequiredAfter gives a newly required variable a grace period: until
+    // the cutoff passes it is treated as optional and, when unset,
+    // reported with status "grace" as a warning; afterwards it is
+    // required. Fleets can roll out new configuration this way without
+    // synchronized deploys, e.g. RequiredAfter: FromVersion("2.0.0").
+    RequiredAfter Cutoff
+    // Deprecated marks a variable being phased out. With ReplacedBy set,
+    // Check reads the new name first and falls back to this one; using the
+    // deprecated name logs a warning once and reports status "deprecated".
+    Deprecated bool
+    ReplacedBy string
+
+    firstUse bool // registered only with FirstUse; see problems
 }
 
 // Result contains the loaded and validated environment variable.
 type Result struct {
     Requirement
-    Present bool   // whether env or default was available
-    Value   string // loaded value (never printed in reports if Sensitive)
-    Err     error  // validator error (if any)
+    Present    bool        // whether env or default was available
+    Value      string      // loaded value (never printed in reports if Sensitive)
+    Provenance string      // where the value came from, e.g. ProvenanceEnv
+    Shadowed   []Shadow    // other sources with a different value, lower precedence
+    From       string      // variable that supplied the value when not Name, e.g. an alias
+    Consulted  []Consulted // every lookup made, in order; see Explain
+    Late       bool        // registered after Freeze inside a late registration window
+    Grace      bool        // required, but not before Requirement.RequiredAfter passes
+    ResolvedAt time.Time   // when the value was loaded and validated
+    Err        error       // validator error (if any)
 }
 
-var (
+// Provenance values recorded on Result.
+const (
+    ProvenanceEnv       = "env"            // process environment
+    ProvenanceDefault   = "default"        // Requirement.Default or DefaultFunc
+    ProvenanceLocal     = "local override" // .env.local developer override layer
+    ProvenanceDotenv    = "dotenv"         // files loaded with LoadDotenv
+    ProvenanceFile      = "file"           // file named by NAME_FILE (see FileSuffix)
+    ProvenanceGenerated = "generated"      // fabricated by GenerateDevValues
+)
+
+// Registry holds registered requirements and their cached results.
+// The zero value is not usable; create registries with New.
+type Registry struct {
     mu     sync.RWMutex
-    reg    = map[string]Requirement{}
-    cache  = map[string]Result{}
+    reg    map[string]Requirement
+    cache  map[string]resolved
     frozen atomic.Bool
-)
+
+    localVars     map[string]string // local override layer
+    dotenvVars    map[string]string // dotenv layer, below the environment
+    loads         []envFiles        // LoadDotenv and LoadLocalOverrides calls, replayed by WaitValid
+    sources       []Source          // source chain, see SetSources
+    providers     []*providerStats  // lookup statistics, parallel to sources
+    devGenerate   bool              // fabricate missing values, see GenerateDevValues
+    devSeed       uint64
+    frozenSources map[string]bool   // sources locked by FreezeSource
+    freezeExempt  map[string]bool   // sources excluded from Freeze
+    lateWindows   []string          // open late registration windows, innermost last
+    lateNames     map[string]bool   // vars registered inside a late window
+    strs          map[string]string // intern table for CompactMemory
+    compact       atomic.Bool
+    runtimeInfo   atomic.Bool     // see IncludeRuntimeInfo
+    dupSecrets    atomic.Bool     // see DetectDuplicateSecrets
+    stopSeverity  atomic.Int32    // see SetStopSeverity
+    devWait       atomic.Bool     // see WaitOnFailure
+    constraints   []constraint    // group constraints, see AllOrNone
+    deprecWarned  map[string]bool // deprecated vars already warned about
+    declared      map[string]bool // see DeclareNames
+    emitted       map[Event]bool  // events already delivered, see OnEvent
+
+    ioMu     sync.RWMutex
+    logger   Logger        // diagnostics, see SetLogger
+    out      io.Writer     // failure reports, see SetOutput
+    handlers []func(Event) // registration-time events, see OnEvent
+    usage    func(Usage)   // opt-in usage reporting, see SetUsageSink
+    build    BuildInfo     // report header, see SetBuildInfo
+
+    late     lateState     // post-Freeze optional registration counters
+    periodic periodicState // revalidation counters
+    hot      hotState      // hot path detector
+}
+
+// New returns an empty, unfrozen registry.
+func New() *Registry {
+    r := &Registry{logger: log.Default(), out: os.Stderr}
+    r.reset()
+    return r
+}
+
+// std is the registry used by the package-level functions.
+var std = New()
+
+// Default returns the process-wide registry used by the package-level functions.
+func Default() *Registry {
+    return std
+}
 
 // Check declares (or references) a requirement, reads & validates immediately,
 // caches the value, and returns a Result you can use inline like os.Getenv.
 func Check(r Requirement) Result {
-    if frozen.Load() {
-        // Check if this is a new registration after freeze
-        mu.RLock()
-        _, exists := reg[r.Name]
-        mu.RUnlock()
+    return std.check(r, 1)
+}
 
-        if !exists {
+// Check declares (or references) a requirement in the registry, reads &
+// validates immediately, caches the value, and returns the Result.
+func (g *Registry) Check(r Requirement) Result {
+    return g.check(r, 1)
+}
+
+// check implements Check. skip is the number of stack frames between the
+// caller of Check and this function, for hot path call sites.
+func (g *Registry) check(r Requirement, skip int) Result {
+    if isFrozen, exempt := g.frozenFor(r.Source); isFrozen {
+        g.recordHotCall(r.Name, skip+1)
+
+        // Check if this is a new registration after freeze
+        g.mu.RLock()
+        _, exists := g.reg[r.Name]
+        g.mu.RUnlock()
+
+        if !exists && g.inLateWindow(r.Source) {
+            // Inside a late registration window: allowed, flagged for the report
+            g.mu.Lock()
+            g.lateNames[r.Name] = true
+            g.mu.Unlock()
+            g.late.windowed.Add(1)
+        } else if !exists {
             // New registration after freeze
-            if r.Optional {
-                // Optional: just log a warning
-                log.Printf("⚠️  envreq: Optional environment variable registered after Freeze(): %s (from %s)", r.Name, r.Source)
+            if !g.required(r) || r.RequiredAfter.pending(g) {
+                // Optional: log a warning (deduplicated and rate limited),
+                // unless the source was excluded from Freeze
+                if !exempt {
+                    g.warnLateOptional(r)
+                }
             } else {
                 // Required: panic immediately with full context
-                log.Printf("🚨 envreq: REQUIRED environment variable registered after Freeze(): %s (from %s)", r.Name, r.Source)
-                log.Println("📋 envreq: Complete environment state at time of panic:")
+                g.logf("🚨 envreq: REQUIRED environment variable registered after Freeze(): %s (from %s)", r.Name, r.Source)
+                g.logf("📋 envreq: Complete environment state at time of panic:")
 
                 // Show current state before panicking
-                results := CheckAll()
-                Report(os.Stderr, results)
+                results := g.CheckAll()
+                reportBuild(g.output(), g.BuildInfo())
+                Report(g.output(), results)
 
                 panic(fmt.Sprintf(
                     "envreq: REQUIRED environment variable '%s' registered after Freeze() (from: %s)\n"+
@@ -86,9 +234,21 @@ func Check(r Requirement) Result {
         // If already registered, allow re-access (normal caching behavior)
     }
 
-    mu.Lock()
+    declaredSensitive := r.Sensitive
+    if r.NeverShow {
+        // NeverShow implies everything Sensitive does
+        r.Sensitive = true
+    }
+
+    handlers := g.eventHandlers()
+    var events []Event
+    isNew := false
+    g.mu.Lock()
     // Merge into registry (stricter wins)
-    if existing, ok := reg[r.Name]; ok {
+    if existing, ok := g.reg[r.Name]; ok {
+        if len(handlers) > 0 {
+            events = mergeEvents(existing, r)
+        }
         merged := existing
         // Required wins over optional
         if !existing.Optional || !r.Optional {
@@ -104,84 +264,370 @@ func Check(r Requirement) Result {
         if merged.Validate == nil && r.Validate != nil {
             merged.Validate = r.Validate
         }
+        if merged.Transform == nil && r.Transform != nil {
+            merged.Transform = r.Transform
+        }
+        if merged.ElementValidator == nil && r.ElementValidator != nil {
+            merged.ElementValidator = r.ElementValidator
+        }
+        if merged.DeepValidate == nil && r.DeepValidate != nil {
+            merged.DeepValidate = r.DeepValidate
+        }
+        if merged.DeepTimeout == 0 {
+            merged.DeepTimeout = r.DeepTimeout
+        }
+        if merged.DeepSeverity == 0 {
+            merged.DeepSeverity = r.DeepSeverity
+        }
+        merged.List = existing.List || r.List
         if merged.Default == "" && r.Default != "" {
             merged.Default = r.Default
         }
+        if merged.DefaultFunc == nil && r.DefaultFunc != nil {
+            merged.DefaultFunc = r.DefaultFunc
+        }
+        if merged.DisplayFunc == nil && r.DisplayFunc != nil {
+            merged.DisplayFunc = r.DisplayFunc
+        }
+        if merged.Owner == "" && r.Owner != "" {
+            merged.Owner = r.Owner
+        }
+        if merged.Example == "" && r.Example != "" {
+            merged.Example = r.Example
+        }
+        if merged.DocsURL == "" && r.DocsURL != "" {
+            merged.DocsURL = r.DocsURL
+        }
+        if merged.SecretRef == "" && r.SecretRef != "" {
+            merged.SecretRef = r.SecretRef
+        }
+        if (!existing.Optional && existing.RequiredAfter.passed == nil) || (!r.Optional && r.RequiredAfter.passed == nil) {
+            // Stricter wins: a required registration without a grace period ends it
+            merged.RequiredAfter = Cutoff{}
+        } else if merged.RequiredAfter.passed == nil {
+            merged.RequiredAfter = r.RequiredAfter
+        }
+        if merged.ReplacedBy == "" && r.ReplacedBy != "" {
+            merged.ReplacedBy = r.ReplacedBy
+        }
+        merged.Deprecated = existing.Deprecated || r.Deprecated
+        merged.Aliases = mergeAliases(existing.Aliases, r.Aliases)
+        mergeCondition(&merged, existing, r)
         // Sensitive wins (more restrictive)
         if existing.Sensitive || r.Sensitive {
             merged.Sensitive = true
         }
-        reg[r.Name] = merged
+        merged.NeverShow = existing.NeverShow || r.NeverShow
+        merged.NoExpand = existing.NoExpand || r.NoExpand
+        merged.External = existing.External || r.External
+        // A single eager registration makes the variable a startup check
+        merged.firstUse = existing.firstUse && r.firstUse
+        g.reg[r.Name] = merged
         r = merged
     } else {
-        reg[r.Name] = r
+        if g.compact.Load() {
+            r = g.internRequirement(r)
+        }
+        g.reg[r.Name] = r
+        isNew = true
+        if len(handlers) > 0 {
+            events = registrationEvents(r, declaredSensitive)
+        }
+    }
+    g.mu.Unlock()
+
+    if len(events) > 0 {
+        g.emit(handlers, events)
+    }
+
+    if isNew {
+        if err := r.Verify(); err != nil {
+            g.logf("⚠️  envreq: invalid requirement declaration (from %s): %v", r.Source, err)
+        }
     }
-    mu.Unlock()
 
     // Check if already cached
-    mu.RLock()
-    if cached, ok := cache[r.Name]; ok {
-        mu.RUnlock()
-        return cached
+    g.mu.RLock()
+    if cached, ok := g.cache[r.Name]; ok {
+        g.mu.RUnlock()
+        return cached.result(r)
     }
-    mu.RUnlock()
+    g.mu.RUnlock()
 
     // Load & validate, cache the Result
-    val, ok := os.LookupEnv(r.Name)
-    if !ok && r.Default != "" {
-        val, ok = r.Default, true
+    res := g.evaluate(r)
+
+    if g.isLate(r.Name) {
+        res.Late = true
+        if (!res.Present && !res.Optional) || res.Err != nil {
+            g.logf("🚨 envreq: late registration of %s (from %s) is missing or invalid", r.Name, r.Source)
+        }
     }
 
-    var verr error
-    if ok && r.Validate != nil {
+    g.mu.Lock()
+    g.cache[r.Name] = resolvedFrom(res)
+    g.mu.Unlock()
+
+    if r.External {
+        g.export(res)
+    }
+    return res
+}
+
+// evaluate resolves and validates r without consulting or updating the
+// cache.
+func (g *Registry) evaluate(r Requirement) Result {
+    required := g.required(r)
+    grace := required && r.RequiredAfter.pending(g)
+    r.Optional = !required || grace
+    rs := g.resolve(r)
+    val, ok, prov, verr := rs.val, rs.ok, rs.prov, rs.err
+
+    if ok && verr == nil {
+        // A value built from a secret is as secret as the secret
+        var sec secrecy
+        val, sec, verr = g.expand(r, val, nil)
+        sec.apply(&r)
+    }
+    if ok && r.Transform != nil {
+        val = r.Transform(val)
+    }
+    if ok && verr == nil && r.Validate != nil {
         verr = r.Validate(val)
     }
+    if ok && verr == nil && r.List {
+        verr = checkList(r, val)
+    }
+    if ok && verr == nil {
+        g.warnCertExpiry(r, val)
+    }
+    if verr == nil && prov != ProvenanceDefault {
+        // An invalid default fails everywhere, not only where the var is unset
+        verr = checkDefault(r)
+    }
+    verr = conceal(r, verr)
 
     res := Result{
         Requirement: r,
         Present:     ok,
         Value:       val,
+        Provenance:  prov,
+        Shadowed:    rs.shadowed,
+        From:        rs.from,
+        Consulted:   rs.consulted,
+        Grace:       grace,
+        ResolvedAt:  time.Now(),
         Err:         verr,
     }
+    if deprecatedInUse(res) {
+        g.warnDeprecated(r)
+    }
+    return res
+}
 
-    mu.Lock()
-    cache[r.Name] = res
-    mu.Unlock()
+// Shadow records a lower-precedence source that also supplied a value,
+// different from the one used, for the same variable.
+type Shadow struct {
+    Provenance  string // source that was shadowed, e.g. ProvenanceEnv
+    Fingerprint string // stable digest of the shadowed value (never the value), "" for Sensitive variables
+}
 
-    return res
+// layer is one value source consulted by resolve, highest precedence first.
+type layer struct {
+    provenance string
+    source     Source
+    stats      *providerStats // nil for the layers outside the source chain
+}
+
+// layers returns the value sources in precedence order: local overrides,
+// the configured source chain, NAME_FILE secret files, then dotenv files.
+func (g *Registry) layers() []layer {
+    g.mu.RLock()
+    sources, stats := g.sources, g.providers
+    g.mu.RUnlock()
+
+    out := make([]layer, 0, len(sources)+3)
+    out = append(out, layer{ProvenanceLocal, lookupFunc(g.localOverride), nil})
+    for i, s := range sources {
+        out = append(out, layer{sourceName(s), s, stats[i]})
+    }
+    return append(out,
+        layer{ProvenanceFile, SourceFunc(g.fileValue), nil},
+        layer{ProvenanceDotenv, lookupFunc(g.dotenvValue), nil},
+    )
+}
+
+// lookupFunc adapts an infallible lookup to a Source.
+func lookupFunc(f func(name string) (string, bool)) Source {
+    return SourceFunc(func(name string) (string, bool, error) {
+        v, ok := f(name)
+        return v, ok, nil
+    })
+}
+
+// resolve looks up the value for r, honoring the local override layer,
+// the source chain (the process environment by default), NAME_FILE secret
+// files, dotenv files, the default (Default, then DefaultFunc) and finally
+// a generated development value, in that order. Every layer is consulted
+// so that values hidden by a higher-precedence layer are reported as
+// shadowed. A source error is returned only when no layer supplied a
+// value.
+//
+// The layers are searched for each of r.names() in turn; from is the name
+// that supplied the value when it is not r.Name. Every lookup is recorded
+// in consulted.
+func (g *Registry) resolve(r Requirement) resolution {
+    var rs resolution
+    for _, name := range r.names() {
+        rn := r
+        if name != r.Name {
+            // A SecretRef belongs to the declared name only
+            rn.Name, rn.SecretRef = name, ""
+        }
+        g.resolveLayers(rn, &rs)
+        if rs.ok {
+            if name != r.Name {
+                rs.from = name
+            }
+            rs.err = nil
+            return rs
+        }
+    }
+
+    fallback := func(prov, v string, err error) resolution {
+        c := Consulted{Provenance: prov, Name: r.Name, Outcome: OutcomeUnset, Err: err}
+        if err != nil {
+            c.Outcome = OutcomeError
+        } else if v != "" {
+            c.Outcome = OutcomeUsed
+            rs.val, rs.ok, rs.prov, rs.err = v, true, prov, nil
+        }
+        rs.consulted = append(rs.consulted, c)
+        return rs
+    }
+    if r.Default != "" {
+        return fallback(ProvenanceDefault, r.Default, nil)
+    }
+    if r.DefaultFunc != nil {
+        v, derr := r.DefaultFunc()
+        if derr != nil {
+            rs.err = fmt.Errorf("DefaultFunc: %w", derr)
+            return fallback(ProvenanceDefault, "", rs.err)
+        }
+        if v != "" {
+            return fallback(ProvenanceDefault, v, nil)
+        }
+    }
+    if v, ok := g.generated(r); ok {
+        return fallback(ProvenanceGenerated, v, nil)
+    }
+    if rs.err == nil && r.SecretRef != "" {
+        rs.err = fmt.Errorf("SecretRef %q did not resolve (no source for its scheme, or not found)", r.SecretRef)
+    }
+    return rs
+}
+
+// resolution is the outcome of resolve.
+type resolution struct {
+    val       string
+    ok        bool
+    prov      string
+    shadowed  []Shadow
+    from      string
+    consulted []Consulted
+    err       error // first source error, when no layer supplied a value
+}
+
+// resolveLayers looks up r.Name in every layer, recording the lookups in
+// rs and the value in rs when a layer supplies one. Successful lookups in
+// remote sources (any but Env and MapSource) are cached per source until
+// Revalidate or SetSources, so that aliases, ${VAR} references and
+// conditions do not cost a round trip each.
+func (g *Registry) resolveLayers(r Requirement, rs *resolution) {
+    key := lookupKey{name: r.Name, ref: r.SecretRef}
+    for _, l := range g.layers() {
+        var v string
+        var found, cached bool
+        var lerr error
+        remote := l.stats != nil && !localSource(l.source)
+        if remote {
+            v, found, cached = l.stats.cached(key)
+        }
+        if !cached {
+            start := time.Now()
+            v, found, lerr = lookup(l.source, r)
+            if l.stats != nil {
+                l.stats.record(time.Since(start), lerr)
+            }
+            if remote && lerr == nil {
+                l.stats.store(key, v, found)
+            }
+        }
+        c := Consulted{Provenance: l.provenance, Name: r.Name, Outcome: OutcomeUnset, Cached: cached}
+        switch {
+        case lerr != nil:
+            c.Outcome, c.Err = OutcomeError, lerr
+            if rs.err == nil {
+                rs.err = fmt.Errorf("source %s: %w", l.provenance, lerr)
+            }
+        case found && !rs.ok:
+            c.Outcome = OutcomeUsed
+            rs.val, rs.ok, rs.prov = v, true, l.provenance
+        case found && v != rs.val:
+            c.Outcome = OutcomeShadowed
+            sh := Shadow{Provenance: l.provenance}
+            if !r.Sensitive {
+                sh.Fingerprint = fingerprint(v)
+            }
+            rs.shadowed = append(rs.shadowed, sh)
+        case found:
+            c.Outcome = OutcomeSame
+        }
+        rs.consulted = append(rs.consulted, c)
+    }
 }
 
 // Value fetches a cached value by name. Returns empty string and false if not found.
 func Value(name string) (string, bool) {
-    mu.RLock()
-    defer mu.RUnlock()
+    return std.Value(name)
+}
+
+// Value fetches a cached value by name. Returns empty string and false if not found.
+func (g *Registry) Value(name string) (string, bool) {
+    g.mu.RLock()
+    defer g.mu.RUnlock()
 
-    if res, ok := cache[name]; ok {
-        return res.Value, res.Present
+    if res, ok := g.cache[name]; ok {
+        return res.value, res.present
     }
     return "", false
 }
 
 // CheckAll returns a snapshot of all known results (merged from prior Check calls).
 func CheckAll() []Result {
-    mu.RLock()
+    return std.CheckAll()
+}
+
+// CheckAll returns a snapshot of all known results in the registry.
+func (g *Registry) CheckAll() []Result {
+    g.mu.RLock()
 
     // Copy cached results first (populated by Check calls)
-    out := make([]Result, 0, len(reg))
+    out := make([]Result, 0, len(g.reg))
     unchecked := make([]Requirement, 0)
 
-    for name, req := range reg {
-        if res, ok := cache[name]; ok {
-            out = append(out, res)
+    for name, req := range g.reg {
+        if res, ok := g.cache[name]; ok {
+            out = append(out, res.result(req))
         } else {
             unchecked = append(unchecked, req)
         }
     }
-    mu.RUnlock()
+    g.mu.RUnlock()
 
     // Check any requirements that haven't been loaded yet
     for _, req := range unchecked {
-        res := Check(req)
+        res := g.check(req, 1)
         out = append(out, res)
     }
 
@@ -193,75 +639,87 @@ func CheckAll() []Result {
     return out
 }
 
-// Report writes a safe report (no values printed; sensitive redacted).
+// Report writes a safe report of all results in the registry.
 // Returns count of missing required variables.
-func Report(w io.Writer, results []Result) (missing int) {
-    showValues := os.Getenv("ENVREQ_SHOW_VALUES") == "1"
-
-    fmt.Fprintf(w, "%-20s %-12s %-8s %-9s %-8s %s\n",
-        "ENV", "SOURCE", "REQUIRED", "SENSITIVE", "STATUS", "DETAILS")
-    fmt.Fprintf(w, "%-20s %-12s %-8s %-9s %-8s %s\n",
-        strings.Repeat("-", 20),
-        strings.Repeat("-", 12),
-        strings.Repeat("-", 8),
-        strings.Repeat("-", 9),
-        strings.Repeat("-", 8),
-        strings.Repeat("-", 20))
-
-    for _, res := range results {
-        required := "no"
-        if !res.Optional {
-            required = "yes"
-        }
-
-        sensitive := "no"
-        if res.Sensitive {
-            sensitive = "yes"
-        }
-
-        status := "ok"
-        details := res.Description
-
-        if !res.Present && !res.Optional {
-            status = "missing"
-            missing++
-        } else if res.Err != nil {
-            status = "invalid"
-            details = fmt.Sprintf("Error: %v", res.Err)
-            if !res.Optional {
-                missing++
-            }
-        } else if showValues && res.Present && !res.Sensitive {
-            // Only show values in debug mode for non-sensitive vars
-            if len(res.Value) > 20 {
-                details = fmt.Sprintf("%s (value: %s...)", res.Description, res.Value[:17])
-            } else {
-                details = fmt.Sprintf("%s (value: %s)", res.Description, res.Value)
-            }
-        } else if showValues && res.Present && res.Sensitive {
-            // Show redacted value for sensitive vars in debug mode
-            if len(res.Value) >= 4 {
-                details = fmt.Sprintf("%s (value: ••••%s)", res.Description, res.Value[len(res.Value)-4:])
-            } else {
-                details = fmt.Sprintf("%s (value: ••••)", res.Description)
-            }
-        }
-
-        fmt.Fprintf(w, "%-20s %-12s %-8s %-9s %-8s %s\n",
-            res.Name, res.Source, required, sensitive, status, details)
-    }
-
+func (g *Registry) Report(w io.Writer) (missing int) {
+    results := g.CheckAll()
+    constraints := g.constraintErrors(results)
+    reportBuild(w, g.BuildInfo())
+    missing, _ = reportPaged(w, results, 0, nil, showValues(), incompleteGroups(constraints))
+    reportConstraints(w, constraints)
+    reportProviders(w, g.Providers())
     return missing
 }
 
 // MustValidate runs CheckAll + Report and exits 2 if any required item is missing/invalid.
+// Use Validate to get the problems as an error instead of exiting.
+// In describe mode (ENVREQ_DESCRIBE set) it writes the schema and exits 0 instead.
+// When ENVREQ_SCHEMA names a committed schema file, drift from it also exits 2.
+// When ENVREQ_ROLLBACK_SCHEMA names the previous release's schema (file or URL),
+// the report says whether a rollback would start; this never exits.
 func MustValidate() {
-    results := CheckAll()
-    missing := Report(os.Stderr, results)
-    if missing > 0 {
-        fmt.Fprintf(os.Stderr, "\n%d required environment variable(s) missing or invalid\n", missing)
+    std.MustValidate()
+}
+
+// MustValidate runs CheckAll + Report on the registry and exits 2 if any
+// required item is missing/invalid. See the package-level MustValidate.
+func (g *Registry) MustValidate() {
+    g.mustValidate(context.Background())
+}
+
+// mustValidate implements MustValidate and MustValidateContext.
+func (g *Registry) mustValidate(ctx context.Context) {
+    if g.describeMode() {
+        os.Exit(0)
+    }
+
+    out := g.output()
+    results, verr, err := g.validate(ctx)
+    reportBuild(out, g.BuildInfo())
+    reportPaged(out, results, 0, nil, showValues(), incompleteGroups(verr.Constraints))
+    reportProviders(out, g.Providers())
+    reportSecrets(out, results)
+    if g.dupSecrets.Load() {
+        reportDuplicateSecrets(out, results)
+    }
+    if location := os.Getenv("ENVREQ_ROLLBACK_SCHEMA"); location != "" {
+        rollback, ok := withContext(ctx, func() []byte {
+            var buf bytes.Buffer
+            g.reportRollback(&buf, location)
+            return buf.Bytes()
+        })
+        if ok {
+            out.Write(rollback)
+        } else {
+            fmt.Fprintf(out, "\nWARNING: cannot check rollback safety: %v\n", timeoutError(ctx))
+        }
+    }
+    reportWarnings(out, verr.Warnings)
+    if err == nil && !verr.failed() {
+        return
+    }
+
+    if err != nil {
+        fmt.Fprintf(out, "\n%v\n", err)
+        if g.waitForFix(ctx, out) {
+            return
+        }
         os.Exit(2)
     }
+    if len(verr.Problems) > 0 {
+        fmt.Fprintf(out, "\n%d required environment variable(s) missing or invalid\n", len(verr.Problems))
+    }
+    reportConstraints(out, verr.Constraints)
+    if len(verr.Skipped) > 0 {
+        fmt.Fprintf(out, "\nValidation stopped early; skipped stage(s): %s\n", stageList(verr.Skipped))
+    }
+    if verr.Drift != nil {
+        fmt.Fprintf(out, "\n%v\n", verr.Drift)
+    }
+    if g.waitForFix(ctx, out) {
+        return
+    }
+    os.Exit(2)
 }
 
 // Freeze prevents new required registrations after validation.
@@ -269,17 +727,61 @@ func MustValidate() {
 // - New REQUIRED variables: panic immediately with full environment report
 // - New OPTIONAL variables: log warning but allow
 // - Re-accessing existing variables: always allowed (normal caching)
-func Freeze() {
-    frozen.Store(true)
-    log.Println("envreq: Registry frozen - new required registrations will panic")
+//
+// Sources listed in excluding (e.g. plugins) may keep registering optional
+// variables without warnings; their new required variables still panic.
+func Freeze(excluding ...string) {
+    std.Freeze(excluding...)
+}
+
+// Freeze prevents new required registrations in the registry.
+// See the package-level Freeze.
+func (g *Registry) Freeze(excluding ...string) {
+    g.mu.Lock()
+    for _, s := range excluding {
+        g.freezeExempt[s] = true
+    }
+    g.mu.Unlock()
+
+    g.frozen.Store(true)
+    g.logf("envreq: Registry frozen - new required registrations will panic")
 }
 
 // Reset clears all registrations and cache. Useful for testing.
 func Reset() {
-    mu.Lock()
-    defer mu.Unlock()
+    std.Reset()
+    SetProfile("")
+}
+
+// Reset clears all registrations, cache and freeze state of the registry.
+func (g *Registry) Reset() {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+
+    g.reset()
+}
 
-    reg = map[string]Requirement{}
-    cache = map[string]Result{}
-    frozen.Store(false)
+// reset reinitializes all state. Callers must hold mu (or own g exclusively).
+func (g *Registry) reset() {
+    g.reg = map[string]Requirement{}
+    g.cache = map[string]resolved{}
+    g.localVars = map[string]string{}
+    g.dotenvVars = map[string]string{}
+    g.loads = nil
+    g.sources = []Source{Env}
+    g.providers = newProviderStats(1)
+    g.devGenerate = false
+    g.frozenSources = map[string]bool{}
+    g.freezeExempt = map[string]bool{}
+    g.lateWindows = nil
+    g.lateNames = map[string]bool{}
+    g.constraints = nil
+    g.deprecWarned = map[string]bool{}
+    g.declared = map[string]bool{}
+    g.emitted = map[Event]bool{}
+    g.strs = map[string]string{}
+    g.frozen.Store(false)
+    g.late.reset()
+    g.periodic.reset()
+    g.hot.reset()
 }
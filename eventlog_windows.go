@@ -0,0 +1,99 @@
+package envreq
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// Windows event types used by EventLog.
+const (
+	eventlogError   = 0x0001
+	eventlogWarning = 0x0002
+)
+
+var (
+	advapi32                  = syscall.NewLazyDLL("advapi32.dll")
+	procRegisterEventSourceW  = advapi32.NewProc("RegisterEventSourceW")
+	procDeregisterEventSource = advapi32.NewProc("DeregisterEventSource")
+	procReportEventW          = advapi32.NewProc("ReportEventW")
+)
+
+// EventLog writes to the Windows Event Log. When running as a Windows
+// service stderr is discarded, so route the registry there:
+//
+//	el, err := envreq.NewEventLog("payments-svc")
+//	if err == nil {
+//		defer el.Close()
+//		envreq.SetLogger(el) // diagnostics as warning events
+//		envreq.SetOutput(el) // validation failure reports as error events
+//	}
+//
+// The event source should be registered (e.g. with New-EventLog) for the
+// Event Viewer to render messages without a "description not found" note.
+type EventLog struct {
+	handle syscall.Handle
+}
+
+// NewEventLog opens the event log for the given event source name.
+func NewEventLog(source string) (*EventLog, error) {
+	name, err := syscall.UTF16PtrFromString(source)
+	if err != nil {
+		return nil, err
+	}
+	h, _, err := procRegisterEventSourceW.Call(0, uintptr(unsafe.Pointer(name)))
+	if h == 0 {
+		return nil, fmt.Errorf("envreq: RegisterEventSource(%s): %w", source, err)
+	}
+	return &EventLog{handle: syscall.Handle(h)}, nil
+}
+
+// Printf reports a warning event. It implements Logger.
+func (e *EventLog) Printf(format string, v ...any) {
+	e.report(eventlogWarning, fmt.Sprintf(format, v...))
+}
+
+// Write reports p as one error event. It implements io.Writer; reports are
+// rendered in a single Write per page, so each page becomes one event.
+func (e *EventLog) Write(p []byte) (int, error) {
+	msg := strings.TrimSpace(string(p))
+	if msg == "" {
+		return len(p), nil
+	}
+	if err := e.report(eventlogError, msg); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close deregisters the event source.
+func (e *EventLog) Close() error {
+	if r, _, err := procDeregisterEventSource.Call(uintptr(e.handle)); r == 0 {
+		return err
+	}
+	return nil
+}
+
+func (e *EventLog) report(etype uint16, msg string) error {
+	text, err := syscall.UTF16PtrFromString(strings.ReplaceAll(msg, "\x00", ""))
+	if err != nil {
+		return err
+	}
+	strs := []*uint16{text}
+	r, _, err := procReportEventW.Call(
+		uintptr(e.handle),
+		uintptr(etype),
+		0, // category
+		1, // event ID
+		0, // user SID
+		1, // number of strings
+		0, // raw data size
+		uintptr(unsafe.Pointer(&strs[0])),
+		0, // raw data
+	)
+	if r == 0 {
+		return fmt.Errorf("envreq: ReportEvent: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package envreq
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// EventKind classifies a registration-time Event.
+type EventKind string
+
+// Kinds of registration-time events.
+const (
+	// EventMergeConflict: two registrations of a variable disagree on a
+	// field that cannot be merged, e.g. different defaults. The first
+	// registration's value is kept.
+	EventMergeConflict EventKind = "merge-conflict"
+	// EventStricterWins: a registration made an existing variable
+	// required, sensitive or never shown.
+	EventStricterWins EventKind = "stricter-wins"
+	// EventSensitiveImplied: a variable was classified Sensitive without
+	// being declared so, e.g. because it is NeverShow.
+	EventSensitiveImplied EventKind = "sensitive-implied"
+	// EventNameConvention: a name or alias is not UPPER_SNAKE_CASE.
+	EventNameConvention EventKind = "name-convention"
+)
+
+// Event is an anomaly noticed while a requirement is registered, delivered
+// to the handlers set with OnEvent as it happens. Events never carry
+// values, defaults included.
+type Event struct {
+	Kind   EventKind
+	Name   string // variable name
+	Source string // component whose registration triggered the event
+	Detail string // what happened, e.g. "Default differs from the registration by server"
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("envreq: %s: %s (from %s): %s", e.Kind, e.Name, e.Source, e.Detail)
+}
+
+// OnEvent adds a handler for registration-time events of the default
+// registry. Handlers run synchronously in the registering goroutine, so
+// they should be quick; each distinct event is delivered once:
+//
+//	envreq.OnEvent(func(e envreq.Event) {
+//	    slog.Warn("config anomaly", "kind", e.Kind, "var", e.Name, "source", e.Source, "detail", e.Detail)
+//	})
+func OnEvent(h func(Event)) {
+	std.OnEvent(h)
+}
+
+// OnEvent adds a handler for the registry's registration-time events.
+func (g *Registry) OnEvent(h func(Event)) {
+	g.ioMu.Lock()
+	g.handlers = append(g.handlers, h)
+	g.ioMu.Unlock()
+}
+
+// eventHandlers returns the registered event handlers.
+func (g *Registry) eventHandlers() []func(Event) {
+	g.ioMu.RLock()
+	defer g.ioMu.RUnlock()
+
+	return g.handlers
+}
+
+// emit delivers the events not delivered before to the handlers. Callers
+// must not hold mu.
+func (g *Registry) emit(handlers []func(Event), events []Event) {
+	for _, e := range events {
+		g.mu.Lock()
+		seen := g.emitted[e]
+		g.emitted[e] = true
+		g.mu.Unlock()
+		if seen {
+			continue
+		}
+		for _, h := range handlers {
+			h(e)
+		}
+	}
+}
+
+// registrationEvents returns the events of registering r, declared with
+// sensitive as its own Sensitive field, for the first time.
+func registrationEvents(r Requirement, sensitive bool) []Event {
+	var events []Event
+	if r.Sensitive && !sensitive {
+		events = append(events, Event{EventSensitiveImplied, r.Name, r.Source, "NeverShow implies Sensitive"})
+	}
+	for _, name := range append([]string{r.Name}, r.Aliases...) {
+		if name != "" && validName(name) && !upperSnake(name) {
+			detail := "name is not UPPER_SNAKE_CASE"
+			if name != r.Name {
+				detail = fmt.Sprintf("alias %s is not UPPER_SNAKE_CASE", name)
+			}
+			events = append(events, Event{EventNameConvention, r.Name, r.Source, detail})
+		}
+	}
+	return events
+}
+
+// mergeEvents returns the events of merging r into existing.
+func mergeEvents(existing, r Requirement) []Event {
+	var events []Event
+	conflict := func(field string) {
+		events = append(events, Event{EventMergeConflict, r.Name, r.Source,
+			fmt.Sprintf("%s differs from the registration by %s; keeping the first", field, existing.Source)})
+	}
+	if existing.Default != "" && r.Default != "" && existing.Default != r.Default {
+		conflict("Default")
+	}
+	if funcsDiffer(existing.Validate, r.Validate) {
+		conflict("Validate")
+	}
+	if funcsDiffer(existing.DeepValidate, r.DeepValidate) {
+		conflict("DeepValidate")
+	}
+	if funcsDiffer(existing.Transform, r.Transform) {
+		conflict("Transform")
+	}
+	if existing.SecretRef != "" && r.SecretRef != "" && existing.SecretRef != r.SecretRef {
+		conflict("SecretRef")
+	}
+	if existing.ReplacedBy != "" && r.ReplacedBy != "" && existing.ReplacedBy != r.ReplacedBy {
+		conflict("ReplacedBy")
+	}
+
+	upgrade := func(what string) {
+		events = append(events, Event{EventStricterWins, r.Name, r.Source,
+			fmt.Sprintf("now %s; registered by %s as not", what, existing.Source)})
+	}
+	if existing.Optional && !existing.conditional() && !r.Optional && !r.conditional() {
+		upgrade("required")
+	}
+	if !existing.Sensitive && r.Sensitive {
+		upgrade("sensitive")
+	}
+	if !existing.NeverShow && r.NeverShow {
+		upgrade("never shown")
+	}
+	return events
+}
+
+// funcsDiffer reports whether a and b are both set to different functions.
+// Closures of the same factory are the same function, so OneOf("a") and
+// OneOf("b") are not told apart.
+func funcsDiffer[F any](a, b F) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.IsNil() || vb.IsNil() {
+		return false
+	}
+	return va.Pointer() != vb.Pointer()
+}
+
+// upperSnake reports whether name is UPPER_SNAKE_CASE: upper-case letters,
+// digits and underscores, starting with a letter or underscore.
+func upperSnake(name string) bool {
+	for i, c := range name {
+		switch {
+		case c >= 'A' && c <= 'Z', c == '_':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package envreq_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestEvents(t *testing.T) {
+	g := envreq.New()
+	var events []envreq.Event
+	g.OnEvent(func(e envreq.Event) {
+		events = append(events, e)
+	})
+	kinds := func() map[envreq.EventKind]int {
+		n := map[envreq.EventKind]int{}
+		for _, e := range events {
+			n[e.Kind]++
+		}
+		return n
+	}
+
+	g.Check(envreq.Requirement{Name: "EVT_TIMEOUT", Source: "client", Optional: true, Default: "5s", Validate: envreq.Duration})
+	g.Check(envreq.Requirement{Name: "EVT_TIMEOUT", Source: "server", Default: "10s", Validate: envreq.Port})
+	if n := kinds(); n[envreq.EventMergeConflict] != 2 || n[envreq.EventStricterWins] != 1 {
+		t.Errorf("Expected Default and Validate conflicts and a required upgrade, got %v", events)
+	}
+	for _, e := range events {
+		if strings.Contains(e.String(), "10s") {
+			t.Errorf("Expected no default values in events, got %s", e)
+		}
+	}
+
+	// The same conflict is delivered once
+	events = nil
+	g.Check(envreq.Requirement{Name: "EVT_TIMEOUT", Source: "server", Default: "10s", Validate: envreq.Port})
+	if len(events) != 0 {
+		t.Errorf("Expected repeated events to be dropped, got %v", events)
+	}
+
+	g.Check(envreq.Requirement{Name: "evt-token", Source: "auth", Optional: true, NeverShow: true, Aliases: []string{"EVT_TOKEN"}})
+	if n := kinds(); n[envreq.EventSensitiveImplied] != 1 || n[envreq.EventNameConvention] != 1 {
+		t.Errorf("Expected implied sensitivity and a naming event, got %v", events)
+	}
+
+	events = nil
+	g.Check(envreq.Requirement{Name: "EVT_TIMEOUT", Source: "secrets", Sensitive: true})
+	if len(events) != 1 || events[0].Kind != envreq.EventStricterWins || !strings.Contains(events[0].Detail, "sensitive") {
+		t.Errorf("Expected a sensitive upgrade, got %v", events)
+	}
+}
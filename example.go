@@ -0,0 +1,101 @@
+package envreq
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteExample renders the registered requirements as a .env.example file:
+// variables grouped by source, each preceded by its description and
+// REQUIRED / SENSITIVE markers. Optional variables are pre-filled with
+// their default and others with their Example, if any; sensitive defaults
+// are never written.
+func WriteExample(w io.Writer) error {
+	return std.WriteExample(w)
+}
+
+// WriteExample renders the registry as a .env.example file.
+// See the package-level WriteExample.
+func (g *Registry) WriteExample(w io.Writer) error {
+	return g.Describe().WriteExample(w)
+}
+
+// WriteExample renders s as a .env.example file. See the package-level
+// WriteExample.
+func (s Schema) WriteExample(w io.Writer) error {
+	vars := append([]SchemaVar(nil), s.Vars...)
+	sort.SliceStable(vars, func(i, j int) bool {
+		if vars[i].Source != vars[j].Source {
+			return vars[i].Source < vars[j].Source
+		}
+		return vars[i].Name < vars[j].Name
+	})
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("# Generated by envreq from the registered requirements.\n")
+	bw.WriteString("# Copy to .env and fill in; never commit real secrets.\n")
+
+	source := "\x00"
+	for _, v := range vars {
+		if v.Source != source {
+			source = v.Source
+			name := source
+			if name == "" {
+				name = "(no source)"
+			}
+			bw.WriteString("\n# --- " + name + " ---\n")
+		}
+
+		bw.WriteByte('\n')
+		for _, line := range strings.Split(v.Description, "\n") {
+			if line != "" {
+				bw.WriteString("# " + line + "\n")
+			}
+		}
+		var marks []string
+		if v.Required {
+			marks = append(marks, "REQUIRED")
+		} else if v.RequiredIf != "" {
+			marks = append(marks, "REQUIRED if "+v.RequiredIf)
+		}
+		if v.Sensitive {
+			marks = append(marks, "SENSITIVE")
+		}
+		if v.Deprecated {
+			if v.ReplacedBy != "" {
+				marks = append(marks, "DEPRECATED, use "+v.ReplacedBy)
+			} else {
+				marks = append(marks, "DEPRECATED")
+			}
+		}
+		if len(marks) > 0 {
+			bw.WriteString("# " + strings.Join(marks, " ") + "\n")
+		}
+		if v.External {
+			bw.WriteString("# read by child processes\n")
+		}
+		if len(v.Aliases) > 0 {
+			bw.WriteString("# also read from: " + strings.Join(v.Aliases, ", ") + "\n")
+		}
+		if v.DocsURL != "" {
+			bw.WriteString("# docs: " + v.DocsURL + "\n")
+		}
+
+		value := v.Example
+		if !v.Required && v.Default != "" {
+			value = v.Default
+		}
+		bw.WriteString(v.Name + "=" + quoteEnvValue(value) + "\n")
+	}
+	return bw.Flush()
+}
+
+// quoteEnvValue quotes v when parseEnvFile would otherwise change it.
+func quoteEnvValue(v string) string {
+	if v == "" || (strings.TrimSpace(v) == v && !strings.ContainsAny(v, "#\"'")) {
+		return v
+	}
+	return `"` + v + `"`
+}
@@ -0,0 +1,133 @@
+package envreq
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrExpansionCycle is returned when ${VAR} references lead back to the
+// variable being expanded.
+var ErrExpansionCycle = errors.New("${VAR} expansion cycle")
+
+// secrecy is the strictest redaction of the variables a value was
+// expanded from, which the expanded value inherits.
+type secrecy struct {
+	sensitive, neverShow bool
+}
+
+// merge folds o into s.
+func (s *secrecy) merge(o secrecy) {
+	s.sensitive = s.sensitive || o.sensitive
+	s.neverShow = s.neverShow || o.neverShow
+}
+
+// apply makes r at least as strict as s.
+func (s secrecy) apply(r *Requirement) {
+	r.Sensitive = r.Sensitive || s.sensitive
+	r.NeverShow = r.NeverShow || s.neverShow
+}
+
+// expand replaces ${NAME} references in val, the value of r, with the
+// values of the registered variables they name, and returns the
+// redaction of those variables. $${ stands for a literal ${, and ${...}
+// that is not a valid NAME or names an unregistered variable is left
+// alone. seen holds the variables being expanded, outermost first, for
+// cycle detection.
+func (g *Registry) expand(r Requirement, val string, seen []string) (string, secrecy, error) {
+	var sec secrecy
+	if r.NoExpand || !strings.Contains(val, "${") {
+		return val, sec, nil
+	}
+	seen = append(seen, r.Name)
+
+	var b strings.Builder
+	for {
+		i := strings.Index(val, "${")
+		if i < 0 {
+			break
+		}
+		if i > 0 && val[i-1] == '$' {
+			// $${ is an escaped ${
+			b.WriteString(val[:i] + "{")
+			val = val[i+2:]
+			continue
+		}
+		end := strings.IndexByte(val[i:], '}')
+		if end < 0 {
+			break
+		}
+		name := val[i+2 : i+end]
+		if !refName(name) {
+			// Not a reference, e.g. part of a generated password
+			b.WriteString(val[:i+2])
+			val = val[i+2:]
+			continue
+		}
+		g.mu.RLock()
+		ref, ok := g.reg[name]
+		g.mu.RUnlock()
+		if !ok {
+			// Not ours, e.g. a template placeholder for another tool
+			b.WriteString(val[:i+end+1])
+			val = val[i+end+1:]
+			continue
+		}
+		v, refSec, err := g.expandRef(ref, seen)
+		if err != nil {
+			return "", sec, err
+		}
+		sec.merge(refSec)
+		b.WriteString(val[:i] + v)
+		val = val[i+end+1:]
+	}
+	b.WriteString(val)
+	return b.String(), sec, nil
+}
+
+// expandRef returns the value of r, referenced as ${r.Name}, itself
+// expanded and transformed, with the redaction of the variables it was
+// expanded from.
+func (g *Registry) expandRef(r Requirement, seen []string) (string, secrecy, error) {
+	var sec secrecy
+	if slices.Contains(seen, r.Name) {
+		return "", sec, fmt.Errorf("%w: %s -> %s", ErrExpansionCycle, strings.Join(seen, " -> "), r.Name)
+	}
+
+	rs := g.resolve(r)
+	v, err := rs.val, rs.err
+	if err != nil {
+		return "", sec, fmt.Errorf("${%s}: %w", r.Name, err)
+	}
+	if !rs.ok {
+		return "", sec, fmt.Errorf("${%s} is not set", r.Name)
+	}
+	if v, sec, err = g.expand(r, v, seen); err != nil {
+		return "", sec, err
+	}
+	if r.Transform != nil {
+		v = r.Transform(v)
+	}
+	sec.merge(secrecy{sensitive: r.Sensitive || r.NeverShow, neverShow: r.NeverShow})
+	return v, sec, nil
+}
+
+// refName reports whether s can be the NAME of a ${NAME} reference:
+// letters, digits and underscores, not starting with a digit.
+func refName(s string) bool {
+	if s == "" || s[0] >= '0' && s[0] <= '9' {
+		return false
+	}
+	for _, c := range []byte(s) {
+		if c != '_' && !(c >= 'A' && c <= 'Z') && !(c >= 'a' && c <= 'z') && !(c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// hasReferences reports whether r's value v would be expanded.
+func hasReferences(r Requirement, v string) bool {
+	return !r.NoExpand && strings.Contains(v, "${")
+}
@@ -0,0 +1,68 @@
+package envreq_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("EXP_PUBLIC_HOST", "api.example.com")
+	t.Setenv("EXP_CALLBACK_URL", "https://${EXP_PUBLIC_HOST}/hook")
+	t.Setenv("EXP_LITERAL", "https://${EXP_PUBLIC_HOST}/hook")
+	t.Setenv("EXP_ESCAPED", "cost $${EXP_PUBLIC_HOST} ${not a ref}")
+	t.Setenv("EXP_A", "${EXP_B}")
+	t.Setenv("EXP_B", "x-${EXP_A}")
+	t.Setenv("EXP_UNKNOWN", "${EXP_NOT_REGISTERED}")
+	t.Setenv("EXP_DB_PASSWORD", "s3cret")
+	t.Setenv("EXP_DB_URL", "postgres://app:${EXP_DB_PASSWORD}@db/app")
+	t.Setenv("EXP_SIGNING_KEY", "k3y")
+	t.Setenv("EXP_KEY_COPY", "${EXP_SIGNING_KEY}")
+	t.Setenv("EXP_KEY_QUOTED", "'${EXP_KEY_COPY}'")
+
+	g := envreq.New()
+	g.Check(envreq.Requirement{Name: "EXP_PUBLIC_HOST"})
+
+	res := g.Check(envreq.Requirement{Name: "EXP_CALLBACK_URL", Validate: envreq.URL})
+	if res.Err != nil || res.Value != "https://api.example.com/hook" {
+		t.Errorf("Expected the host expanded, got %q (%v)", res.Value, res.Err)
+	}
+
+	def := envreq.Requirement{Name: "EXP_STATUS_URL", Optional: true, Default: "https://${EXP_PUBLIC_HOST}/status", Validate: envreq.URL}
+	if err := def.Verify(); err != nil {
+		t.Errorf("Expected a default with references to pass Verify, got %v", err)
+	}
+	if res := g.Check(def); res.Value != "https://api.example.com/status" {
+		t.Errorf("Expected the default expanded, got %q", res.Value)
+	}
+
+	if res := g.Check(envreq.Requirement{Name: "EXP_LITERAL", NoExpand: true}); res.Value != "https://${EXP_PUBLIC_HOST}/hook" {
+		t.Errorf("Expected NoExpand to keep the value, got %q", res.Value)
+	}
+	if res := g.Check(envreq.Requirement{Name: "EXP_ESCAPED"}); res.Value != "cost ${EXP_PUBLIC_HOST} ${not a ref}" {
+		t.Errorf("Expected escapes and non-references kept, got %q", res.Value)
+	}
+
+	g.Check(envreq.Requirement{Name: "EXP_B", Optional: true})
+	res = g.Check(envreq.Requirement{Name: "EXP_A"})
+	if !errors.Is(res.Err, envreq.ErrExpansionCycle) || !strings.Contains(res.Err.Error(), "EXP_A -> EXP_B -> EXP_A") {
+		t.Errorf("Expected an expansion cycle, got %v", res.Err)
+	}
+
+	if res := g.Check(envreq.Requirement{Name: "EXP_UNKNOWN"}); res.Err != nil || res.Value != "${EXP_NOT_REGISTERED}" {
+		t.Errorf("Expected an unregistered reference kept literally, got %q (%v)", res.Value, res.Err)
+	}
+
+	// Values built from secrets are as secret as the secrets
+	g.Check(envreq.Requirement{Name: "EXP_DB_PASSWORD", Sensitive: true})
+	g.Check(envreq.Requirement{Name: "EXP_SIGNING_KEY", NeverShow: true})
+	g.Check(envreq.Requirement{Name: "EXP_KEY_COPY"})
+	if res := g.Check(envreq.Requirement{Name: "EXP_DB_URL", Validate: envreq.URL}); res.Err != nil || !res.Sensitive || res.NeverShow {
+		t.Errorf("Expected a URL with an expanded password to be Sensitive, got %+v", res)
+	}
+	if res := g.Check(envreq.Requirement{Name: "EXP_KEY_QUOTED"}); res.Value != "'k3y'" || !res.NeverShow || !res.Sensitive {
+		t.Errorf("Expected NeverShow inherited through a nested reference, got %+v", res)
+	}
+}
@@ -0,0 +1,26 @@
+package envreq
+
+import "os"
+
+// export makes the process environment hold the validated value of the
+// External res under its name, so that child processes, which inherit
+// the environment, see the value Check validated: a default, a value from
+// another source or alias, or a transformed value. Invalid or missing
+// values are left alone, and so are Sensitive ones: every child process
+// would inherit a secret that was deliberately kept out of the
+// environment, e.g. behind a SecretRef.
+func (g *Registry) export(res Result) {
+	if !res.Present || res.Err != nil {
+		return
+	}
+	if cur, ok := os.LookupEnv(res.Name); ok && cur == res.Value {
+		return
+	}
+	if res.Sensitive {
+		g.logf("⚠️  envreq: not exporting sensitive %s to child processes (from %s); pass it to the child explicitly", res.Name, res.Source)
+		return
+	}
+	if err := os.Setenv(res.Name, res.Value); err != nil {
+		g.logf("⚠️  envreq: cannot export %s for child processes (from %s): %v", res.Name, res.Source, err)
+	}
+}
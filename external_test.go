@@ -0,0 +1,59 @@
+package envreq_test
+
+import (
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestExternal(t *testing.T) {
+	g := envreq.New()
+	t.Setenv("EXT_PROFILE", "")
+	os.Unsetenv("EXT_PROFILE")
+	t.Setenv("EXT_SSH", "  ssh -i key  ")
+	t.Setenv("EXT_BAD", "nope")
+
+	// A default is exported so that child processes see it
+	g.Check(envreq.Requirement{Name: "EXT_PROFILE", Source: "deploy", External: true, Default: "deploy-bot"})
+	if v, ok := os.LookupEnv("EXT_PROFILE"); !ok || v != "deploy-bot" {
+		t.Errorf("Expected the default to be exported, got %q, %v", v, ok)
+	}
+
+	// So is the transformed value
+	g.Check(envreq.Requirement{Name: "EXT_SSH", Source: "deploy", External: true, Transform: strings.TrimSpace})
+	if v := os.Getenv("EXT_SSH"); v != "ssh -i key" {
+		t.Errorf("Expected the transformed value to be exported, got %q", v)
+	}
+
+	// Invalid values are validated but left alone
+	res := g.Check(envreq.Requirement{Name: "EXT_BAD", Source: "deploy", External: true, Validate: envreq.Bool})
+	if res.Err == nil || os.Getenv("EXT_BAD") != "nope" {
+		t.Errorf("Expected an invalid value to be reported and not exported, got %v", res.Err)
+	}
+
+	// Secrets stay out of the environment
+	var logs strings.Builder
+	g.SetLogger(log.New(&logs, "", 0))
+	g.SetSources(envreq.Env, envreq.MapSource{"EXT_TOKEN": "s3cr3t", "EXT_KEY": "k3y"})
+	t.Setenv("EXT_TOKEN", "")
+	os.Unsetenv("EXT_TOKEN")
+	t.Setenv("EXT_KEY", "")
+	os.Unsetenv("EXT_KEY")
+	g.Check(envreq.Requirement{Name: "EXT_TOKEN", Source: "deploy", External: true, Sensitive: true})
+	g.Check(envreq.Requirement{Name: "EXT_KEY", Source: "deploy", External: true, NeverShow: true})
+	for _, name := range []string{"EXT_TOKEN", "EXT_KEY"} {
+		if _, ok := os.LookupEnv(name); ok {
+			t.Errorf("Expected sensitive %s not to be exported", name)
+		}
+		if !strings.Contains(logs.String(), "not exporting sensitive "+name) {
+			t.Errorf("Expected a warning for %s, got %q", name, logs.String())
+		}
+	}
+
+	if s := g.Describe(); !s.Vars[0].External {
+		t.Errorf("Expected external in the schema, got %+v", s.Vars[0])
+	}
+}
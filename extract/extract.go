@@ -0,0 +1,647 @@
+// Package extract builds an envreq schema from Go source code without
+// executing it.
+//
+// It parses the source with go/ast and looks for envreq.Requirement composite
+// literals (the argument of envreq.Check and friends) whose fields are
+// literals or string constants, either of the same package or of imported
+// packages such as names.DatabaseURL. Requirements it cannot resolve
+// statically are reported as Issues rather than guessed. This suits security
+// review pipelines that are not allowed to run arbitrary service code.
+package extract
+
+import (
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bbmumford/envreq"
+)
+
+// ImportPath is the import path whose Requirement literals are extracted.
+const ImportPath = "github.com/bbmumford/envreq"
+
+// Issue is a Requirement literal that could not be fully extracted.
+type Issue struct {
+	Pos     string // file:line:column
+	Message string
+	Skipped bool // the Requirement is missing from the schema
+}
+
+func (i Issue) String() string {
+	return i.Pos + ": " + i.Message
+}
+
+// Dir extracts requirements from every .go file below root, skipping
+// vendor, testdata and hidden directories. Test files are included only
+// when tests is true.
+func Dir(root string, tests bool) (envreq.Schema, []Issue, error) {
+	x := newExtractor()
+	err := walkPackages(root, tests, x.pkg)
+	if err != nil {
+		return envreq.Schema{}, nil, err
+	}
+	return x.schema(), x.issues, nil
+}
+
+// walkPackages calls pkg with the .go files of every directory below root,
+// in directory order, skipping vendor, testdata and hidden directories.
+func walkPackages(root string, tests bool, pkg func(paths []string) error) error {
+	byDir := map[string][]string{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".go") && (tests || !strings.HasSuffix(path, "_test.go")) {
+			dir := filepath.Dir(path)
+			byDir[dir] = append(byDir[dir], path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		if err := pkg(byDir[dir]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Files extracts requirements from the given files, which are treated as
+// one package for constant resolution.
+func Files(paths ...string) (envreq.Schema, []Issue, error) {
+	x := newExtractor()
+	if err := x.pkg(paths); err != nil {
+		return envreq.Schema{}, nil, err
+	}
+	return x.schema(), x.issues, nil
+}
+
+type extractor struct {
+	fset     *token.FileSet
+	vars     map[string]*envreq.SchemaVar
+	issues   []Issue
+	pkgName  string // package of the file being inspected
+	importer types.Importer
+	imported map[string]importedConsts // by import path
+}
+
+// importedConsts are the exported string constants of an imported package.
+type importedConsts struct {
+	name   string // package name
+	consts map[string]string
+	err    error
+}
+
+func newExtractor() *extractor {
+	fset := token.NewFileSet()
+	return &extractor{
+		fset:     fset,
+		vars:     map[string]*envreq.SchemaVar{},
+		importer: importer.ForCompiler(fset, "source", nil),
+		imported: map[string]importedConsts{},
+	}
+}
+
+// pkg parses the files of one directory and extracts their requirements.
+func (x *extractor) pkg(paths []string) error {
+	files := make([]*ast.File, 0, len(paths))
+	for _, p := range paths {
+		f, err := parser.ParseFile(x.fset, p, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+
+	consts := stringConsts(files)
+	for i, f := range files {
+		local := importName(f)
+		if local == "" {
+			continue
+		}
+		x.pkgName = f.Name.Name
+		fileConsts := x.importConsts(f, local, filepath.Dir(paths[i]), consts)
+		ast.Inspect(f, func(n ast.Node) bool {
+			lit, ok := n.(*ast.CompositeLit)
+			if ok && isRequirementType(lit.Type, local) {
+				x.literal(lit, local, fileConsts)
+			}
+			return true
+		})
+	}
+	return nil
+}
+
+// importConsts returns consts extended with the string constants of the
+// packages f imports and uses inside Requirement literals, keyed as they
+// are written in f, e.g. "names.DatabaseURL". The envreq import itself is
+// not loaded. Packages that cannot be loaded are reported as issues.
+func (x *extractor) importConsts(f *ast.File, local, dir string, consts map[string]string) map[string]string {
+	used := map[string]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok || !isRequirementType(lit.Type, local) {
+			return true
+		}
+		ast.Inspect(lit, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if id, ok := sel.X.(*ast.Ident); ok {
+					used[id.Name] = true
+				}
+			}
+			return true
+		})
+		return false
+	})
+
+	out, cloned := consts, false
+	for _, imp := range f.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || p == ImportPath {
+			continue
+		}
+		name := ""
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name == "_" || (name != "." && !used[name] && !used[path.Base(p)]) {
+			continue
+		}
+		pc := x.loadConsts(p, dir)
+		if pc.err != nil {
+			x.issues = append(x.issues, Issue{Pos: x.fset.Position(imp.Pos()).String(), Message: "cannot resolve constants of " + p + ": " + pc.err.Error()})
+			continue
+		}
+		if name == "" {
+			name = pc.name
+		}
+		if !used[name] && name != "." {
+			continue
+		}
+		if !cloned {
+			out, cloned = maps.Clone(consts), true
+		}
+		for k, v := range pc.consts {
+			if name == "." {
+				out[k] = v
+			} else {
+				out[name+"."+k] = v
+			}
+		}
+	}
+	return out
+}
+
+// loadConsts type-checks the package with import path p, as imported from
+// dir, and returns its exported string constants. Results are cached.
+func (x *extractor) loadConsts(p, dir string) importedConsts {
+	if c, ok := x.imported[p]; ok {
+		return c
+	}
+	c := importedConsts{consts: map[string]string{}}
+	defer func() { x.imported[p] = c }()
+
+	// The go command resolves module imports relative to Dir
+	ctxt := build.Default
+	ctxt.Dir = dir
+	bp, err := ctxt.Import(p, dir, 0)
+	if err != nil {
+		c.err = err
+		return c
+	}
+	files := make([]*ast.File, 0, len(bp.GoFiles))
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(x.fset, filepath.Join(bp.Dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			c.err = err
+			return c
+		}
+		files = append(files, f)
+	}
+	// Errors elsewhere in the package do not affect its constants
+	conf := types.Config{Importer: x.importer, Error: func(error) {}}
+	pkg, err := conf.Check(p, x.fset, files, nil)
+	if pkg == nil {
+		c.err = err
+		return c
+	}
+	c.name = pkg.Name()
+	for _, name := range pkg.Scope().Names() {
+		k, ok := pkg.Scope().Lookup(name).(*types.Const)
+		if ok && k.Exported() && k.Val().Kind() == constant.String {
+			c.consts[name] = constant.StringVal(k.Val())
+		}
+	}
+	return c
+}
+
+// literal extracts one Requirement composite literal.
+func (x *extractor) literal(lit *ast.CompositeLit, local string, consts map[string]string) {
+	var v envreq.SchemaVar
+	v.Required = true
+	pos := x.fset.Position(lit.Pos()).String()
+	var conds [2]string // RequiredIf, RequiredWhen
+
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			x.issues = append(x.issues, Issue{Pos: pos, Message: "positional Requirement literal is not supported", Skipped: true})
+			return
+		}
+		key, _ := kv.Key.(*ast.Ident)
+		if key == nil {
+			continue
+		}
+
+		switch key.Name {
+		case "Name", "Source", "Description", "Owner", "Example", "DocsURL", "Default", "ReplacedBy":
+			s, ok := stringValue(kv.Value, consts)
+			if !ok {
+				if key.Name == "Name" {
+					x.issues = append(x.issues, Issue{Pos: pos, Message: "Name is not a string literal or constant", Skipped: true})
+					return
+				}
+				x.issues = append(x.issues, Issue{Pos: pos, Message: key.Name + " is not a string literal or constant"})
+				continue
+			}
+			setString(&v, key.Name, s)
+		case "Optional", "Sensitive", "NeverShow", "Deprecated", "List", "External":
+			b, ok := boolValue(kv.Value)
+			if !ok {
+				x.issues = append(x.issues, Issue{Pos: pos, Message: key.Name + " is not a boolean literal; assuming the stricter value"})
+				// Stricter: required, sensitive, never shown, not deprecated
+				b = key.Name == "Sensitive" || key.Name == "NeverShow"
+			}
+			switch key.Name {
+			case "Optional":
+				v.Required = !b
+			case "Sensitive":
+				v.Sensitive = v.Sensitive || b
+			case "NeverShow":
+				v.NeverShow = b
+				v.Sensitive = v.Sensitive || b
+			case "Deprecated":
+				v.Deprecated = b
+			case "List":
+				v.List = b
+			case "External":
+				v.External = b
+			}
+		case "Aliases":
+			aliases, ok := stringList(kv.Value, consts)
+			if !ok {
+				x.issues = append(x.issues, Issue{Pos: pos, Message: "Aliases is not a []string literal of strings or constants"})
+			}
+			v.Aliases = aliases
+		case "RequiredIf":
+			conds[0] = conditionDesc(kv.Value, local, consts)
+			if conds[0] == "" {
+				x.issues = append(x.issues, Issue{Pos: pos, Message: "RequiredIf is not an Equals or IsSet call with literal arguments"})
+				conds[0] = "RequiredIf"
+			}
+		case "RequiredWhen":
+			if id, ok := kv.Value.(*ast.Ident); !ok || id.Name != "nil" {
+				conds[1] = x.validatorName(kv.Value, local)
+				if conds[1] == "" {
+					conds[1] = "RequiredWhen"
+				}
+			}
+		case "DefaultFunc":
+			if id, ok := kv.Value.(*ast.Ident); !ok || id.Name != "nil" {
+				v.DefaultFunc = x.validatorName(kv.Value, local)
+				if v.DefaultFunc == "" {
+					v.DefaultFunc = "custom"
+				}
+			}
+		case "DeepValidate":
+			if id, ok := kv.Value.(*ast.Ident); !ok || id.Name != "nil" {
+				v.DeepValidator = x.validatorName(kv.Value, local)
+				if v.DeepValidator == "" {
+					v.DeepValidator = "custom"
+				}
+			}
+		case "ElementValidator":
+			if id, ok := kv.Value.(*ast.Ident); !ok || id.Name != "nil" {
+				v.ElementValidator = x.validatorName(kv.Value, local)
+			}
+		case "Validate":
+			if id, ok := kv.Value.(*ast.Ident); !ok || id.Name != "nil" {
+				v.Validated = true
+				v.Validator = x.validatorName(kv.Value, local)
+			}
+		}
+	}
+
+	if v.Name == "" {
+		x.issues = append(x.issues, Issue{Pos: pos, Message: "Requirement without a Name", Skipped: true})
+		return
+	}
+	var parts []string
+	for _, c := range conds {
+		if c != "" {
+			parts = append(parts, c)
+		}
+	}
+	if len(parts) > 0 {
+		v.RequiredIf = strings.Join(parts, " or ")
+		v.Required = false
+	}
+	x.merge(v)
+}
+
+// merge adds v using the registry's stricter-wins rules.
+func (x *extractor) merge(v envreq.SchemaVar) {
+	if v.Sensitive {
+		v.Default = ""
+	}
+	cur, ok := x.vars[v.Name]
+	if !ok {
+		x.vars[v.Name] = &v
+		return
+	}
+	cur.Required = cur.Required || v.Required
+	cur.Sensitive = cur.Sensitive || v.Sensitive
+	cur.NeverShow = cur.NeverShow || v.NeverShow
+	cur.Validated = cur.Validated || v.Validated
+	cur.Deprecated = cur.Deprecated || v.Deprecated
+	cur.List = cur.List || v.List
+	cur.External = cur.External || v.External
+	for _, alias := range v.Aliases {
+		if !slices.Contains(cur.Aliases, alias) {
+			cur.Aliases = append(cur.Aliases, alias)
+		}
+	}
+	for _, f := range []struct{ dst, src *string }{
+		{&cur.Validator, &v.Validator},
+		{&cur.ElementValidator, &v.ElementValidator},
+		{&cur.DeepValidator, &v.DeepValidator},
+		{&cur.Source, &v.Source},
+		{&cur.Description, &v.Description},
+		{&cur.Owner, &v.Owner},
+		{&cur.Example, &v.Example},
+		{&cur.DocsURL, &v.DocsURL},
+		{&cur.Default, &v.Default},
+		{&cur.DefaultFunc, &v.DefaultFunc},
+		{&cur.RequiredIf, &v.RequiredIf},
+		{&cur.ReplacedBy, &v.ReplacedBy},
+	} {
+		if *f.dst == "" {
+			*f.dst = *f.src
+		}
+	}
+	if cur.Sensitive {
+		cur.Default = ""
+	}
+	if cur.Required {
+		cur.RequiredIf = ""
+	}
+}
+
+func (x *extractor) schema() envreq.Schema {
+	s := envreq.Schema{Version: envreq.SchemaVersion, Vars: make([]envreq.SchemaVar, 0, len(x.vars))}
+	for _, v := range x.vars {
+		s.Vars = append(s.Vars, *v)
+	}
+	sort.Slice(s.Vars, func(i, j int) bool {
+		return s.Vars[i].Name < s.Vars[j].Name
+	})
+	return s
+}
+
+func setString(v *envreq.SchemaVar, field, s string) {
+	switch field {
+	case "Name":
+		v.Name = s
+	case "Source":
+		v.Source = s
+	case "Description":
+		v.Description = s
+	case "Owner":
+		v.Owner = s
+	case "Example":
+		v.Example = s
+	case "DocsURL":
+		v.DocsURL = s
+	case "Default":
+		v.Default = s
+	case "ReplacedBy":
+		v.ReplacedBy = s
+	}
+}
+
+// stringList returns the elements of a []string composite literal. It
+// reports false when e is not one, or an element is not a string literal
+// or constant; the elements that are understood are still returned.
+func stringList(e ast.Expr, consts map[string]string) ([]string, bool) {
+	lit, ok := e.(*ast.CompositeLit)
+	if !ok {
+		return nil, false
+	}
+	var list []string
+	for _, elt := range lit.Elts {
+		s, ok := stringValue(elt, consts)
+		if !ok {
+			return list, false
+		}
+		list = append(list, s)
+	}
+	return list, true
+}
+
+// importName returns the local name of the envreq import in f, or "" when
+// f does not import it. Dot imports are reported as ".".
+func importName(f *ast.File) string {
+	return importNameOf(f, ImportPath)
+}
+
+// importNameOf returns the local name of the import of pkgPath in f, or ""
+// when f does not import it. Unnamed imports are assumed to use the last
+// path element as the package name.
+func importNameOf(f *ast.File, pkgPath string) string {
+	for _, imp := range f.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || p != pkgPath {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return path.Base(pkgPath)
+	}
+	return ""
+}
+
+// validatorName names the Validate expression the way the registry does,
+// e.g. "envreq.URL" or "envreq.OneOf" for envreq.OneOf("a", "b"). Function
+// literals are not named.
+func (x *extractor) validatorName(expr ast.Expr, local string) string {
+	if call, ok := expr.(*ast.CallExpr); ok {
+		expr = call.Fun
+	}
+	switch e := expr.(type) {
+	case *ast.SelectorExpr:
+		pkg, ok := e.X.(*ast.Ident)
+		if !ok {
+			return ""
+		}
+		if pkg.Name == local {
+			return "envreq." + e.Sel.Name
+		}
+		return pkg.Name + "." + e.Sel.Name
+	case *ast.Ident:
+		if local == "." {
+			return ""
+		}
+		return x.pkgName + "." + e.Name
+	}
+	return ""
+}
+
+// conditionDesc describes an envreq.Equals or envreq.IsSet call the way
+// Condition.String does, or returns "" when expr is something else or has
+// non-constant arguments.
+func conditionDesc(expr ast.Expr, local string, consts map[string]string) string {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return ""
+	}
+	var fn string
+	switch f := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		if pkg, ok := f.X.(*ast.Ident); ok && pkg.Name == local {
+			fn = f.Sel.Name
+		}
+	case *ast.Ident:
+		if local == "." {
+			fn = f.Name
+		}
+	}
+	args := make([]string, len(call.Args))
+	for i, a := range call.Args {
+		s, ok := stringValue(a, consts)
+		if !ok {
+			return ""
+		}
+		args[i] = s
+	}
+	switch {
+	case fn == "Equals":
+		return args[0] + "=" + strings.Join(args[1:], "|")
+	case fn == "IsSet" && len(args) == 1:
+		return args[0] + " is set"
+	}
+	return ""
+}
+
+// isRequirementType reports whether expr names envreq.Requirement.
+func isRequirementType(expr ast.Expr, local string) bool {
+	switch t := expr.(type) {
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		return ok && pkg.Name == local && t.Sel.Name == "Requirement"
+	case *ast.Ident:
+		return local == "." && t.Name == "Requirement"
+	}
+	return false
+}
+
+// stringConsts collects package-level string constants declared with a
+// literal value.
+func stringConsts(files []*ast.File) map[string]string {
+	consts := map[string]string{}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if i < len(vs.Values) {
+						if s, ok := stringValue(vs.Values[i], nil); ok {
+							consts[name.Name] = s
+						}
+					}
+				}
+			}
+		}
+	}
+	return consts
+}
+
+// stringValue evaluates a string literal, a known constant, a constant of
+// an imported package (keyed "pkg.Name" in consts), or a concatenation of
+// those.
+func stringValue(expr ast.Expr, consts map[string]string) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	case *ast.Ident:
+		s, ok := consts[e.Name]
+		return s, ok
+	case *ast.SelectorExpr:
+		pkg, ok := e.X.(*ast.Ident)
+		if !ok {
+			return "", false
+		}
+		s, ok := consts[pkg.Name+"."+e.Sel.Name]
+		return s, ok
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		l, ok1 := stringValue(e.X, consts)
+		r, ok2 := stringValue(e.Y, consts)
+		return l + r, ok1 && ok2
+	case *ast.ParenExpr:
+		return stringValue(e.X, consts)
+	}
+	return "", false
+}
+
+func boolValue(expr ast.Expr) (bool, bool) {
+	id, ok := expr.(*ast.Ident)
+	if !ok {
+		return false, false
+	}
+	switch id.Name {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,164 @@
+package extract_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq/extract"
+)
+
+const source = `package payments
+
+import env "github.com/bbmumford/envreq"
+
+const keyName = "STRIPE_API_KEY"
+
+var key = env.Check(env.Requirement{
+	Name:        keyName,
+	Source:      "payments",
+	Description: "Stripe " + "secret key",
+	Sensitive:   true,
+	Default:     "sk_test_dev",
+	Validate:    env.NotEmpty,
+})
+
+var timeout = env.Check(env.Requirement{
+	Name:     "PAYMENTS_TIMEOUT",
+	Optional: true,
+	Default:  "30s",
+	Aliases:  []string{"STRIPE_TIMEOUT"},
+})
+
+var webhook = env.Check(env.Requirement{
+	Name:       "STRIPE_WEBHOOK_SECRET",
+	RequiredIf: env.Equals("APP_ENV", "production", "staging"),
+})
+
+func dynamic(name string) {
+	env.Check(env.Requirement{Name: name})
+}
+`
+
+func TestFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payments.go")
+	if err := os.WriteFile(path, []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	schema, issues, err := extract.Files(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Vars) != 3 {
+		t.Fatalf("Expected 3 vars, got %+v", schema.Vars)
+	}
+
+	key := schema.Vars[1]
+	if key.Name != "STRIPE_API_KEY" || !key.Required || !key.Sensitive || !key.Validated {
+		t.Errorf("Unexpected STRIPE_API_KEY: %+v", key)
+	}
+	if key.Validator != "envreq.NotEmpty" {
+		t.Errorf("Expected the validator named as the registry does, got %q", key.Validator)
+	}
+	if key.Description != "Stripe secret key" {
+		t.Errorf("Expected concatenated description, got %q", key.Description)
+	}
+	if key.Default != "" {
+		t.Error("Sensitive default must not be extracted")
+	}
+
+	timeout := schema.Vars[0]
+	if timeout.Name != "PAYMENTS_TIMEOUT" || timeout.Required || timeout.Default != "30s" ||
+		len(timeout.Aliases) != 1 || timeout.Aliases[0] != "STRIPE_TIMEOUT" {
+		t.Errorf("Unexpected PAYMENTS_TIMEOUT: %+v", timeout)
+	}
+
+	webhook := schema.Vars[2]
+	if webhook.Required || webhook.RequiredIf != "APP_ENV=production|staging" {
+		t.Errorf("Expected a conditional STRIPE_WEBHOOK_SECRET, got %+v", webhook)
+	}
+
+	if len(issues) != 1 {
+		t.Errorf("Expected 1 issue for the dynamic name, got %v", issues)
+	}
+}
+
+func TestCheckNames(t *testing.T) {
+	dir := t.TempDir()
+	src := `package app
+
+import (
+	env "github.com/bbmumford/envreq"
+	"example.com/app/envnames"
+)
+
+var db = env.Check(env.Requirement{Name: envnames.DatabaseURL, Aliases: []string{envnames.DBURL, "DB_URI"}})
+
+var typo = env.Check(env.Requirement{Name: "DATABSE_URL"})
+`
+	if err := os.WriteFile(filepath.Join(dir, "app.go"), []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := extract.CheckNames(dir, "example.com/app/envnames", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("Expected issues for the alias and the literal name, got %v", issues)
+	}
+	if !strings.Contains(issues[0].Message, "Aliases is not a constant from example.com/app/envnames") ||
+		!strings.Contains(issues[1].Message, "Name is not a constant") {
+		t.Errorf("Unexpected issues: %v", issues)
+	}
+}
+
+func TestDirImportedConstants(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.23\n",
+		"envnames/envnames.go": `package envnames
+
+const prefix = "APP_"
+
+const (
+	DatabaseURL = prefix + "DATABASE_URL"
+	DBURL       = "DB_URL"
+)
+`,
+		"app/app.go": `package app
+
+import (
+	env "github.com/bbmumford/envreq"
+	"example.com/app/envnames"
+)
+
+var db = env.Check(env.Requirement{Name: envnames.DatabaseURL, Aliases: []string{envnames.DBURL}})
+
+var missing = env.Check(env.Requirement{Name: envnames.Missing})
+`,
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	schema, issues, err := extract.Dir(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Vars) != 1 || schema.Vars[0].Name != "APP_DATABASE_URL" ||
+		len(schema.Vars[0].Aliases) != 1 || schema.Vars[0].Aliases[0] != "DB_URL" {
+		t.Errorf("Expected APP_DATABASE_URL named by an imported constant, got %+v", schema.Vars)
+	}
+	if len(issues) != 1 || !issues[0].Skipped {
+		t.Errorf("Expected the undefined constant reported as skipped, got %+v", issues)
+	}
+}
@@ -0,0 +1,89 @@
+package extract
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+)
+
+// CheckNames reports every Requirement literal below root whose Name,
+// ReplacedBy or Aliases element is not a constant of the package with
+// import path namesPkg, written as names.DatabaseURL. Used in CI next to
+// envreq.DeclareNames, it ensures variable names are compile-time checked
+// instead of string literals that can be mistyped ("DATABSE_URL").
+//
+// Test files are included only when tests is true.
+func CheckNames(root, namesPkg string, tests bool) ([]Issue, error) {
+	fset := token.NewFileSet()
+	var issues []Issue
+	err := walkPackages(root, tests, func(paths []string) error {
+		for _, p := range paths {
+			f, err := parser.ParseFile(fset, p, nil, parser.SkipObjectResolution)
+			if err != nil {
+				return err
+			}
+			local := importName(f)
+			if local == "" {
+				continue
+			}
+			names := importNameOf(f, namesPkg)
+			ast.Inspect(f, func(n ast.Node) bool {
+				lit, ok := n.(*ast.CompositeLit)
+				if ok && isRequirementType(lit.Type, local) {
+					issues = append(issues, checkLiteralNames(fset, lit, names, namesPkg)...)
+				}
+				return true
+			})
+		}
+		return nil
+	})
+	return issues, err
+}
+
+// checkLiteralNames checks the name fields of one Requirement literal.
+// names is the local name of the names package in the file.
+func checkLiteralNames(fset *token.FileSet, lit *ast.CompositeLit, names, namesPkg string) []Issue {
+	var issues []Issue
+	check := func(field string, e ast.Expr) {
+		if !isNamesConst(e, names) {
+			issues = append(issues, Issue{Pos: fset.Position(e.Pos()).String(), Message: field + " is not a constant from " + namesPkg})
+		}
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, _ := kv.Key.(*ast.Ident)
+		if key == nil {
+			continue
+		}
+		switch key.Name {
+		case "Name", "ReplacedBy":
+			check(key.Name, kv.Value)
+		case "Aliases":
+			list, ok := kv.Value.(*ast.CompositeLit)
+			if !ok {
+				check(key.Name, kv.Value)
+				continue
+			}
+			for _, e := range list.Elts {
+				check(key.Name, e)
+			}
+		}
+	}
+	return issues
+}
+
+// isNamesConst reports whether e refers to an identifier of the names
+// package, imported as names.
+func isNamesConst(e ast.Expr, names string) bool {
+	switch e := e.(type) {
+	case *ast.SelectorExpr:
+		pkg, ok := e.X.(*ast.Ident)
+		return ok && names != "" && pkg.Name == names
+	case *ast.Ident:
+		return names == "."
+	}
+	return false
+}
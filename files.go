@@ -0,0 +1,24 @@
+//go:build !(js && wasm) && !envreq_nofiles
+
+package envreq
+
+import (
+	"io"
+	"io/fs"
+	"os"
+)
+
+// openFile opens a file for reading.
+func openFile(path string) (io.ReadCloser, error) {
+	return os.Open(path)
+}
+
+// createFile creates or truncates a file for writing.
+func createFile(path string) (io.WriteCloser, error) {
+	return os.Create(path)
+}
+
+// statFile returns the file information of path, following symlinks.
+func statFile(path string) (fs.FileInfo, error) {
+	return os.Stat(path)
+}
@@ -0,0 +1,26 @@
+//go:build (js && wasm) || envreq_nofiles
+
+package envreq
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// On js/wasm (browsers have no filesystem) and when built with the
+// envreq_nofiles tag (e.g. for TinyGo-style targets), file-backed features
+// are compiled out and report errors.ErrUnsupported. Environment lookups,
+// validation, reports and the freeze lifecycle work unchanged.
+func openFile(path string) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("envreq: open %s: %w", path, errors.ErrUnsupported)
+}
+
+func createFile(path string) (io.WriteCloser, error) {
+	return nil, fmt.Errorf("envreq: create %s: %w", path, errors.ErrUnsupported)
+}
+
+func statFile(path string) (fs.FileInfo, error) {
+	return nil, fmt.Errorf("envreq: stat %s: %w", path, errors.ErrUnsupported)
+}
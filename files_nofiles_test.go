@@ -0,0 +1,24 @@
+//go:build envreq_nofiles
+
+package envreq_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestNoFilesBuild(t *testing.T) {
+	envreq.Reset()
+	defer envreq.Reset()
+
+	if err := envreq.LoadLocalOverrides(".env.local"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported without file support, got %v", err)
+	}
+
+	t.Setenv("NOFILES_VAR", "ok")
+	if res := envreq.Check(envreq.Requirement{Name: "NOFILES_VAR"}); res.Value != "ok" {
+		t.Errorf("Expected env lookups to work, got %q", res.Value)
+	}
+}
@@ -0,0 +1,117 @@
+package envreq
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MissingError explains why a variable has no usable value: which variable,
+// who owns it, what it is for, and how to set it. Its message is meant to
+// be self-explanatory without reading the code.
+type MissingError struct {
+	Requirement
+	Err error // ErrMissing, or the validation or source error
+}
+
+// newMissingError returns the MissingError for res. A missing optional
+// variable without a default has no error and yields nil.
+func newMissingError(res Result) error {
+	switch {
+	case res.Err != nil:
+		return &MissingError{Requirement: res.Requirement, Err: res.Err}
+	case !res.Present && !res.Optional:
+		return &MissingError{Requirement: res.Requirement, Err: ErrMissing}
+	}
+	return nil
+}
+
+func (e *MissingError) Error() string {
+	var b strings.Builder
+	if e.Err == ErrMissing {
+		fmt.Fprintf(&b, "envreq: required environment variable %s is not set", e.Name)
+	} else {
+		fmt.Fprintf(&b, "envreq: environment variable %s is invalid: %v", e.Name, e.Err)
+	}
+	if e.Source != "" {
+		fmt.Fprintf(&b, " (from %s)", e.Source)
+	}
+	for _, line := range [...]struct{ label, value string }{
+		{"description", e.Description},
+		{"example", e.Example},
+		{"docs", e.DocsURL},
+		{"owner", e.Owner},
+	} {
+		if line.value != "" {
+			fmt.Fprintf(&b, "\n  %-11s %s", line.label+":", line.value)
+		}
+	}
+	return b.String()
+}
+
+// Unwrap returns the underlying error, so errors.Is(err, ErrMissing) works.
+func (e *MissingError) Unwrap() error {
+	return e.Err
+}
+
+// Lazy is a requirement whose failure is deferred to its first use,
+// returned by FirstUse.
+type Lazy struct {
+	g    *Registry
+	name string
+}
+
+// FirstUse registers r like Check, but instead of relying on MustValidate
+// at startup it defers a missing or invalid value to the point of use:
+// Value panics and Lookup returns a *MissingError carrying the variable's
+// Description, Example and DocsURL. This suits variables only some code
+// paths need, e.g. a feature behind a flag.
+//
+// Validate and MustValidate do not fail for the variable; reports show it
+// with status "pending" instead. Registering the same name with Check as
+// well makes it an ordinary startup check again.
+func FirstUse(r Requirement) *Lazy {
+	r.firstUse = true
+	std.check(r, 1)
+	return &Lazy{g: std, name: r.Name}
+}
+
+// FirstUse registers r in the registry. See the package-level FirstUse.
+func (g *Registry) FirstUse(r Requirement) *Lazy {
+	r.firstUse = true
+	g.check(r, 1)
+	return &Lazy{g: g, name: r.Name}
+}
+
+// Result returns the current result of the variable, resolving it again if
+// the cached value was dropped (e.g. by LoadLocalOverrides).
+func (l *Lazy) Result() Result {
+	l.g.mu.RLock()
+	r := l.g.reg[l.name]
+	cached, ok := l.g.cache[l.name]
+	l.g.mu.RUnlock()
+
+	if !ok {
+		return l.g.check(r, 2)
+	}
+	return cached.result(r)
+}
+
+// Lookup returns the value, or a *MissingError if the variable is required
+// and not set or fails validation.
+func (l *Lazy) Lookup() (string, error) {
+	res := l.Result()
+	if err := newMissingError(res); err != nil {
+		return "", err
+	}
+	return res.Value, nil
+}
+
+// Value returns the value. It panics with a *MissingError if the variable
+// is required and not set or fails validation.
+func (l *Lazy) Value() string {
+	v, err := l.Lookup()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
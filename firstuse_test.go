@@ -0,0 +1,62 @@
+package envreq_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestFirstUse(t *testing.T) {
+	g := envreq.New()
+	t.Setenv("LAZY_PRESENT", "ok")
+
+	if v := g.FirstUse(envreq.Requirement{Name: "LAZY_PRESENT", Source: "test"}).Value(); v != "ok" {
+		t.Errorf("Expected 'ok', got %q", v)
+	}
+
+	lazy := g.FirstUse(envreq.Requirement{
+		Name:        "LAZY_WEBHOOK_SECRET",
+		Source:      "billing",
+		Description: "Signing secret for incoming webhooks",
+		Example:     "whsec_...",
+		DocsURL:     "https://wiki.example.com/billing#webhooks",
+		Sensitive:   true,
+	})
+
+	_, err := lazy.Lookup()
+	var missing *envreq.MissingError
+	if !errors.As(err, &missing) || !errors.Is(err, envreq.ErrMissing) {
+		t.Fatalf("Expected a *MissingError wrapping ErrMissing, got %v", err)
+	}
+	for _, want := range []string{"LAZY_WEBHOOK_SECRET", "billing", "Signing secret", "whsec_...", "https://wiki.example.com/billing#webhooks"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error message lacks %q:\n%s", want, err)
+		}
+	}
+
+	// Startup validation leaves it to the code path that needs it
+	results, err := g.ValidateResults()
+	if err != nil {
+		t.Errorf("Expected no startup failure for a FirstUse variable, got %v", err)
+	}
+	var buf strings.Builder
+	envreq.Report(&buf, results)
+	if !strings.Contains(buf.String(), envreq.StatusPending) || !strings.Contains(buf.String(), "checked at first use") {
+		t.Errorf("Expected the variable reported as pending:\n%s", buf.String())
+	}
+
+	// An eager registration of the same name makes it a startup check
+	g.Check(envreq.Requirement{Name: "LAZY_WEBHOOK_SECRET"})
+	if err := g.Validate(); err == nil || !strings.Contains(err.Error(), "LAZY_WEBHOOK_SECRET") {
+		t.Errorf("Expected Check to make the variable required at startup, got %v", err)
+	}
+
+	defer func() {
+		if p := recover(); p == nil {
+			t.Error("Expected Value to panic for a missing required variable")
+		}
+	}()
+	lazy.Value()
+}
@@ -0,0 +1,111 @@
+package envreq
+
+import (
+	"sort"
+	"strings"
+)
+
+// FreezeSource locks the registrations of the given sources before the rest
+// of the application is frozen, e.g. for a critical subsystem whose
+// configuration must be settled early. New requirements from a frozen source
+// behave as after Freeze: required ones panic, optional ones warn.
+func FreezeSource(sources ...string) {
+	std.FreezeSource(sources...)
+}
+
+// FreezeSource locks the registrations of the given sources in the registry.
+// See the package-level FreezeSource.
+func (g *Registry) FreezeSource(sources ...string) {
+	g.mu.Lock()
+	for _, s := range sources {
+		g.frozenSources[s] = true
+	}
+	g.mu.Unlock()
+
+	g.logf("envreq: Registrations frozen for source(s): %s", strings.Join(sources, ", "))
+}
+
+// FrozenSources returns the sources locked by FreezeSource.
+func FrozenSources() []string {
+	return std.FrozenSources()
+}
+
+// FrozenSources returns the sources locked by FreezeSource in the registry.
+func (g *Registry) FrozenSources() []string {
+	g.mu.RLock()
+	out := make([]string, 0, len(g.frozenSources))
+	for s := range g.frozenSources {
+		out = append(out, s)
+	}
+	g.mu.RUnlock()
+
+	sort.Strings(out)
+	return out
+}
+
+// frozenFor reports whether new registrations from source are frozen, and
+// whether the source was excluded from the global freeze (late optional
+// registrations from excluded sources are expected and not warned about).
+func (g *Registry) frozenFor(source string) (isFrozen, exempt bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if g.frozen.Load() {
+		return true, g.freezeExempt[source]
+	}
+	return g.frozenSources[source], false
+}
+
+// BeginLateRegistration opens a window during which source may register
+// requirements after Freeze, including required ones, e.g. while a
+// dynamically loaded plugin initializes. Such requirements are validated
+// immediately, logged when missing or invalid, and flagged in reports.
+// Close the window with EndLateRegistration.
+func BeginLateRegistration(source string) {
+	std.BeginLateRegistration(source)
+}
+
+// BeginLateRegistration opens a late registration window in the registry.
+// See the package-level BeginLateRegistration.
+func (g *Registry) BeginLateRegistration(source string) {
+	g.mu.Lock()
+	g.lateWindows = append(g.lateWindows, source)
+	g.mu.Unlock()
+}
+
+// EndLateRegistration closes the most recently opened late registration
+// window. It is a no-op when no window is open.
+func EndLateRegistration() {
+	std.EndLateRegistration()
+}
+
+// EndLateRegistration closes the most recently opened late registration
+// window in the registry.
+func (g *Registry) EndLateRegistration() {
+	g.mu.Lock()
+	if n := len(g.lateWindows); n > 0 {
+		g.lateWindows = g.lateWindows[:n-1]
+	}
+	g.mu.Unlock()
+}
+
+// inLateWindow reports whether source currently has an open window.
+func (g *Registry) inLateWindow(source string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	for _, s := range g.lateWindows {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// isLate reports whether name was registered inside a late window.
+func (g *Registry) isLate(name string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.lateNames[name]
+}
@@ -0,0 +1,85 @@
+package envreq_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestFreezeSource(t *testing.T) {
+	envreq.Reset()
+	defer envreq.Reset()
+
+	envreq.FreezeSource("payments")
+
+	// Other sources are unaffected
+	envreq.Check(envreq.Requirement{Name: "OTHER_REQUIRED", Source: "search"})
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for required var from a frozen source")
+		}
+	}()
+	envreq.Check(envreq.Requirement{Name: "PAYMENTS_LATE", Source: "payments"})
+}
+
+func TestFreezeExcluding(t *testing.T) {
+	envreq.Reset()
+	defer envreq.Reset()
+
+	envreq.Freeze("plugins")
+
+	envreq.Check(envreq.Requirement{Name: "PLUGIN_OPT", Source: "plugins", Optional: true})
+	if got := envreq.Stats().LateOptional; got != 0 {
+		t.Errorf("Expected excluded source not to count as late, got %d", got)
+	}
+
+	envreq.Check(envreq.Requirement{Name: "APP_OPT", Source: "app", Optional: true})
+	if got := envreq.Stats().LateOptional; got != 1 {
+		t.Errorf("Expected 1 late optional registration, got %d", got)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for required var even from an excluded source")
+		}
+	}()
+	envreq.Check(envreq.Requirement{Name: "PLUGIN_REQ", Source: "plugins"})
+}
+
+func TestLateRegistrationWindow(t *testing.T) {
+	envreq.Reset()
+	defer envreq.Reset()
+	t.Setenv("PLUGIN_TOKEN", "abc")
+
+	envreq.Freeze()
+
+	envreq.BeginLateRegistration("plugin-x")
+	res := envreq.Check(envreq.Requirement{Name: "PLUGIN_TOKEN", Source: "plugin-x"})
+	missing := envreq.Check(envreq.Requirement{Name: "PLUGIN_MISSING", Source: "plugin-x"})
+	envreq.EndLateRegistration()
+
+	if !res.Late || !res.Present {
+		t.Errorf("Expected late, present result, got %+v", res)
+	}
+	if missing.Present {
+		t.Error("Expected PLUGIN_MISSING to be validated immediately as missing")
+	}
+
+	var buf bytes.Buffer
+	if n := envreq.Report(&buf, envreq.CheckAll()); n != 1 {
+		t.Errorf("Expected 1 missing, got %d", n)
+	}
+	if !strings.Contains(buf.String(), "late registration") {
+		t.Error("Expected report to flag late registrations")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic once the window is closed")
+		}
+	}()
+	envreq.Check(envreq.Requirement{Name: "PLUGIN_AFTER", Source: "plugin-x"})
+}
@@ -0,0 +1,305 @@
+// Package gcpsource resolves envreq requirements from Google Cloud Secret
+// Manager, for services such as Cloud Run that cannot pre-populate the
+// environment.
+//
+// Requirements name a secret version with a SecretRef:
+//
+//	src := gcpsource.New(gcpsource.Config{})
+//	envreq.SetSources(envreq.Env, src)
+//
+//	key := envreq.Check(envreq.Requirement{
+//	    Name:      "STRIPE_API_KEY",
+//	    Source:    "payments",
+//	    SecretRef: "gcp:projects/acme/secrets/stripe-key/versions/latest",
+//	    Sensitive: true,
+//	})
+//
+// A reference without "/versions/..." reads the latest version. Each call
+// has its own timeout and transient failures (network errors, 429 and 5xx
+// responses) are retried with backoff. Values are cached for the life of
+// the source.
+//
+// Access tokens come from Config.Token or, by default, from the metadata
+// server of the Cloud Run service, GCE instance or GKE workload; the package
+// does not depend on the Google Cloud client libraries.
+package gcpsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scheme is the SecretRef scheme resolved by Source.
+const Scheme = "gcp"
+
+// Defaults for Config.
+const (
+	DefaultEndpoint    = "https://secretmanager.googleapis.com"
+	DefaultMetadataURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	DefaultTimeout     = 5 * time.Second
+	DefaultRetries     = 3
+)
+
+// Config configures a Source.
+type Config struct {
+	// Project, if set, serves requirements without a SecretRef from the
+	// latest version of the secret named like the variable.
+	Project string
+	// Token is a static OAuth access token. When empty, tokens are
+	// requested from MetadataURL and refreshed before they expire.
+	Token       string
+	Endpoint    string        // default DefaultEndpoint
+	MetadataURL string        // default DefaultMetadataURL
+	Timeout     time.Duration // per call, including token requests; default DefaultTimeout
+	Retries     int           // retries of transient failures; default DefaultRetries, negative for none
+	Backoff     time.Duration // first retry delay, doubled per retry; default 200ms
+	Client      *http.Client  // default http.DefaultClient
+}
+
+// Source resolves envreq requirements from Secret Manager. It implements
+// envreq.RefSource.
+type Source struct {
+	cfg Config
+
+	mu      sync.Mutex
+	secrets map[string]*string // resource name -> value; nil when not found
+	hits    uint64             // lookups served from secrets
+	misses  uint64             // lookups that called Secret Manager
+
+	tokenMu     sync.Mutex
+	token       string
+	tokenExpiry time.Time
+	now         func() time.Time
+}
+
+// New returns a Source for cfg.
+func New(cfg Config) *Source {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = DefaultEndpoint
+	}
+	if cfg.MetadataURL == "" {
+		cfg.MetadataURL = DefaultMetadataURL
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = DefaultRetries
+	} else if cfg.Retries < 0 {
+		cfg.Retries = 0
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 200 * time.Millisecond
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	return &Source{cfg: cfg, secrets: map[string]*string{}, now: time.Now}
+}
+
+// Name returns "gcp", recorded as the provenance of values.
+func (s *Source) Name() string {
+	return Scheme
+}
+
+// Scheme returns "gcp".
+func (s *Source) Scheme() string {
+	return Scheme
+}
+
+// Lookup reads the secret name of Config.Project, if configured.
+func (s *Source) Lookup(name string) (string, bool, error) {
+	if s.cfg.Project == "" {
+		return "", false, nil
+	}
+	return s.LookupRef("projects/" + s.cfg.Project + "/secrets/" + name)
+}
+
+// LookupRef returns the payload of the secret version ref, e.g.
+// "projects/acme/secrets/stripe-key/versions/3".
+func (s *Source) LookupRef(ref string) (string, bool, error) {
+	parts := strings.Split(strings.Trim(ref, "/"), "/")
+	switch {
+	case len(parts) == 4 && parts[0] == "projects" && parts[2] == "secrets":
+		ref = strings.Join(parts, "/") + "/versions/latest"
+	case len(parts) == 6 && parts[0] == "projects" && parts[2] == "secrets" && parts[4] == "versions":
+		ref = strings.Join(parts, "/")
+	default:
+		return "", false, fmt.Errorf("gcpsource: reference %q is not projects/<project>/secrets/<secret>[/versions/<version>]", ref)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if v, ok := s.secrets[ref]; ok {
+		s.hits++
+		return deref(v)
+	}
+	s.misses++
+	v, err := s.access(ref)
+	if err != nil {
+		return "", false, err
+	}
+	s.secrets[ref] = v
+	return deref(v)
+}
+
+// CacheStats returns how many lookups were served from the cache and how
+// many called Secret Manager, for envreq.Providers.
+func (s *Source) CacheStats() (hits, misses uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits, s.misses
+}
+
+func deref(v *string) (string, bool, error) {
+	if v == nil {
+		return "", false, nil
+	}
+	return *v, true, nil
+}
+
+// access calls versions.access for name, retrying transient failures.
+func (s *Source) access(name string) (*string, error) {
+	var out struct {
+		Payload struct {
+			Data       []byte `json:"data"`
+			DataCrc32c string `json:"dataCrc32c"`
+		} `json:"payload"`
+	}
+
+	delay := s.cfg.Backoff
+	for attempt := 0; ; attempt++ {
+		status, err := s.get(s.cfg.Endpoint+"/v1/"+name+":access", &out)
+		if status == http.StatusNotFound {
+			return nil, nil
+		}
+		if err == nil {
+			break
+		}
+		if attempt >= s.cfg.Retries || !retryable(status) {
+			return nil, err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+
+	if out.Payload.DataCrc32c != "" {
+		want, err := strconv.ParseUint(out.Payload.DataCrc32c, 10, 32)
+		if err == nil && crc32.Checksum(out.Payload.Data, crc32.MakeTable(crc32.Castagnoli)) != uint32(want) {
+			return nil, fmt.Errorf("gcpsource: %s: payload checksum mismatch", name)
+		}
+	}
+	v := string(out.Payload.Data)
+	return &v, nil
+}
+
+// retryable reports whether a call that failed with status (0 for no
+// response) may succeed when repeated.
+func retryable(status int) bool {
+	return status == 0 || status == http.StatusTooManyRequests || status >= 500
+}
+
+// get performs one authorized GET with the per-call timeout and decodes
+// the JSON response into v. It returns the response status, or 0 when no
+// response was received.
+func (s *Source) get(url string, v any) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+	defer cancel()
+
+	token, err := s.accessToken(ctx)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return s.do(req, v)
+}
+
+// do sends req and decodes a successful JSON response into v.
+func (s *Source) do(req *http.Request, v any) (int, error) {
+	resp, err := s.cfg.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("gcpsource: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, fmt.Errorf("gcpsource: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+				Status  string `json:"status"`
+			} `json:"error"`
+		}
+		json.Unmarshal(body, &e)
+		err := fmt.Errorf("gcpsource: GET %s: %s", req.URL.Path, resp.Status)
+		if e.Error.Message != "" {
+			err = fmt.Errorf("gcpsource: %s: %s", e.Error.Status, e.Error.Message)
+		}
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			err = authError{err}
+		}
+		return resp.StatusCode, err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return resp.StatusCode, fmt.Errorf("gcpsource: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+// authError is a rejected credential. It matches fs.ErrPermission, which
+// envreq.Providers reports as an authentication failure.
+type authError struct{ error }
+
+func (e authError) Is(target error) bool { return target == fs.ErrPermission }
+
+func (e authError) Unwrap() error { return e.error }
+
+// accessToken returns Config.Token or a cached metadata server token.
+func (s *Source) accessToken(ctx context.Context) (string, error) {
+	if s.cfg.Token != "" {
+		return s.cfg.Token, nil
+	}
+
+	s.tokenMu.Lock()
+	defer s.tokenMu.Unlock()
+
+	// Refresh a minute early so a token does not expire mid-call
+	if s.token != "" && s.now().Add(time.Minute).Before(s.tokenExpiry) {
+		return s.token, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.cfg.MetadataURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if _, err := s.do(req, &tok); err != nil {
+		return "", fmt.Errorf("gcpsource: no access token (set Config.Token outside Google Cloud): %s",
+			strings.TrimPrefix(err.Error(), "gcpsource: "))
+	}
+	s.token = tok.AccessToken
+	s.tokenExpiry = s.now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return s.token, nil
+}
@@ -0,0 +1,148 @@
+package gcpsource
+
+import (
+	"encoding/base64"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bbmumford/envreq"
+)
+
+// fakeGCP serves the metadata token endpoint and versions.access, failing
+// the first fail calls with 503.
+type fakeGCP struct {
+	secrets map[string]string // resource name -> payload
+	fail    atomic.Int32
+	calls   atomic.Int32
+	tokens  atomic.Int32
+	delay   time.Duration
+}
+
+func (f *fakeGCP) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/token" {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		f.tokens.Add(1)
+		fmt.Fprint(w, `{"access_token":"ya29.test","expires_in":3600,"token_type":"Bearer"}`)
+		return
+	}
+
+	f.calls.Add(1)
+	time.Sleep(f.delay)
+	if f.fail.Add(-1) >= 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer ya29.test" {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"code":401,"message":"Request had invalid authentication credentials.","status":"UNAUTHENTICATED"}}`)
+		return
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/"), ":access")
+	v, ok := f.secrets[name]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"code":404,"message":"Secret not found","status":"NOT_FOUND"}}`)
+		return
+	}
+	sum := crc32.Checksum([]byte(v), crc32.MakeTable(crc32.Castagnoli))
+	fmt.Fprintf(w, `{"name":%q,"payload":{"data":%q,"dataCrc32c":"%d"}}`,
+		name, base64.StdEncoding.EncodeToString([]byte(v)), sum)
+}
+
+func newTest(t *testing.T, f *fakeGCP, cfg Config) *Source {
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	cfg.Endpoint = srv.URL
+	cfg.MetadataURL = srv.URL + "/token"
+	cfg.Backoff = time.Millisecond
+	return New(cfg)
+}
+
+func TestSecretRef(t *testing.T) {
+	f := &fakeGCP{secrets: map[string]string{
+		"projects/acme/secrets/stripe-key/versions/latest": "sk_test_123",
+		"projects/acme/secrets/APP_NAME/versions/latest":   "payments",
+	}}
+	src := newTest(t, f, Config{Project: "acme"})
+
+	g := envreq.New()
+	g.SetSources(envreq.Env, src)
+	res := g.Check(envreq.Requirement{
+		Name:      "GCP_TEST_STRIPE_KEY",
+		Source:    "payments",
+		SecretRef: "gcp:projects/acme/secrets/stripe-key/versions/latest",
+		Sensitive: true,
+	})
+	if res.Value != "sk_test_123" || res.Provenance != "gcp" {
+		t.Fatalf("Expected the secret from gcp, got %q from %q (err %v)", res.Value, res.Provenance, res.Err)
+	}
+
+	// Without a version the latest is read, from the cache
+	if v, ok, err := src.LookupRef("projects/acme/secrets/stripe-key"); err != nil || !ok || v != "sk_test_123" {
+		t.Errorf("Expected the latest version, got %q, %v, %v", v, ok, err)
+	}
+	if v, _, _ := src.Lookup("APP_NAME"); v != "payments" {
+		t.Errorf("Expected Lookup in Config.Project, got %q", v)
+	}
+	if _, ok, err := src.LookupRef("projects/acme/secrets/nope"); ok || err != nil {
+		t.Errorf("Expected a missing secret to be not found, got %v, %v", ok, err)
+	}
+	if _, _, err := src.LookupRef("stripe-key"); err == nil {
+		t.Error("Expected an error for a malformed reference")
+	}
+	if n, tok := f.calls.Load(), f.tokens.Load(); n != 3 || tok != 1 {
+		t.Errorf("Expected 3 calls with one metadata token, got %d calls and %d tokens", n, tok)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	f := &fakeGCP{secrets: map[string]string{"projects/acme/secrets/key/versions/1": "v1"}}
+	f.fail.Store(2)
+	src := newTest(t, f, Config{Token: "ya29.test"})
+	if v, _, err := src.LookupRef("projects/acme/secrets/key/versions/1"); err != nil || v != "v1" {
+		t.Fatalf("Expected success after retries, got %q, %v", v, err)
+	}
+	if n := f.calls.Load(); n != 3 {
+		t.Errorf("Expected 3 attempts, got %d", n)
+	}
+
+	f = &fakeGCP{secrets: map[string]string{}}
+	f.fail.Store(10)
+	src = newTest(t, f, Config{Token: "ya29.test", Retries: -1})
+	if _, _, err := src.LookupRef("projects/acme/secrets/key"); err == nil {
+		t.Error("Expected an error without retries")
+	}
+	if n := f.calls.Load(); n != 1 {
+		t.Errorf("Expected a single attempt, got %d", n)
+	}
+
+	// Client errors are not retried
+	f = &fakeGCP{secrets: map[string]string{}}
+	src = newTest(t, f, Config{Token: "wrong"})
+	if _, _, err := src.LookupRef("projects/acme/secrets/key"); err == nil || !strings.Contains(err.Error(), "UNAUTHENTICATED") {
+		t.Errorf("Expected an authentication error, got %v", err)
+	}
+	if n := f.calls.Load(); n != 1 {
+		t.Errorf("Expected no retries of a 401, got %d attempts", n)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	f := &fakeGCP{secrets: map[string]string{"projects/acme/secrets/key/versions/latest": "v"}, delay: 50 * time.Millisecond}
+	src := newTest(t, f, Config{Token: "ya29.test", Timeout: 10 * time.Millisecond, Retries: 1})
+	if _, _, err := src.LookupRef("projects/acme/secrets/key"); err == nil {
+		t.Error("Expected the per-call timeout to fail the lookup")
+	}
+	if n := f.calls.Load(); n != 2 {
+		t.Errorf("Expected a timed out call to be retried once, got %d attempts", n)
+	}
+}
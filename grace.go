@@ -0,0 +1,94 @@
+package envreq
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cutoff is the point from which a newly required variable is enforced,
+// set as Requirement.RequiredAfter. Build one with AfterDate or
+// FromVersion.
+type Cutoff struct {
+	desc   string
+	passed func(g *Registry) bool
+}
+
+// AfterDate returns a Cutoff that passes at t, so that a fleet can be
+// given until a date to set a new variable:
+//
+//	RequiredAfter: envreq.AfterDate(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+func AfterDate(t time.Time) Cutoff {
+	layout := time.RFC3339
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		layout = time.DateOnly
+	}
+	return Cutoff{
+		desc: t.Format(layout),
+		passed: func(*Registry) bool {
+			return !time.Now().Before(t)
+		},
+	}
+}
+
+// FromVersion returns a Cutoff that passes in builds of version or later,
+// compared with the Version set with SetBuildInfo (or read from the
+// module), numerically by dot-separated component: "1.10.0" is after
+// "1.9.2", and a leading "v" and any "-pre" or "+build" suffix are
+// ignored. Builds without a version are treated as before the cutoff.
+func FromVersion(version string) Cutoff {
+	return Cutoff{
+		desc: "version " + version,
+		passed: func(g *Registry) bool {
+			current := g.BuildInfo().Version
+			return current != "" && compareVersions(current, version) >= 0
+		},
+	}
+}
+
+// String describes the cutoff, e.g. "2025-03-01" or "version 2.0.0".
+func (c Cutoff) String() string {
+	return c.desc
+}
+
+// pending reports whether c is set and has not passed yet in g.
+func (c Cutoff) pending(g *Registry) bool {
+	return c.passed != nil && !c.passed(g)
+}
+
+// compareVersions compares the versions a and b like strings.Compare, see
+// FromVersion.
+func compareVersions(a, b string) int {
+	as, bs := versionParts(a), versionParts(b)
+	for i := range max(len(as), len(bs)) {
+		var x, y int
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionParts returns the numeric components of version. Components that
+// are not numbers count as 0.
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	var parts []int
+	for _, s := range strings.Split(version, ".") {
+		n, _ := strconv.Atoi(s)
+		parts = append(parts, n)
+	}
+	return parts
+}
@@ -0,0 +1,80 @@
+package envreq_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestRequiredAfterDate(t *testing.T) {
+	tomorrow := time.Now().Add(24 * time.Hour)
+	g := envreq.New()
+	res := g.Check(envreq.Requirement{Name: "GRC_TRACING_URL", Source: "otel", RequiredAfter: envreq.AfterDate(tomorrow)})
+	if !res.Grace || !res.Optional {
+		t.Errorf("Expected the variable to be in its grace period, got %+v", res)
+	}
+	if err := g.Validate(); err != nil {
+		t.Errorf("Expected the grace period not to fail validation, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	g.Report(&buf)
+	if !strings.Contains(buf.String(), envreq.StatusGrace) || !strings.Contains(buf.String(), "[required after ") || !strings.Contains(buf.String(), "will become required") {
+		t.Errorf("Expected the grace period in the report:\n%s", buf.String())
+	}
+	buf.Reset()
+	g.ReportJSON(&buf)
+	var rep envreq.JSONReport
+	if err := json.Unmarshal(buf.Bytes(), &rep); err != nil {
+		t.Fatal(err)
+	}
+	if e := rep.Vars[0]; e.Status != envreq.StatusGrace || e.Remediation == nil || !strings.Contains(e.Remediation.Action, "required after") {
+		t.Errorf("Expected a grace entry with a remediation, got %+v", e)
+	}
+
+	g = envreq.New()
+	g.Check(envreq.Requirement{Name: "GRC_TRACING_URL", Source: "otel", RequiredAfter: envreq.AfterDate(time.Now().Add(-time.Hour))})
+	var verr *envreq.ValidationError
+	if err := g.Validate(); !errors.As(err, &verr) || len(verr.Problems) != 1 || !verr.Problems[0].Missing {
+		t.Errorf("Expected the variable to be required after the cutoff, got %v", err)
+	}
+}
+
+func TestRequiredAfterVersion(t *testing.T) {
+	for _, tt := range []struct {
+		version string
+		grace   bool
+	}{
+		{"", true},
+		{"1.9.2", true},
+		{"v2.0.0-rc1", false},
+		{"1.10.0", false},
+		{"2.0", false},
+	} {
+		g := envreq.New()
+		g.SetBuildInfo(envreq.BuildInfo{Service: "api", Version: tt.version})
+		res := g.Check(envreq.Requirement{Name: "GRC_REGION", Source: "cloud", RequiredAfter: envreq.FromVersion("1.10")})
+		if res.Grace != tt.grace {
+			t.Errorf("version %q: expected grace %t, got %t", tt.version, tt.grace, res.Grace)
+		}
+	}
+}
+
+func TestRequiredAfterMerge(t *testing.T) {
+	g := envreq.New()
+	cutoff := envreq.AfterDate(time.Now().Add(24 * time.Hour))
+	g.Check(envreq.Requirement{Name: "GRC_MERGED", Source: "a", RequiredAfter: cutoff})
+	g.Check(envreq.Requirement{Name: "GRC_MERGED", Source: "b", Optional: true})
+	if err := g.Validate(); err != nil {
+		t.Errorf("Expected an optional registration to keep the grace period, got %v", err)
+	}
+	g.Check(envreq.Requirement{Name: "GRC_MERGED", Source: "c"})
+	if _, err := g.Revalidate(); err == nil {
+		t.Error("Expected a required registration without a cutoff to end the grace period")
+	}
+}
@@ -0,0 +1,211 @@
+package envreq
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+)
+
+// DefaultTokenEnv is the environment variable RequireToken reads when given
+// no name.
+const DefaultTokenEnv = "ENVREQ_HANDLER_TOKEN"
+
+// privateNetworks are the loopback and private ranges allowed by default.
+var privateNetworks = []netip.Prefix{
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("fc00::/7"),
+}
+
+// HandlerOption configures the access control of Handler.
+type HandlerOption func(*handlerConfig)
+
+type handlerConfig struct {
+	networks   []netip.Prefix
+	custom     bool // networks set with AllowNetworks
+	tokenEnv   string
+	middleware []func(http.Handler) http.Handler
+}
+
+// AllowNetworks restricts access to clients whose address is in one of the
+// given CIDR prefixes, replacing the default loopback/private ranges. It
+// panics on an invalid prefix, since that is a programming error. Pass
+// "0.0.0.0/0" and "::/0" to allow every client.
+func AllowNetworks(cidrs ...string) HandlerOption {
+	prefixes := make([]netip.Prefix, len(cidrs))
+	for i, c := range cidrs {
+		p, err := netip.ParsePrefix(c)
+		if err != nil {
+			panic(fmt.Sprintf("envreq: AllowNetworks: %v", err))
+		}
+		prefixes[i] = p.Masked()
+	}
+	return func(c *handlerConfig) {
+		c.networks, c.custom = prefixes, true
+	}
+}
+
+// RequireToken requires requests to carry "Authorization: Bearer <token>",
+// where the token is read from the named environment variable
+// (DefaultTokenEnv if name is empty) when the handler is built. If the
+// variable is unset every request is refused.
+func RequireToken(name string) HandlerOption {
+	if name == "" {
+		name = DefaultTokenEnv
+	}
+	return func(c *handlerConfig) {
+		c.tokenEnv = name
+	}
+}
+
+// WithAuth wraps the handler in an authentication middleware, e.g. the
+// application's SSO or mTLS check. Middleware runs after the network and
+// token checks; several WithAuth options apply outermost first.
+func WithAuth(mw func(http.Handler) http.Handler) HandlerOption {
+	return func(c *handlerConfig) {
+		c.middleware = append(c.middleware, mw)
+	}
+}
+
+// Handler returns an http.Handler serving the redacted report of all
+// registered variables, for mounting on an internal admin mux. It renders
+// the Report table, or the ReportJSON document when the request accepts
+// application/json, both with the providers section (see Providers).
+// Values are never shown, whatever ENVREQ_SHOW_VALUES says.
+//
+// Even a redacted inventory reveals architecture, so the handler is guarded:
+// by default only loopback and private network clients are allowed (see
+// AllowNetworks), and RequireToken and WithAuth add further checks.
+//
+// Behind a reverse proxy, load balancer or ingress, the client address is
+// the proxy's, usually private, so the default would admit every client
+// of the proxy. Requests carrying X-Forwarded-For or Forwarded are
+// therefore refused unless RequireToken, WithAuth or AllowNetworks is
+// given. Do not expose the handler through a public proxy without a token
+// or authentication.
+func Handler(opts ...HandlerOption) http.Handler {
+	return std.Handler(opts...)
+}
+
+// Handler returns an http.Handler serving the registry's redacted report.
+// See the package-level Handler.
+func (g *Registry) Handler(opts ...HandlerOption) http.Handler {
+	return guard(readOnly(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		if acceptsJSON(r) {
+			w.Header().Set("Content-Type", "application/json")
+			g.ReportJSON(w)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		results := g.CheckAll()
+		constraints := g.constraintErrors(results)
+		reportBuild(w, g.BuildInfo())
+		reportPaged(w, results, 0, nil, false, incompleteGroups(constraints))
+		reportConstraints(w, constraints)
+		reportProviders(w, g.Providers())
+	}), opts)
+}
+
+// acceptsJSON reports whether the request prefers JSON over text.
+func acceptsJSON(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, _ := strings.Cut(part, ";")
+		switch strings.TrimSpace(mt) {
+		case "application/json":
+			return true
+		case "text/plain", "text/*":
+			return false
+		}
+	}
+	return false
+}
+
+// readOnly serves h for GET and HEAD only, and disables caching of the
+// response.
+func readOnly(h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		h(w, r)
+	})
+}
+
+// guard wraps h in the access controls configured by opts.
+func guard(h http.Handler, opts []HandlerOption) http.Handler {
+	cfg := handlerConfig{networks: privateNetworks}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	for i := len(cfg.middleware) - 1; i >= 0; i-- {
+		h = cfg.middleware[i](h)
+	}
+	if cfg.tokenEnv != "" {
+		h = requireToken(h, os.Getenv(cfg.tokenEnv))
+	}
+	if !cfg.custom && cfg.tokenEnv == "" && len(cfg.middleware) == 0 {
+		h = refuseProxied(h)
+	}
+	return allowNetworks(h, cfg.networks)
+}
+
+// refuseProxied refuses requests forwarded by a proxy, whose client
+// address says nothing about the client.
+func refuseProxied(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Forwarded-For") != "" || r.Header.Get("Forwarded") != "" {
+			http.Error(w, "forbidden: proxied request; configure RequireToken or WithAuth", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowNetworks refuses clients outside networks.
+func allowNetworks(next http.Handler, networks []netip.Prefix) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		addr, err := netip.ParseAddr(host)
+		if err != nil {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		addr = addr.Unmap()
+		for _, p := range networks {
+			if p.Contains(addr) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		http.Error(w, "forbidden", http.StatusForbidden)
+	})
+}
+
+// requireToken refuses requests without the bearer token. An empty token
+// refuses everything rather than accepting an empty header.
+func requireToken(next http.Handler, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="envreq"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
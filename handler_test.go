@@ -0,0 +1,119 @@
+package envreq_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestHandlerAccessControl(t *testing.T) {
+	g := envreq.New()
+	t.Setenv("HANDLER_SECRET", "sk_live_abcdef")
+	t.Setenv("HANDLER_TOKEN", "letmein")
+	t.Setenv("ENVREQ_SHOW_VALUES", "1")
+	g.Check(envreq.Requirement{Name: "HANDLER_SECRET", Source: "test", Sensitive: true})
+
+	get := func(h http.Handler, remote, auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/debug/env", nil)
+		req.RemoteAddr = remote
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	h := g.Handler()
+	if rec := get(h, "203.0.113.7:5000", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("Public client: got %d, want 403", rec.Code)
+	}
+	rec := get(h, "127.0.0.1:5000", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Loopback client: got %d, want 200", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "HANDLER_SECRET") || strings.Contains(rec.Body.String(), "abcdef") {
+		t.Errorf("Expected a redacted report, got:\n%s", rec.Body.String())
+	}
+
+	// Behind a proxy the private address is the proxy's, not the client's
+	for _, header := range []string{"X-Forwarded-For", "Forwarded"} {
+		req := httptest.NewRequest(http.MethodGet, "/debug/env", nil)
+		req.RemoteAddr = "10.0.0.5:5000"
+		req.Header.Set(header, "203.0.113.7")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("Proxied request with %s and no token: got %d, want 403", header, rec.Code)
+		}
+		rec = httptest.NewRecorder()
+		req.Header.Set("Authorization", "Bearer letmein")
+		g.Handler(envreq.RequireToken("HANDLER_TOKEN")).ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("Proxied request with %s and a token: got %d, want 200", header, rec.Code)
+		}
+	}
+
+	h = g.Handler(envreq.AllowNetworks("203.0.113.0/24"), envreq.RequireToken("HANDLER_TOKEN"))
+	if rec := get(h, "203.0.113.7:5000", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Missing token: got %d, want 401", rec.Code)
+	}
+	if rec := get(h, "203.0.113.7:5000", "Bearer nope"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Wrong token: got %d, want 401", rec.Code)
+	}
+	if rec := get(h, "203.0.113.7:5000", "Bearer letmein"); rec.Code != http.StatusOK {
+		t.Errorf("Valid token: got %d, want 200", rec.Code)
+	}
+	if rec := get(h, "127.0.0.1:5000", "Bearer letmein"); rec.Code != http.StatusForbidden {
+		t.Errorf("Network outside allow list: got %d, want 403", rec.Code)
+	}
+
+	h = g.Handler(envreq.RequireToken("HANDLER_TOKEN_UNSET"))
+	if rec := get(h, "127.0.0.1:5000", "Bearer "); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Unset token variable must refuse all: got %d", rec.Code)
+	}
+
+	h = g.Handler(envreq.WithAuth(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-User") == "" {
+				http.Error(w, "login required", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}))
+	if rec := get(h, "10.1.2.3:5000", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Auth middleware: got %d, want 401", rec.Code)
+	}
+}
+
+func TestHandlerJSON(t *testing.T) {
+	g := envreq.New()
+	t.Setenv("HANDLER_JSON_KEY", "sk_live_abcdef")
+	g.Check(envreq.Requirement{Name: "HANDLER_JSON_KEY", Source: "test", Sensitive: true})
+	g.Check(envreq.Requirement{Name: "HANDLER_JSON_MISSING", Source: "test"})
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/env", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	req.Header.Set("Accept", "application/json, text/plain;q=0.5")
+	rec := httptest.NewRecorder()
+	g.Handler().ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected JSON, got %q", ct)
+	}
+	var rep envreq.JSONReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &rep); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, rec.Body.String())
+	}
+	if rep.Missing != 1 || len(rep.Vars) != 2 {
+		t.Errorf("Unexpected report: %+v", rep)
+	}
+	if strings.Contains(rec.Body.String(), "abcdef") {
+		t.Errorf("JSON report leaks a value: %s", rec.Body.String())
+	}
+}
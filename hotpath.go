@@ -0,0 +1,119 @@
+package envreq
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HotPath describes a variable whose Check is called frequently after
+// Freeze, which usually means the Check lives in a request handler and
+// should be hoisted to initialization.
+type HotPath struct {
+	Name     string `json:"name"`               // variable name
+	Calls    uint64 `json:"calls"`              // total Check calls observed after Freeze
+	PeakRate int    `json:"peakRate"`           // highest calls observed within one second
+	Flagged  bool   `json:"flagged"`            // PeakRate exceeded the detection threshold
+	CallSite string `json:"callSite,omitempty"` // file:line of the first call that exceeded the threshold
+}
+
+type hotCounter struct {
+	windowStart time.Time
+	window      int
+	HotPath
+}
+
+// hotState is the hot path detector of a registry.
+type hotState struct {
+	threshold atomic.Int64
+	mu        sync.Mutex
+	counters  map[string]*hotCounter
+}
+
+// DetectHotPaths enables the hot path detector: after Freeze, any variable
+// whose Check is called more than perSecond times within one second is
+// flagged and its call site logged once. Pass 0 to disable.
+func DetectHotPaths(perSecond int) {
+	std.DetectHotPaths(perSecond)
+}
+
+// DetectHotPaths enables the hot path detector of the registry.
+func (g *Registry) DetectHotPaths(perSecond int) {
+	g.hot.threshold.Store(int64(perSecond))
+}
+
+// HotPaths returns per-variable Check counts recorded after Freeze, flagged
+// entries first.
+func HotPaths() []HotPath {
+	return std.HotPaths()
+}
+
+// HotPaths returns the per-variable Check counts of the registry.
+func (g *Registry) HotPaths() []HotPath {
+	h := &g.hot
+	h.mu.Lock()
+	out := make([]HotPath, 0, len(h.counters))
+	for _, c := range h.counters {
+		out = append(out, c.HotPath)
+	}
+	h.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Flagged != out[j].Flagged {
+			return out[i].Flagged
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// recordHotCall counts a post-Freeze Check of name. skip is the number of
+// stack frames between the caller of Check and this function.
+func (g *Registry) recordHotCall(name string, skip int) {
+	h := &g.hot
+	limit := int(h.threshold.Load())
+	if limit <= 0 {
+		return
+	}
+
+	now := time.Now()
+	h.mu.Lock()
+	c, ok := h.counters[name]
+	if !ok {
+		c = &hotCounter{HotPath: HotPath{Name: name}}
+		h.counters[name] = c
+	}
+	if now.Sub(c.windowStart) >= time.Second {
+		c.windowStart = now
+		c.window = 0
+	}
+	c.window++
+	c.Calls++
+	if c.window > c.PeakRate {
+		c.PeakRate = c.window
+	}
+	flagNow := !c.Flagged && c.window > limit
+	if flagNow {
+		c.Flagged = true
+		if _, file, line, ok := runtime.Caller(skip + 1); ok {
+			c.CallSite = fmt.Sprintf("%s:%d", file, line)
+		}
+	}
+	site := c.CallSite
+	h.mu.Unlock()
+
+	if flagNow {
+		g.logf("⚠️  envreq: Check(%s) called more than %d times/s after Freeze() at %s; hoist it to initialization", name, limit, site)
+	}
+}
+
+// reset clears detector state.
+func (h *hotState) reset() {
+	h.threshold.Store(0)
+	h.mu.Lock()
+	h.counters = map[string]*hotCounter{}
+	h.mu.Unlock()
+}
@@ -0,0 +1,40 @@
+package envreq_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestDetectHotPaths(t *testing.T) {
+	envreq.Reset()
+	defer envreq.Reset()
+
+	envreq.Check(envreq.Requirement{Name: "HOT_VAR", Source: "test", Optional: true})
+	envreq.Check(envreq.Requirement{Name: "COLD_VAR", Source: "test", Optional: true})
+	envreq.DetectHotPaths(5)
+	envreq.Freeze()
+
+	for i := 0; i < 10; i++ {
+		envreq.Check(envreq.Requirement{Name: "HOT_VAR", Source: "test", Optional: true})
+	}
+	envreq.Check(envreq.Requirement{Name: "COLD_VAR", Source: "test", Optional: true})
+
+	hot := envreq.HotPaths()
+	if len(hot) != 2 {
+		t.Fatalf("Expected 2 tracked vars, got %d", len(hot))
+	}
+	if hot[0].Name != "HOT_VAR" || !hot[0].Flagged {
+		t.Errorf("Expected HOT_VAR flagged first, got %+v", hot[0])
+	}
+	if hot[0].Calls != 10 {
+		t.Errorf("Expected 10 calls, got %d", hot[0].Calls)
+	}
+	if !strings.Contains(hot[0].CallSite, "hotpath_test.go") {
+		t.Errorf("Expected call site in test file, got %q", hot[0].CallSite)
+	}
+	if hot[1].Flagged {
+		t.Errorf("Did not expect COLD_VAR to be flagged")
+	}
+}
@@ -0,0 +1,81 @@
+package envreq
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LintIssue is a problem with a registered declaration found by Lint.
+type LintIssue struct {
+	Name   string // variable name
+	Source string // owning package/component
+	Err    error  // what is wrong
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s (%s): %v", i.Name, i.Source, i.Err)
+}
+
+// Lint inspects every registered requirement without reading the
+// environment and returns the declaration problems found by
+// Requirement.Verify, e.g. defaults that fail their own validator. It also
+// flags variables that are not Sensitive although their Default or
+// already-resolved value looks like a credential (ErrLooksLikeSecret), and
+// names missing from DeclareNames (ErrUndeclaredName).
+// Run it in CI or a unit test to catch mistakes that would otherwise only
+// surface in environments where a variable is unset.
+func Lint() []LintIssue {
+	return std.Lint()
+}
+
+// Lint returns the declaration problems of the registry's requirements.
+func (g *Registry) Lint() []LintIssue {
+	g.mu.RLock()
+	reqs := make([]Requirement, 0, len(g.reg))
+	values := map[string]string{}
+	undeclared := map[string][]LintIssue{}
+	for _, r := range g.reg {
+		reqs = append(reqs, r)
+		if cached, ok := g.cache[r.Name]; ok && cached.present {
+			values[r.Name] = cached.value
+		}
+		undeclared[r.Name] = g.undeclaredIssues(r)
+	}
+	g.mu.RUnlock()
+
+	sort.Slice(reqs, func(i, j int) bool {
+		return reqs[i].Name < reqs[j].Name
+	})
+
+	var issues []LintIssue
+	for _, r := range reqs {
+		issues = append(issues, undeclared[r.Name]...)
+		if err := r.Verify(); err != nil {
+			issues = append(issues, LintIssue{Name: r.Name, Source: r.Source, Err: err})
+		}
+		if issue, ok := secretIssue(r, r.Default); ok {
+			issues = append(issues, issue)
+		} else if issue, ok := secretIssue(r, values[r.Name]); ok {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// checkDefault validates r.Default (transformed like any value) with
+// r.Validate, so that an invalid default is reported even when the
+// environment supplies a valid value. Defaults with ${VAR} references
+// depend on other variables and are only validated once expanded.
+func checkDefault(r Requirement) error {
+	if r.Default == "" || r.Validate == nil || hasReferences(r, r.Default) {
+		return nil
+	}
+	def := r.Default
+	if r.Transform != nil {
+		def = r.Transform(def)
+	}
+	if err := r.Validate(def); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDefault, err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package envreq
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrEmptyList is the error of a required List variable that is set but
+// has no elements, e.g. " , ".
+var ErrEmptyList = errors.New("list has no elements")
+
+// Values returns the comma-separated elements of the value, trimmed of
+// spaces, with empty elements dropped. It returns nil when the value is not
+// present. It is meant for List requirements but works on any Result.
+func (r Result) Values() []string {
+	if !r.Present {
+		return nil
+	}
+	return splitList(r.Value)
+}
+
+// splitList splits a List value into trimmed, non-empty elements.
+func splitList(v string) []string {
+	var elems []string
+	for _, e := range strings.Split(v, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			elems = append(elems, e)
+		}
+	}
+	return elems
+}
+
+// checkList validates the elements of the List value v with
+// r.ElementValidator. All element errors are joined.
+func checkList(r Requirement, v string) error {
+	elems := splitList(v)
+	if len(elems) == 0 && !r.Optional {
+		return ErrEmptyList
+	}
+	if r.ElementValidator == nil {
+		return nil
+	}
+	var errs []error
+	for i, e := range elems {
+		if err := r.ElementValidator(e); err != nil {
+			errs = append(errs, fmt.Errorf("element %d: %w", i+1, err))
+		}
+	}
+	return errors.Join(errs...)
+}
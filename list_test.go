@@ -0,0 +1,37 @@
+package envreq_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestList(t *testing.T) {
+	t.Setenv("LIST_BROKERS", " kafka-1:9092, kafka-2:9092 ,,")
+	t.Setenv("LIST_BAD", "kafka-1:9092,kafka-2,kafka-3:99999")
+	t.Setenv("LIST_EMPTY", " , ")
+
+	g := envreq.New()
+	res := g.Check(envreq.Requirement{Name: "LIST_BROKERS", List: true, ElementValidator: envreq.HostPort})
+	if res.Err != nil || !slices.Equal(res.Values(), []string{"kafka-1:9092", "kafka-2:9092"}) {
+		t.Errorf("Expected two trimmed elements, got %q (%v)", res.Values(), res.Err)
+	}
+
+	res = g.Check(envreq.Requirement{Name: "LIST_BAD", List: true, ElementValidator: envreq.HostPort})
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "element 2") || !strings.Contains(res.Err.Error(), "element 3") {
+		t.Errorf("Expected errors for elements 2 and 3, got %v", res.Err)
+	}
+	if strings.Contains(res.Err.Error(), "kafka-2") {
+		t.Errorf("Expected element errors without the value, got %v", res.Err)
+	}
+
+	if res := g.Check(envreq.Requirement{Name: "LIST_EMPTY", List: true}); !errors.Is(res.Err, envreq.ErrEmptyList) {
+		t.Errorf("Expected ErrEmptyList for a required empty list, got %v", res.Err)
+	}
+	if res := g.Check(envreq.Requirement{Name: "LIST_UNSET", List: true, Optional: true}); res.Values() != nil {
+		t.Errorf("Expected no values for an unset list, got %q", res.Values())
+	}
+}
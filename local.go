@@ -0,0 +1,128 @@
+package envreq
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// DefaultLocalFile is the conventional, git-ignored developer override file.
+const DefaultLocalFile = ".env.local"
+
+// LoadLocalOverrides loads a developer override file whose entries take
+// precedence over the process environment. With no arguments it reads
+// $ENVREQ_LOCAL_FILE, falling back to DefaultLocalFile. A missing file is
+// not an error: the convention is opt-in and the file is normally absent
+// outside a developer checkout.
+//
+// Values loaded this way are reported with ProvenanceLocal. A warning is
+// logged when overrides are active outside the development profile.
+//
+// On targets without file support (js/wasm, envreq_nofiles) it returns an
+// error wrapping errors.ErrUnsupported.
+func LoadLocalOverrides(paths ...string) error {
+	return std.LoadLocalOverrides(paths...)
+}
+
+// LoadLocalOverrides loads a developer override file into the registry.
+// See the package-level LoadLocalOverrides.
+func (g *Registry) LoadLocalOverrides(paths ...string) error {
+	if len(paths) == 0 {
+		p := os.Getenv("ENVREQ_LOCAL_FILE")
+		if p == "" {
+			p = DefaultLocalFile
+		}
+		paths = []string{p}
+	}
+	g.recordLoad(envFiles{paths: paths, local: true})
+	return g.loadLocalOverrides(paths)
+}
+
+// loadLocalOverrides implements LoadLocalOverrides.
+func (g *Registry) loadLocalOverrides(paths []string) error {
+	loaded := 0
+	for _, p := range paths {
+		vars, err := readEnvFile(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		g.mu.Lock()
+		for k, v := range vars {
+			g.localVars[k] = v
+			// Drop any cached result so the override is picked up
+			delete(g.cache, k)
+		}
+		g.mu.Unlock()
+		loaded += len(vars)
+	}
+
+	if loaded > 0 && !IsDevelopment() {
+		g.logf("⚠️  envreq: %d local override(s) active outside development profile (profile=%q)", loaded, Profile())
+	}
+	return nil
+}
+
+// localOverride returns the local override for name, if any.
+func (g *Registry) localOverride(name string) (string, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	v, ok := g.localVars[name]
+	return v, ok
+}
+
+// readEnvFile opens and parses a KEY=VALUE file.
+func readEnvFile(path string) (map[string]string, error) {
+	f, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars, err := parseEnvFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("envreq: %s: %w", path, err)
+	}
+	return vars, nil
+}
+
+// parseEnvFile parses dotenv-style content: KEY=VALUE lines, blank lines and
+// # comments ignored, an optional "export " prefix, and single or double
+// quoted values.
+func parseEnvFile(r io.Reader) (map[string]string, error) {
+	vars := map[string]string{}
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+
+		key, val, ok := strings.Cut(text, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", line)
+		}
+
+		val = strings.TrimSpace(val)
+		if n := len(val); n >= 2 && (val[0] == '"' || val[0] == '\'') && val[n-1] == val[0] {
+			val = val[1 : n-1]
+		}
+		vars[key] = val
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
@@ -0,0 +1,74 @@
+//go:build !envreq_nofiles
+
+package envreq_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestLocalOverrides(t *testing.T) {
+	envreq.Reset()
+	defer envreq.Reset()
+
+	path := filepath.Join(t.TempDir(), ".env.local")
+	content := "# developer overrides\nexport LOCAL_URL=\"http://localhost:8080\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LOCAL_URL", "https://api.example.com")
+	t.Setenv("ENVREQ_LOCAL_FILE", path)
+	envreq.SetProfile("development")
+
+	if err := envreq.LoadLocalOverrides(); err != nil {
+		t.Fatalf("LoadLocalOverrides: %v", err)
+	}
+
+	res := envreq.Check(envreq.Requirement{Name: "LOCAL_URL", Source: "test", Validate: envreq.URL})
+	if res.Value != "http://localhost:8080" {
+		t.Errorf("Expected local override value, got '%s'", res.Value)
+	}
+	if res.Provenance != envreq.ProvenanceLocal {
+		t.Errorf("Expected provenance %q, got %q", envreq.ProvenanceLocal, res.Provenance)
+	}
+
+	if len(res.Shadowed) != 1 || res.Shadowed[0].Provenance != envreq.ProvenanceEnv {
+		t.Fatalf("Expected the env value to be recorded as shadowed, got %+v", res.Shadowed)
+	}
+	if fp := res.Shadowed[0].Fingerprint; fp == "" || strings.Contains(fp, "example") {
+		t.Errorf("Expected an opaque fingerprint, got %q", fp)
+	}
+
+	var buf bytes.Buffer
+	envreq.Report(&buf, envreq.CheckAll())
+	if !strings.Contains(buf.String(), "local override") {
+		t.Error("Expected report to show local override provenance")
+	}
+	if !strings.Contains(buf.String(), "shadows env") {
+		t.Error("Expected report to flag the shadowed env value")
+	}
+	if strings.Contains(buf.String(), "outside development") {
+		t.Error("Did not expect an override warning in development profile")
+	}
+
+	envreq.SetProfile("production")
+	buf.Reset()
+	envreq.Report(&buf, envreq.CheckAll())
+	if !strings.Contains(buf.String(), "outside development") {
+		t.Error("Expected a warning for local overrides outside development")
+	}
+}
+
+func TestLocalOverridesMissingFile(t *testing.T) {
+	envreq.Reset()
+	defer envreq.Reset()
+
+	if err := envreq.LoadLocalOverrides(filepath.Join(t.TempDir(), "nope")); err != nil {
+		t.Errorf("Expected missing override file to be ignored, got %v", err)
+	}
+}
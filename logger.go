@@ -0,0 +1,69 @@
+package envreq
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// Logger receives the registry's diagnostic messages (post-Freeze
+// registrations, override warnings, declaration problems). *log.Logger
+// satisfies it.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// SetLogger routes diagnostic messages of the default registry to l.
+// Pass nil to restore the standard library's default logger.
+func SetLogger(l Logger) {
+	std.SetLogger(l)
+}
+
+// SetLogger routes diagnostic messages of the registry to l.
+func (g *Registry) SetLogger(l Logger) {
+	if l == nil {
+		l = log.Default()
+	}
+	g.ioMu.Lock()
+	g.logger = l
+	g.ioMu.Unlock()
+}
+
+// SetOutput sets where the default registry writes the reports produced by
+// MustValidate and by a post-Freeze panic. Pass nil to restore os.Stderr.
+func SetOutput(w io.Writer) {
+	std.SetOutput(w)
+}
+
+// SetOutput sets where the registry writes its failure reports.
+func (g *Registry) SetOutput(w io.Writer) {
+	if w == nil {
+		w = os.Stderr
+	}
+	g.ioMu.Lock()
+	g.out = w
+	g.ioMu.Unlock()
+}
+
+// logf writes a diagnostic message to the registry's logger, in the
+// configured OutputStyle.
+func (g *Registry) logf(format string, v ...any) {
+	g.ioMu.RLock()
+	l := g.logger
+	g.ioMu.RUnlock()
+
+	if asciiOutput() {
+		l.Printf("%s", styled(fmt.Sprintf(format, v...)))
+		return
+	}
+	l.Printf(format, v...)
+}
+
+// output returns the registry's report destination.
+func (g *Registry) output() io.Writer {
+	g.ioMu.RLock()
+	defer g.ioMu.RUnlock()
+
+	return g.out
+}
@@ -0,0 +1,115 @@
+package envreq
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteMarkdown renders the registered requirements as Markdown tables
+// (name, required, default, validator, description), one per source, for
+// embedding in project documentation:
+//
+//	//go:generate go run ./cmd/docsgen > CONFIG.md
+func WriteMarkdown(w io.Writer) error {
+	return std.WriteMarkdown(w)
+}
+
+// WriteMarkdown renders the registry as Markdown. See the package-level
+// WriteMarkdown.
+func (g *Registry) WriteMarkdown(w io.Writer) error {
+	return g.Describe().WriteMarkdown(w)
+}
+
+// WriteMarkdown renders s as Markdown tables grouped by source. Defaults of
+// sensitive variables are never shown.
+func (s Schema) WriteMarkdown(w io.Writer) error {
+	vars := append([]SchemaVar(nil), s.Vars...)
+	sort.SliceStable(vars, func(i, j int) bool {
+		if vars[i].Source != vars[j].Source {
+			return vars[i].Source < vars[j].Source
+		}
+		return vars[i].Name < vars[j].Name
+	})
+
+	bw := bufio.NewWriter(w)
+	for i, v := range vars {
+		if i == 0 || v.Source != vars[i-1].Source {
+			if i > 0 {
+				bw.WriteByte('\n')
+			}
+			name := v.Source
+			if name == "" {
+				name = "(no source)"
+			}
+			bw.WriteString("### " + markdownCell(name) + "\n\n")
+			bw.WriteString("| Variable | Required | Default | Validator | Description |\n")
+			bw.WriteString("|----------|----------|---------|-----------|-------------|\n")
+		}
+
+		required := "no"
+		if v.Required && v.RequiredAfter != "" {
+			required = "after " + markdownCell(v.RequiredAfter)
+		} else if v.Required {
+			required = "yes"
+		} else if v.RequiredIf != "" {
+			required = "if " + markdownCell(v.RequiredIf)
+		}
+		def := "`" + v.Default + "`"
+		switch {
+		case v.Sensitive:
+			def = "*(sensitive)*"
+		case v.Default == "" && v.DefaultFunc == "custom":
+			def = "*(computed)*"
+		case v.Default == "" && v.DefaultFunc != "":
+			def = "*(computed by `" + v.DefaultFunc + "`)*"
+		case v.Default == "":
+			def = ""
+		}
+		validator := ""
+		if v.Validator != "" {
+			validator = "`" + v.Validator + "`"
+		} else if v.Validated {
+			validator = "custom"
+		}
+		if v.ElementValidator != "" {
+			validator = strings.TrimSpace(validator + " each `" + v.ElementValidator + "`")
+		}
+		if v.DeepValidator == "custom" {
+			validator = strings.TrimSpace(validator + " deep custom")
+		} else if v.DeepValidator != "" {
+			validator = strings.TrimSpace(validator + " deep `" + v.DeepValidator + "`")
+		}
+		desc := markdownCell(v.Description)
+		if v.List {
+			desc = "Comma-separated list. " + desc
+		}
+		if v.External {
+			desc = "Read by child processes. " + desc
+		}
+		if v.Deprecated {
+			note := "**Deprecated.**"
+			if v.ReplacedBy != "" {
+				note = "**Deprecated**, use `" + v.ReplacedBy + "`."
+			}
+			desc = note + " " + desc
+		}
+		if len(v.Aliases) > 0 {
+			desc += " Also read from `" + strings.Join(v.Aliases, "`, `") + "`."
+		}
+		if v.DocsURL != "" {
+			desc += " ([docs](" + v.DocsURL + "))"
+		}
+
+		bw.WriteString("| `" + v.Name + "` | " + required + " | " + markdownCell(def) + " | " +
+			validator + " | " + strings.TrimSpace(desc) + " |\n")
+	}
+	return bw.Flush()
+}
+
+// markdownCell escapes s for use inside a Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
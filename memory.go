@@ -0,0 +1,109 @@
+package envreq
+
+import "time"
+
+// resolved is the cached outcome of resolving one variable. Only the
+// per-resolution fields are kept; the Requirement lives once in reg and is
+// joined back in by result, so metadata is never stored twice.
+type resolved struct {
+	present    bool
+	value      string
+	provenance string
+	shadowed   []Shadow
+	from       string
+	consulted  []Consulted
+	late       bool
+	grace      bool
+	resolvedAt time.Time
+	err        error
+	typed      any  // value parsed by Get, if any
+	optional   bool // whether a conditional or grace period requirement was optional
+}
+
+// resolvedFrom extracts the cacheable part of res.
+func resolvedFrom(res Result) resolved {
+	return resolved{
+		present:    res.Present,
+		value:      res.Value,
+		provenance: res.Provenance,
+		shadowed:   res.Shadowed,
+		from:       res.From,
+		consulted:  res.Consulted,
+		late:       res.Late,
+		grace:      res.Grace,
+		resolvedAt: res.ResolvedAt,
+		err:        res.Err,
+		optional:   res.Optional,
+	}
+}
+
+// result joins the cached outcome with the current registry entry.
+func (v resolved) result(r Requirement) Result {
+	if r.conditional() || r.RequiredAfter.passed != nil {
+		r.Optional = v.optional
+	}
+	return Result{
+		Requirement: r,
+		Present:     v.present,
+		Value:       v.value,
+		Provenance:  v.provenance,
+		Shadowed:    v.shadowed,
+		From:        v.from,
+		Consulted:   v.consulted,
+		Late:        v.late,
+		Grace:       v.grace,
+		ResolvedAt:  v.resolvedAt,
+		Err:         v.err,
+	}
+}
+
+// CompactMemory enables a reduced memory footprint mode for constrained
+// environments (e.g. CLIs handling big schemas in tiny containers): the
+// metadata strings of newly registered requirements and of schemas read
+// with ReadSchema (sources, descriptions, owners) are interned, so the many
+// variables sharing a source or owner share one copy of the string.
+// Interning costs a map lookup per string and is off by default.
+func CompactMemory(enabled bool) {
+	std.CompactMemory(enabled)
+}
+
+// CompactMemory enables the reduced memory footprint mode of the registry.
+func (g *Registry) CompactMemory(enabled bool) {
+	g.compact.Store(enabled)
+}
+
+// intern returns the canonical copy of s. Callers must hold mu.
+func (g *Registry) intern(s string) string {
+	if s == "" {
+		return s
+	}
+	if c, ok := g.strs[s]; ok {
+		return c
+	}
+	g.strs[s] = s
+	return s
+}
+
+// internRequirement interns the shared metadata strings of r. Callers must
+// hold mu.
+func (g *Registry) internRequirement(r Requirement) Requirement {
+	r.Source = g.intern(r.Source)
+	r.Description = g.intern(r.Description)
+	r.Owner = g.intern(r.Owner)
+	r.DocsURL = g.intern(r.DocsURL)
+	return r
+}
+
+// internSchema interns the shared metadata strings of s in place.
+func (g *Registry) internSchema(s *Schema) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for i := range s.Vars {
+		v := &s.Vars[i]
+		v.Source = g.intern(v.Source)
+		v.Description = g.intern(v.Description)
+		v.Owner = g.intern(v.Owner)
+		v.DocsURL = g.intern(v.DocsURL)
+	}
+}
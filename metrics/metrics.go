@@ -0,0 +1,131 @@
+// Package metrics exposes the validation state of an envreq registry as
+// Prometheus metrics, without depending on the Prometheus client library.
+//
+// Serve them directly:
+//
+//	mux.Handle("/metrics/envreq", metrics.Handler(nil))
+//
+// or, with the Prometheus client, register gauge functions over Collect:
+//
+//	prometheus.MustRegister(prometheus.NewGaugeFunc(
+//	    prometheus.GaugeOpts{Name: "envreq_invalid"},
+//	    func() float64 { return float64(metrics.Collect(nil).Invalid) },
+//	))
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bbmumford/envreq"
+)
+
+// Values is a snapshot of the metrics of one registry.
+type Values struct {
+	Registered        int    // registered variables
+	MissingRequired   int    // required variables that are not set
+	Invalid           int    // variables that fail validation
+	LateRegistrations uint64 // registrations after Freeze (optional or in a late window)
+	Revalidations     uint64 // envreq.Revalidate runs
+	Degraded          uint64 // revalidations that found problems
+}
+
+// Collect computes the metrics of g, or of the default registry if g is
+// nil. Missing and invalid counts come from the cached results, i.e. what
+// the running code is using.
+func Collect(g *envreq.Registry) Values {
+	if g == nil {
+		g = envreq.Default()
+	}
+
+	results := g.CheckAll()
+	stats := g.Stats()
+	v := Values{
+		Registered:        len(results),
+		LateRegistrations: stats.LateOptional + stats.LateWindowed,
+		Revalidations:     stats.Revalidations,
+		Degraded:          stats.Degraded,
+	}
+	for _, res := range results {
+		switch {
+		case !res.Present && !res.Optional:
+			v.MissingRequired++
+		case res.Err != nil:
+			v.Invalid++
+		}
+	}
+	return v
+}
+
+// metric is one exposed series.
+type metric struct {
+	name, kind, help string
+	value            func(Values) float64
+}
+
+var exposed = []metric{
+	{"envreq_registered_vars", "gauge", "Number of registered environment variables.",
+		func(v Values) float64 { return float64(v.Registered) }},
+	{"envreq_missing_required", "gauge", "Number of required environment variables that are not set.",
+		func(v Values) float64 { return float64(v.MissingRequired) }},
+	{"envreq_invalid", "gauge", "Number of environment variables that fail validation.",
+		func(v Values) float64 { return float64(v.Invalid) }},
+	{"envreq_late_registrations_total", "counter", "Registrations after Freeze.",
+		func(v Values) float64 { return float64(v.LateRegistrations) }},
+	{"envreq_revalidations_total", "counter", "Periodic revalidation runs.",
+		func(v Values) float64 { return float64(v.Revalidations) }},
+	{"envreq_revalidations_degraded_total", "counter", "Revalidation runs that found missing or invalid variables.",
+		func(v Values) float64 { return float64(v.Degraded) }},
+}
+
+// Write writes the metrics of g (the default registry if nil) in the
+// Prometheus text exposition format.
+func Write(w io.Writer, g *envreq.Registry) error {
+	v := Collect(g)
+	bw := bufio.NewWriter(w)
+	for _, m := range exposed {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", m.name, m.help, m.name, m.kind, m.name, m.value(v))
+	}
+	return bw.Flush()
+}
+
+// Handler returns an http.Handler serving the metrics of g (the default
+// registry if nil). The metrics hold no names or values, so unlike
+// envreq.Handler it is not access controlled.
+func Handler(g *envreq.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		Write(w, g)
+	})
+}
+
+// WriteShapes writes one envreq_value_shape series per set variable of g
+// (the default registry if nil), labelled with its length class, charset
+// class and whether it was defaulted; see envreq.ValueShape. Values are
+// never exposed, Sensitive variables only show whether they are empty and
+// NeverShow variables are left out, but the series do
+// carry variable names: serve them behind the same access control as
+// envreq.Handler, not from the unauthenticated Handler.
+func WriteShapes(w io.Writer, g *envreq.Registry) error {
+	if g == nil {
+		g = envreq.Default()
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprint(bw, "# HELP envreq_value_shape Length and charset class of each set environment variable.\n# TYPE envreq_value_shape gauge\n")
+	for _, res := range g.CheckAll() {
+		shape, ok := res.Shape()
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(bw, "envreq_value_shape{var=\"%s\",length=\"%s\",charset=\"%s\",defaulted=\"%t\"} 1\n",
+			labelEscaper.Replace(res.Name), shape.Length, shape.Charset, shape.Defaulted)
+	}
+	return bw.Flush()
+}
+
+// labelEscaper escapes a label value for the text exposition format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
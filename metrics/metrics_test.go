@@ -0,0 +1,78 @@
+package metrics_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+	"github.com/bbmumford/envreq/metrics"
+)
+
+func TestWrite(t *testing.T) {
+	t.Setenv("METRICS_BAD_URL", "nope")
+	g := envreq.New()
+	g.SetLogger(nopLogger{})
+	g.Check(envreq.Requirement{Name: "METRICS_MISSING", Source: "test"})
+	g.Check(envreq.Requirement{Name: "METRICS_BAD_URL", Source: "test", Validate: envreq.URL})
+	g.Check(envreq.Requirement{Name: "METRICS_OPTIONAL", Source: "test", Optional: true})
+	g.Freeze()
+	g.Check(envreq.Requirement{Name: "METRICS_LATE", Source: "test", Optional: true})
+	g.Revalidate()
+
+	v := metrics.Collect(g)
+	want := metrics.Values{Registered: 4, MissingRequired: 1, Invalid: 1, LateRegistrations: 1, Revalidations: 1, Degraded: 1}
+	if v != want {
+		t.Errorf("Collect = %+v, want %+v", v, want)
+	}
+
+	var buf bytes.Buffer
+	if err := metrics.Write(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"# TYPE envreq_invalid gauge\nenvreq_invalid 1\n",
+		"envreq_missing_required 1\n",
+		"envreq_registered_vars 4\n",
+		"# TYPE envreq_late_registrations_total counter\nenvreq_late_registrations_total 1\n",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("Exposition lacks %q:\n%s", line, buf.String())
+		}
+	}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
+
+func TestWriteShapes(t *testing.T) {
+	t.Setenv("SHAPES_API_KEY", "")
+	t.Setenv("SHAPES_TOKEN", "s3cr3t-t0ken")
+	t.Setenv("SHAPES_PIN", "4921")
+	g := envreq.New()
+	g.SetLogger(nopLogger{})
+	g.Check(envreq.Requirement{Name: "SHAPES_API_KEY", Source: "test", Optional: true})
+	g.Check(envreq.Requirement{Name: "SHAPES_PORT", Source: "test", Default: "8080"})
+	g.Check(envreq.Requirement{Name: "SHAPES_TOKEN", Source: "test", NeverShow: true})
+	g.Check(envreq.Requirement{Name: "SHAPES_PIN", Source: "test", Sensitive: true})
+	g.Check(envreq.Requirement{Name: "SHAPES_UNSET", Source: "test", Optional: true})
+
+	var buf bytes.Buffer
+	if err := metrics.WriteShapes(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"# TYPE envreq_value_shape gauge\n",
+		`envreq_value_shape{var="SHAPES_API_KEY",length="0",charset="empty",defaulted="false"} 1` + "\n",
+		`envreq_value_shape{var="SHAPES_PORT",length="1-7",charset="digits",defaulted="true"} 1` + "\n",
+		`envreq_value_shape{var="SHAPES_PIN",length="1+",charset="",defaulted="false"} 1` + "\n",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("Exposition lacks %q:\n%s", line, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "SHAPES_TOKEN") || strings.Contains(buf.String(), "SHAPES_UNSET") || strings.Contains(buf.String(), "8080") {
+		t.Errorf("Exposition includes a NeverShow or unset variable, or a value:\n%s", buf.String())
+	}
+}
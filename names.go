@@ -0,0 +1,95 @@
+package envreq
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrUndeclaredName is reported by Lint for a variable name that is not
+// among the names given to DeclareNames, usually a typo.
+var ErrUndeclaredName = errors.New("variable name is not declared")
+
+// DeclareNames records the complete set of variable names the program may
+// use, typically from the init of a package holding one constant per name:
+//
+//	const DatabaseURL = "DATABASE_URL"
+//
+//	func init() { envreq.DeclareNames(DatabaseURL, StripeAPIKey) }
+//
+// Once any names are declared, Lint reports every registered name, alias
+// and replacement that is not one of them (ErrUndeclaredName), suggesting
+// the closest declared name, so "DATABSE_URL" fails in CI rather than at
+// deploy time. Calls add to the set.
+func DeclareNames(names ...string) {
+	std.DeclareNames(names...)
+}
+
+// DeclareNames adds names to the registry's declared names. See the
+// package-level DeclareNames.
+func (g *Registry) DeclareNames(names ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, name := range names {
+		g.declared[name] = true
+	}
+}
+
+// undeclaredIssues returns the Lint issues for names of r that are not
+// declared. Callers must hold at least g.mu.RLock.
+func (g *Registry) undeclaredIssues(r Requirement) []LintIssue {
+	if len(g.declared) == 0 {
+		return nil
+	}
+	var issues []LintIssue
+	for _, name := range r.names() {
+		if g.declared[name] {
+			continue
+		}
+		err := fmt.Errorf("%w: %s", ErrUndeclaredName, name)
+		if near := g.nearestDeclared(name); near != "" {
+			err = fmt.Errorf("%w: %s (did you mean %s?)", ErrUndeclaredName, name, near)
+		}
+		issues = append(issues, LintIssue{Name: r.Name, Source: r.Source, Err: err})
+	}
+	return issues
+}
+
+// nearestDeclared returns the declared name closest to name, if it is
+// within a small edit distance.
+func (g *Registry) nearestDeclared(name string) string {
+	declared := make([]string, 0, len(g.declared))
+	for d := range g.declared {
+		declared = append(declared, d)
+	}
+	sort.Strings(declared)
+
+	best, bestDist := "", 3 // suggest only near misses
+	for _, d := range declared {
+		if dist := editDistance(name, d); dist < bestDist {
+			best, bestDist = d, dist
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
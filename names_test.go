@@ -0,0 +1,35 @@
+package envreq_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestDeclareNames(t *testing.T) {
+	g := envreq.New()
+	g.Check(envreq.Requirement{Name: "DATABSE_URL", Source: "db", Optional: true})
+	g.Check(envreq.Requirement{Name: "STRIPE_API_KEY", Source: "payments", Optional: true, Aliases: []string{"STRIPE_KEY"}})
+
+	// Nothing is reported until names are declared
+	for _, issue := range g.Lint() {
+		if errors.Is(issue.Err, envreq.ErrUndeclaredName) {
+			t.Errorf("Unexpected issue without declared names: %v", issue)
+		}
+	}
+
+	g.DeclareNames("DATABASE_URL", "STRIPE_API_KEY")
+	var undeclared []string
+	for _, issue := range g.Lint() {
+		if errors.Is(issue.Err, envreq.ErrUndeclaredName) {
+			undeclared = append(undeclared, issue.Err.Error())
+		}
+	}
+	if len(undeclared) != 2 ||
+		!strings.Contains(undeclared[0], "DATABSE_URL (did you mean DATABASE_URL?)") ||
+		!strings.Contains(undeclared[1], "STRIPE_KEY") {
+		t.Errorf("Expected the typo and the undeclared alias, got %q", undeclared)
+	}
+}
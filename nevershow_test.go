@@ -0,0 +1,48 @@
+package envreq_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestNeverShow(t *testing.T) {
+	errBad := errors.New(`bad key "root-secret-xyz"`)
+	t.Setenv("ENVREQ_SHOW_VALUES", "1")
+	t.Setenv("NS_ROOT_KEY", "root-secret-abcd")
+	t.Setenv("NS_SIGNING_KEY", "root-secret-xyz")
+
+	g := envreq.New()
+	key := g.Check(envreq.Requirement{Name: "NS_ROOT_KEY", NeverShow: true})
+	signing := g.Check(envreq.Requirement{
+		Name:      "NS_SIGNING_KEY",
+		NeverShow: true,
+		Validate:  func(string) error { return errBad },
+	})
+
+	if !key.Sensitive {
+		t.Error("Expected NeverShow to imply Sensitive")
+	}
+
+	var buf bytes.Buffer
+	g.Report(&buf)
+	if strings.Contains(buf.String(), "abcd") || strings.Contains(buf.String(), "root-secret") {
+		t.Errorf("Expected no value details in the debug report:\n%s", buf.String())
+	}
+
+	if strings.Contains(signing.Err.Error(), "root-secret") {
+		t.Errorf("Expected the validator message to be withheld, got %v", signing.Err)
+	}
+	if !errors.Is(signing.Err, errBad) {
+		t.Errorf("Expected errors.Is to see the validator error, got %v", signing.Err)
+	}
+
+	for _, v := range g.TakeSnapshot().Vars {
+		if v.Provenance == "" || v.Fingerprint != "" {
+			t.Errorf("Expected provenance without a fingerprint for %s, got %+v", v.Name, v)
+		}
+	}
+}
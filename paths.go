@@ -0,0 +1,125 @@
+package envreq
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+var pathBase atomic.Pointer[string]
+
+// SetPathBase sets the directory that FileExists, DirExists, FileReadable
+// and Executable resolve relative paths against, e.g. the directory of the
+// configuration file or a container's working root. "" (the default) uses
+// the process working directory.
+func SetPathBase(dir string) {
+	pathBase.Store(&dir)
+}
+
+// resolvePath returns v resolved against the path base.
+func resolvePath(v string) string {
+	if base := pathBase.Load(); base != nil && *base != "" && !filepath.IsAbs(v) {
+		return filepath.Join(*base, v)
+	}
+	return v
+}
+
+// statPath stats the resolved path v, with errors that name the problem
+// but not the path.
+func statPath(v string) (string, fs.FileInfo, error) {
+	if v == "" {
+		return "", nil, fmt.Errorf("path cannot be empty")
+	}
+	path := resolvePath(v)
+	info, err := statFile(path)
+	if err != nil {
+		var perr *fs.PathError
+		if errors.As(err, &perr) {
+			err = perr.Err
+		}
+		return path, nil, fmt.Errorf("cannot access path: %w", err)
+	}
+	return path, info, nil
+}
+
+// FileExists validates that the value names an existing file (not a
+// directory), e.g. a TLS certificate. Relative paths are resolved against
+// SetPathBase.
+func FileExists(v string) error {
+	_, info, err := statPath(v)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("is a directory, expected a file")
+	}
+	return nil
+}
+
+// DirExists validates that the value names an existing directory, e.g. a
+// plugin directory. Relative paths are resolved against SetPathBase.
+func DirExists(v string) error {
+	_, info, err := statPath(v)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("is not a directory")
+	}
+	return nil
+}
+
+// FileReadable validates that the value names a file the process can open
+// for reading. Relative paths are resolved against SetPathBase.
+func FileReadable(v string) error {
+	path, info, err := statPath(v)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("is a directory, expected a file")
+	}
+	f, err := openFile(path)
+	if err != nil {
+		var perr *fs.PathError
+		if errors.As(err, &perr) {
+			err = perr.Err
+		}
+		return fmt.Errorf("cannot read file: %w", err)
+	}
+	f.Close()
+	return nil
+}
+
+// windowsExecutable are the file extensions Windows runs directly.
+var windowsExecutable = []string{".exe", ".com", ".bat", ".cmd"}
+
+// Executable validates that the value names a file with an execute
+// permission bit set (on Windows: an .exe, .com, .bat or .cmd file).
+// Relative paths are resolved against SetPathBase.
+func Executable(v string) error {
+	path, info, err := statPath(v)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("is a directory, expected an executable")
+	}
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(path))
+		for _, e := range windowsExecutable {
+			if ext == e {
+				return nil
+			}
+		}
+		return fmt.Errorf("is not executable")
+	}
+	if info.Mode().Perm()&0o111 == 0 {
+		return fmt.Errorf("is not executable")
+	}
+	return nil
+}
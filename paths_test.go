@@ -0,0 +1,72 @@
+//go:build !(js && wasm) && !envreq_nofiles
+
+package envreq_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestPathValidators(t *testing.T) {
+	dir := t.TempDir()
+	cert := filepath.Join(dir, "tls.crt")
+	tool := filepath.Join(dir, "plugin.sh")
+	if err := os.WriteFile(cert, []byte("cert"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(tool, []byte("#!/bin/sh\n"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.crt")
+
+	type test struct {
+		name      string
+		validator func(string) error
+		value     string
+		wantError bool
+	}
+	tests := []test{
+		{"file exists", envreq.FileExists, cert, false},
+		{"file missing", envreq.FileExists, missing, true},
+		{"file is dir", envreq.FileExists, dir, true},
+		{"dir exists", envreq.DirExists, dir, false},
+		{"dir is file", envreq.DirExists, cert, true},
+		{"readable", envreq.FileReadable, cert, false},
+		{"readable missing", envreq.FileReadable, missing, true},
+		{"empty path", envreq.FileExists, "", true},
+	}
+	if runtime.GOOS != "windows" {
+		tests = append(tests,
+			test{"executable", envreq.Executable, tool, false},
+			test{"not executable", envreq.Executable, cert, true},
+		)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validator(tt.value)
+			if (err != nil) != tt.wantError {
+				t.Errorf("validator() error = %v, wantError %v", err, tt.wantError)
+			}
+		})
+	}
+
+	if err := envreq.FileExists(missing); err == nil || strings.Contains(err.Error(), dir) {
+		t.Errorf("Expected an error without the path, got %v", err)
+	}
+
+	// Relative paths resolve against the base
+	envreq.SetPathBase(dir)
+	defer envreq.SetPathBase("")
+	if err := envreq.FileReadable("tls.crt"); err != nil {
+		t.Errorf("Expected tls.crt relative to the base, got %v", err)
+	}
+	if err := envreq.FileExists(cert); err != nil {
+		t.Errorf("Expected absolute paths to ignore the base, got %v", err)
+	}
+}
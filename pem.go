@@ -0,0 +1,186 @@
+package envreq
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var certExpiryWarning atomic.Int64
+
+// maxPEMFileSize bounds the size of a PEM file.
+const maxPEMFileSize = 1 << 20
+
+// SetCertExpiryWarning makes Check log a warning on the registry's Logger
+// when a variable validated with PEMCertificate (as Validate, or as the
+// ElementValidator of a List) holds a certificate that expires within d,
+// e.g. 14 days. Combined with Revalidate the warning repeats until the
+// certificate is rotated. 0 (the default) disables the warning.
+func SetCertExpiryWarning(d time.Duration) {
+	certExpiryWarning.Store(int64(d))
+}
+
+// pemData returns the PEM text of v: v itself when it holds a PEM block
+// (with literal "\n" escapes, as often found in environment variables,
+// turned into newlines), otherwise the contents of the file v names,
+// resolved against SetPathBase.
+func pemData(v string) ([]byte, error) {
+	if strings.Contains(v, "-----BEGIN") {
+		if !strings.Contains(v, "\n") {
+			v = strings.ReplaceAll(v, `\n`, "\n")
+		}
+		return []byte(v), nil
+	}
+	if v == "" {
+		return nil, fmt.Errorf("must be PEM data or the path of a PEM file")
+	}
+	f, err := openFile(resolvePath(v))
+	if err != nil {
+		var perr *fs.PathError
+		if errors.As(err, &perr) {
+			err = perr.Err
+		}
+		return nil, fmt.Errorf("not PEM data, and cannot read it as a file: %w", err)
+	}
+	defer f.Close()
+
+	// Read one byte past the limit: a truncated file would fail to parse
+	// with a misleading error
+	data, err := io.ReadAll(io.LimitReader(f, maxPEMFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxPEMFileSize {
+		return nil, fmt.Errorf("PEM file is larger than %d bytes", maxPEMFileSize)
+	}
+	return data, nil
+}
+
+// PEMCertificate validates one or more PEM "CERTIFICATE" blocks, given
+// inline or as the path of a file: every certificate must parse and be
+// within its validity period. See SetCertExpiryWarning for early warnings
+// from Check.
+func PEMCertificate(v string) error {
+	data, err := pemData(v)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	found := 0
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		found++
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("certificate %d: %w", found, err)
+		}
+		switch {
+		case now.After(cert.NotAfter):
+			return fmt.Errorf("certificate %d (%s) expired on %s", found, cert.Subject.CommonName, formatTime(cert.NotAfter))
+		case now.Before(cert.NotBefore):
+			return fmt.Errorf("certificate %d (%s) is not valid before %s", found, cert.Subject.CommonName, formatTime(cert.NotBefore))
+		}
+	}
+	if found == 0 {
+		return fmt.Errorf("no PEM certificate found")
+	}
+	return nil
+}
+
+// warnCertExpiry logs on g's Logger the certificates in v, the valid
+// value of r, that expire within the SetCertExpiryWarning window, when r
+// is validated with PEMCertificate.
+func (g *Registry) warnCertExpiry(r Requirement, v string) {
+	warn := time.Duration(certExpiryWarning.Load())
+	if warn <= 0 {
+		return
+	}
+	values := []string{v}
+	switch {
+	case sameValidator(r.Validate, PEMCertificate):
+	case r.List && sameValidator(r.ElementValidator, PEMCertificate):
+		values = splitList(v)
+	default:
+		return
+	}
+
+	now := time.Now()
+	for _, v := range values {
+		data, err := pemData(v)
+		if err != nil {
+			continue
+		}
+		for {
+			var block *pem.Block
+			block, data = pem.Decode(data)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil || cert.NotAfter.Sub(now) >= warn {
+				continue
+			}
+			g.logf("⚠️  envreq: certificate %s in %s (from %s) expires in %s (%s)",
+				cert.Subject.CommonName, r.Name, r.Source, formatDuration(cert.NotAfter.Sub(now)), formatTime(cert.NotAfter))
+		}
+	}
+}
+
+// sameValidator reports whether fn is the validator v.
+func sameValidator(fn, v func(string) error) bool {
+	return fn != nil && reflect.ValueOf(fn).Pointer() == reflect.ValueOf(v).Pointer()
+}
+
+// PEMPrivateKey validates a PEM private key, given inline or as the path
+// of a file, in PKCS #8 ("PRIVATE KEY"), PKCS #1 ("RSA PRIVATE KEY") or
+// SEC 1 ("EC PRIVATE KEY") form. Encrypted keys are rejected.
+func PEMPrivateKey(v string) error {
+	data, err := pemData(v)
+	if err != nil {
+		return err
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return fmt.Errorf("no PEM private key found")
+		}
+		if strings.Contains(block.Headers["Proc-Type"], "ENCRYPTED") || block.Type == "ENCRYPTED PRIVATE KEY" {
+			return fmt.Errorf("private key is encrypted")
+		}
+		switch block.Type {
+		case "PRIVATE KEY":
+			_, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			_, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			_, err = x509.ParseECPrivateKey(block.Bytes)
+		default:
+			continue
+		}
+		if err != nil {
+			// x509 errors describe the structure, never the key material
+			return fmt.Errorf("invalid %s: %w", strings.ToLower(block.Type), err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,162 @@
+package envreq
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// periodicState counts revalidation runs.
+type periodicState struct {
+	runs     atomic.Uint64
+	degraded atomic.Uint64
+}
+
+func (p *periodicState) reset() {
+	p.runs.Store(0)
+	p.degraded.Store(0)
+}
+
+// Revalidate resolves and validates every registered variable again, from
+// its sources, and returns the fresh results with a *ValidationError if
+// required variables are now missing or invalid. The cached values handed
+// out by Check and Value are left untouched: it detects configuration that
+// degraded after startup (a deleted secret file, a rotated-away key)
+// without changing what the running code sees.
+func Revalidate() ([]Result, error) {
+	return std.Revalidate()
+}
+
+// Revalidate re-resolves the registry's variables. See the package-level
+// Revalidate.
+func (g *Registry) Revalidate() ([]Result, error) {
+	g.mu.RLock()
+	for _, p := range g.providers {
+		// Ask the sources again, refreshing the lookup cache
+		p.clearCache()
+	}
+	reqs := make([]Requirement, 0, len(g.reg))
+	for _, r := range g.reg {
+		reqs = append(reqs, r)
+	}
+	g.mu.RUnlock()
+
+	results := make([]Result, len(reqs))
+	for i, r := range reqs {
+		results[i] = g.evaluate(r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	g.periodic.runs.Add(1)
+	verr := &ValidationError{Problems: problems(results), Constraints: g.constraintErrors(results)}
+	if len(verr.Problems) > 0 || len(verr.Constraints) > 0 {
+		g.periodic.degraded.Add(1)
+		return results, verr
+	}
+	return results, nil
+}
+
+// Escalation configures how StartPeriodicValidation reacts to degraded
+// configuration. Every degradation and recovery is logged; persistent
+// degradation escalates to the Metric and then the Callback hook.
+type Escalation struct {
+	// MetricAfter is the number of consecutive degraded runs before Metric
+	// is called; 0 means 1.
+	MetricAfter int
+	// Metric is called after every run once MetricAfter is reached with the
+	// number of problems, and once with 0 on recovery, e.g. to set a gauge.
+	Metric func(problems int)
+
+	// CallbackAfter is the number of consecutive degraded runs before
+	// Callback is called; 0 means 3.
+	CallbackAfter int
+	// Callback is called once per degradation episode, e.g. to page or to
+	// trigger a graceful restart.
+	Callback func(err *ValidationError)
+}
+
+// DefaultRevalidationInterval is the interval StartPeriodicValidation uses
+// when given one that is not positive, e.g. from an unset duration.
+const DefaultRevalidationInterval = time.Minute
+
+// StartPeriodicValidation calls Revalidate every interval in a background
+// goroutine until ctx is done, escalating per esc while the configuration
+// stays degraded. An interval that is not positive is logged and replaced
+// by DefaultRevalidationInterval. It is meant for long-lived daemons whose
+// configuration can break after startup, e.g. an expired certificate or a
+// deleted secret file.
+func StartPeriodicValidation(ctx context.Context, interval time.Duration, esc Escalation) {
+	std.StartPeriodicValidation(ctx, interval, esc)
+}
+
+// StartPeriodicValidation revalidates the registry periodically. See the
+// package-level StartPeriodicValidation.
+func (g *Registry) StartPeriodicValidation(ctx context.Context, interval time.Duration, esc Escalation) {
+	if esc.MetricAfter <= 0 {
+		esc.MetricAfter = 1
+	}
+	if esc.CallbackAfter <= 0 {
+		esc.CallbackAfter = 3
+	}
+	if interval <= 0 {
+		g.logf("⚠️  envreq: periodic validation interval %v is not positive; using %v", interval, DefaultRevalidationInterval)
+		interval = DefaultRevalidationInterval
+	}
+
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		streak := 0
+		metered := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+			streak, metered = g.escalate(streak, metered, esc)
+		}
+	}()
+}
+
+// escalate runs one revalidation and applies esc. streak is the number of
+// consecutive degraded runs so far and metered whether Metric was called
+// during this episode; the updated values are returned.
+func (g *Registry) escalate(streak int, metered bool, esc Escalation) (int, bool) {
+	_, err := g.Revalidate()
+	if err == nil {
+		if streak > 0 {
+			g.logf("envreq: configuration recovered after %d degraded check(s)", streak)
+		}
+		if metered && esc.Metric != nil {
+			esc.Metric(0)
+		}
+		return 0, false
+	}
+
+	verr := err.(*ValidationError)
+	streak++
+	if streak == 1 {
+		names := make([]string, 0, len(verr.Problems)+len(verr.Constraints))
+		for _, p := range verr.Problems {
+			names = append(names, p.String())
+		}
+		for _, c := range verr.Constraints {
+			names = append(names, c.Err.Error())
+		}
+		g.logf("🚨 envreq: configuration degraded since startup: %s", strings.Join(names, ", "))
+	}
+	if streak >= esc.MetricAfter && esc.Metric != nil {
+		esc.Metric(len(verr.Problems) + len(verr.Constraints))
+		metered = true
+	}
+	if streak == esc.CallbackAfter && esc.Callback != nil {
+		esc.Callback(verr)
+	}
+	return streak, metered
+}
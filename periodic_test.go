@@ -0,0 +1,112 @@
+package envreq_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestRevalidate(t *testing.T) {
+	g := envreq.New()
+	var secret atomic.Value
+	secret.Store("s3cret")
+	g.SetSources(envreq.SourceFunc(func(name string) (string, bool, error) {
+		v := secret.Load().(string)
+		return v, v != "", nil
+	}))
+
+	if res := g.Check(envreq.Requirement{Name: "PERIODIC_SECRET", Source: "test"}); !res.Present {
+		t.Fatal("Expected PERIODIC_SECRET to be present at startup")
+	}
+	if _, err := g.Revalidate(); err != nil {
+		t.Fatalf("Unexpected revalidation error: %v", err)
+	}
+
+	secret.Store("")
+	if _, err := g.Revalidate(); err == nil {
+		t.Error("Expected a revalidation error once the secret is gone")
+	}
+	if v, ok := g.Value("PERIODIC_SECRET"); !ok || v != "s3cret" {
+		t.Errorf("Revalidate must not change cached values, got %q", v)
+	}
+	if s := g.Stats(); s.Revalidations != 2 || s.Degraded != 1 {
+		t.Errorf("Unexpected counters: %+v", s)
+	}
+}
+
+func TestPeriodicValidationEscalation(t *testing.T) {
+	g := envreq.New()
+	g.SetLogger(&captureLogger{})
+	var present atomic.Bool
+	present.Store(true)
+	g.SetSources(envreq.SourceFunc(func(name string) (string, bool, error) {
+		return "x", present.Load(), nil
+	}))
+	g.Check(envreq.Requirement{Name: "PERIODIC_FILE", Source: "test"})
+
+	var mu sync.Mutex
+	var metrics []int
+	called := make(chan *envreq.ValidationError, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	present.Store(false)
+	g.StartPeriodicValidation(ctx, time.Millisecond, envreq.Escalation{
+		MetricAfter:   2,
+		CallbackAfter: 3,
+		Metric: func(n int) {
+			mu.Lock()
+			metrics = append(metrics, n)
+			mu.Unlock()
+		},
+		Callback: func(err *envreq.ValidationError) { called <- err },
+	})
+
+	select {
+	case err := <-called:
+		if len(err.Problems) != 1 || err.Problems[0].Name != "PERIODIC_FILE" {
+			t.Errorf("Unexpected problems: %+v", err.Problems)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Callback was not called")
+	}
+
+	present.Store(true)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		last := metrics[len(metrics)-1]
+		first := metrics[0]
+		mu.Unlock()
+		if last == 0 {
+			if first != 1 {
+				t.Errorf("Expected the metric to report 1 problem, got %v", first)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Metric did not report recovery")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPeriodicValidationInterval(t *testing.T) {
+	g := envreq.New()
+	logger := &captureLogger{}
+	g.SetLogger(logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A zero interval must not panic the background goroutine
+	g.StartPeriodicValidation(ctx, 0, envreq.Escalation{})
+	if len(logger.lines) != 1 || !strings.Contains(logger.lines[0], "using "+envreq.DefaultRevalidationInterval.String()) {
+		t.Errorf("Expected a warning about the interval, got %q", logger.lines)
+	}
+	time.Sleep(10 * time.Millisecond)
+}
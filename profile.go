@@ -0,0 +1,46 @@
+package envreq
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	profileMu sync.RWMutex
+	profile   string
+)
+
+// Profile returns the active deployment profile, e.g. "development" or
+// "production". An explicit SetProfile wins; otherwise ENVREQ_PROFILE and
+// then APP_ENV are consulted. The result is lower-cased.
+func Profile() string {
+	profileMu.RLock()
+	p := profile
+	profileMu.RUnlock()
+
+	if p == "" {
+		p = os.Getenv("ENVREQ_PROFILE")
+	}
+	if p == "" {
+		p = os.Getenv("APP_ENV")
+	}
+	return strings.ToLower(strings.TrimSpace(p))
+}
+
+// SetProfile overrides the profile detected from the environment.
+// Pass "" to go back to detection.
+func SetProfile(p string) {
+	profileMu.Lock()
+	profile = p
+	profileMu.Unlock()
+}
+
+// IsDevelopment reports whether the active profile is a local/dev profile.
+func IsDevelopment() bool {
+	switch Profile() {
+	case "dev", "development", "local":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package envreq
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// Auth statuses of a ProviderStatus.
+const (
+	AuthOK     = "ok"     // the provider accepted the credentials
+	AuthFailed = "failed" // the provider rejected the credentials
+)
+
+// ProviderStatus is the health of one Source of the chain set with
+// SetSources, as observed by the lookups made so far. It lets a report
+// tell a secrets backend outage or a revoked token apart from variables
+// that are genuinely missing.
+//
+// A lookup error wrapping fs.ErrPermission counts as an authentication
+// failure: the provider was reached but rejected the credentials. Sources
+// that cache may report their cache counters with a method
+//
+//	CacheStats() (hits, misses uint64)
+type ProviderStatus struct {
+	Name        string        // provenance recorded for the provider's values
+	Reachable   bool          // the last lookup reached the provider; false before the first lookup
+	Auth        string        // AuthOK or AuthFailed; "" when unknown or not applicable
+	Lookups     uint64        // lookups made
+	Errors      uint64        // lookups that failed
+	Latency     time.Duration // mean lookup latency
+	CacheHits   uint64        // lookups served from the provider's cache, see CacheStats
+	CacheMisses uint64        // lookups that went to the backend
+	LastError   error         // error of the last failed lookup, if the last lookup failed
+}
+
+// CacheHitRate returns the fraction of lookups served from the provider's
+// cache, and false when the provider does not report cache counters or
+// has not been consulted yet.
+func (p ProviderStatus) CacheHitRate() (float64, bool) {
+	total := p.CacheHits + p.CacheMisses
+	if total == 0 {
+		return 0, false
+	}
+	return float64(p.CacheHits) / float64(total), true
+}
+
+// Providers returns the health of the configured sources, highest
+// precedence first.
+func Providers() []ProviderStatus {
+	return std.Providers()
+}
+
+// Providers returns the health of the registry's sources.
+func (g *Registry) Providers() []ProviderStatus {
+	g.mu.RLock()
+	sources, stats := g.sources, g.providers
+	g.mu.RUnlock()
+
+	out := make([]ProviderStatus, len(sources))
+	for i, s := range sources {
+		out[i] = stats[i].status(s)
+	}
+	return out
+}
+
+// providerStats accumulates the lookups made to one source, and caches
+// their results when the source is remote (see resolveLayers).
+type providerStats struct {
+	mu      sync.Mutex
+	lookups uint64
+	errors  uint64
+	total   time.Duration
+	lastErr error
+	cache   map[lookupKey]cachedLookup
+}
+
+// lookupKey identifies a lookup in a source: a name, or the SecretRef a
+// RefSource resolves instead.
+type lookupKey struct {
+	name, ref string
+}
+
+// cachedLookup is the result of a successful lookup.
+type cachedLookup struct {
+	val   string
+	found bool
+}
+
+// cached returns the cached result of the lookup k, if any.
+func (p *providerStats) cached(k lookupKey) (string, bool, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c, ok := p.cache[k]
+	return c.val, c.found, ok
+}
+
+// store caches the result of the lookup k.
+func (p *providerStats) store(k lookupKey, val string, found bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cache == nil {
+		p.cache = map[lookupKey]cachedLookup{}
+	}
+	p.cache[k] = cachedLookup{val: val, found: found}
+}
+
+// clearCache drops the cached lookups.
+func (p *providerStats) clearCache() {
+	p.mu.Lock()
+	p.cache = nil
+	p.mu.Unlock()
+}
+
+// newProviderStats returns empty statistics for n sources.
+func newProviderStats(n int) []*providerStats {
+	stats := make([]*providerStats, n)
+	for i := range stats {
+		stats[i] = &providerStats{}
+	}
+	return stats
+}
+
+// record adds one lookup that took d and failed with err.
+func (p *providerStats) record(d time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lookups++
+	p.total += d
+	p.lastErr = err
+	if err != nil {
+		p.errors++
+	}
+}
+
+// status returns the health of s.
+func (p *providerStats) status(s Source) ProviderStatus {
+	p.mu.Lock()
+	st := ProviderStatus{
+		Name:      sourceName(s),
+		Lookups:   p.lookups,
+		Errors:    p.errors,
+		LastError: p.lastErr,
+	}
+	if p.lookups > 0 {
+		st.Latency = p.total / time.Duration(p.lookups)
+	}
+	p.mu.Unlock()
+
+	if n, ok := s.(named); ok {
+		s = n.Source
+	}
+	denied := errors.Is(st.LastError, fs.ErrPermission)
+	st.Reachable = st.Lookups > 0 && (st.LastError == nil || denied)
+	_, authenticates := s.(RefSource)
+	switch {
+	case denied:
+		st.Auth = AuthFailed
+	case authenticates && st.Reachable:
+		st.Auth = AuthOK
+	}
+	if c, ok := s.(interface{ CacheStats() (hits, misses uint64) }); ok {
+		st.CacheHits, st.CacheMisses = c.CacheStats()
+	}
+	return st
+}
+
+// remoteProviders reports whether the chain holds anything but Env, which
+// is always reachable and not worth a report section.
+func remoteProviders(providers []ProviderStatus) bool {
+	for _, p := range providers {
+		if p.Name != ProvenanceEnv {
+			return true
+		}
+	}
+	return false
+}
+
+// reportProviders writes the providers section of a report.
+func reportProviders(w io.Writer, providers []ProviderStatus) {
+	if !remoteProviders(providers) {
+		return
+	}
+	fmt.Fprintf(w, "\nProviders:\n")
+	for _, p := range providers {
+		state := "reachable"
+		switch {
+		case p.Lookups == 0:
+			state = "not consulted"
+		case !p.Reachable:
+			state = "UNREACHABLE"
+		}
+		line := fmt.Sprintf("  %-16s %-13s", p.Name, state)
+		if p.Auth != "" {
+			line += "  auth " + p.Auth
+		}
+		if p.Lookups > 0 {
+			line += fmt.Sprintf("  %d lookup(s), %s avg", p.Lookups, p.Latency.Round(time.Millisecond/10))
+		}
+		if rate, ok := p.CacheHitRate(); ok {
+			line += fmt.Sprintf("  cache %.0f%%", rate*100)
+		}
+		if p.LastError != nil {
+			line += fmt.Sprintf("\n    last error: %v", p.LastError)
+		}
+		fmt.Fprintln(w, line)
+	}
+}
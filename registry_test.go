@@ -0,0 +1,131 @@
+package envreq_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestRegistryIsolation(t *testing.T) {
+	t.Setenv("ISOLATED_VAR", "value")
+
+	a := envreq.New()
+	b := envreq.New()
+
+	a.Check(envreq.Requirement{Name: "ISOLATED_VAR", Source: "a"})
+	a.Freeze()
+
+	// b is unaffected by a's registrations and freeze
+	if _, ok := b.Value("ISOLATED_VAR"); ok {
+		t.Error("Expected registries to be isolated")
+	}
+	b.Check(envreq.Requirement{Name: "ONLY_IN_B", Source: "b"})
+
+	if got := len(a.CheckAll()); got != 1 {
+		t.Errorf("Expected 1 result in a, got %d", got)
+	}
+
+	var buf bytes.Buffer
+	if missing := b.Report(&buf); missing != 1 {
+		t.Errorf("Expected 1 missing in b, got %d", missing)
+	}
+
+	// The default registry is untouched as well
+	if _, ok := envreq.Default().Value("ONLY_IN_B"); ok {
+		t.Error("Expected default registry to be isolated")
+	}
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			g := envreq.New()
+			for j := 0; j < 50; j++ {
+				g.Check(envreq.Requirement{Name: fmt.Sprintf("CONC_%d_%d", i, j), Source: "test", Optional: true})
+			}
+			g.Freeze()
+			if n := len(g.CheckAll()); n != 50 {
+				t.Errorf("Expected 50 results, got %d", n)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+type captureLogger struct{ lines []string }
+
+func (c *captureLogger) Printf(format string, v ...any) {
+	c.lines = append(c.lines, fmt.Sprintf(format, v...))
+}
+
+func TestRegistryLoggerAndOutput(t *testing.T) {
+	g := envreq.New()
+	logger := &captureLogger{}
+	var out bytes.Buffer
+	g.SetLogger(logger)
+	g.SetOutput(&out)
+
+	g.Check(envreq.Requirement{Name: "LOGGED_VAR", Source: "test", Optional: true})
+	g.Freeze()
+
+	func() {
+		defer func() { recover() }()
+		g.Check(envreq.Requirement{Name: "LOGGED_LATE", Source: "test"})
+	}()
+
+	if len(logger.lines) == 0 || !strings.Contains(strings.Join(logger.lines, "\n"), "LOGGED_LATE") {
+		t.Errorf("Expected diagnostics to go to the logger, got %v", logger.lines)
+	}
+	if !strings.Contains(out.String(), "LOGGED_VAR") {
+		t.Errorf("Expected the panic report on the configured output, got %q", out.String())
+	}
+}
+
+func TestASCIIOutput(t *testing.T) {
+	g := envreq.New()
+	logger := &captureLogger{}
+	g.SetLogger(logger)
+	g.Check(envreq.Requirement{Name: "ASCII_KEY", Source: "test", Sensitive: true})
+
+	t.Setenv("ENVREQ_ASCII", "1")
+	t.Setenv("ASCII_LATE", "x")
+	t.Setenv("ENVREQ_SHOW_VALUES", "1")
+	t.Setenv("ENVREQ_PROFILE", "development")
+	t.Setenv("ASCII_KEY", "sk_abcd")
+	g.Freeze()
+	g.Check(envreq.Requirement{Name: "ASCII_LATE", Source: "test", Optional: true})
+
+	var buf bytes.Buffer
+	g.Reset()
+	g.Check(envreq.Requirement{Name: "ASCII_KEY", Source: "test", Sensitive: true})
+	g.Report(&buf)
+
+	for _, s := range append(logger.lines, buf.String()) {
+		for _, r := range s {
+			if r > 0x7f {
+				t.Fatalf("Non-ASCII output with ENVREQ_ASCII=1: %q", s)
+			}
+		}
+	}
+	if n := len(logger.lines); n == 0 || !strings.HasPrefix(logger.lines[n-1], "WARNING: ") {
+		t.Errorf("Expected a WARNING: prefixed log line, got %q", logger.lines)
+	}
+	if !strings.Contains(buf.String(), "****abcd") {
+		t.Errorf("Expected an ASCII mask, got:\n%s", buf.String())
+	}
+
+	envreq.SetOutputStyle(envreq.StyleUnicode)
+	defer envreq.SetOutputStyle(envreq.StyleAuto)
+	buf.Reset()
+	g.Report(&buf)
+	if !strings.Contains(buf.String(), "••••abcd") {
+		t.Errorf("Expected StyleUnicode to override ENVREQ_ASCII, got:\n%s", buf.String())
+	}
+}
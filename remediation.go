@@ -0,0 +1,76 @@
+package envreq
+
+import "fmt"
+
+// Remediation tells a person or a bot how to fix a variable whose status
+// is not StatusOK, so that chatops and deploy tooling can post actionable
+// instructions rather than just the failure. It is part of ReportEntry and
+// never carries the current value.
+type Remediation struct {
+	Action    string `json:"action"`              // what to do, e.g. "set DB_URL"
+	Command   string `json:"command,omitempty"`   // suggested shell command, e.g. "export DB_URL='postgres://db:5432/app'"
+	Example   string `json:"example,omitempty"`   // Requirement.Example
+	DocsURL   string `json:"docsUrl,omitempty"`   // Requirement.DocsURL
+	Owner     string `json:"owner,omitempty"`     // Requirement.Owner, the team to ask
+	SecretRef string `json:"secretRef,omitempty"` // where the secret is expected to be stored
+}
+
+// remediation returns how to fix res, which has the given status and, for
+// StatusIncomplete, detail, or nil when there is nothing to fix.
+func remediation(res Result, status, detail string) *Remediation {
+	rem := &Remediation{
+		Example:   res.Example,
+		DocsURL:   res.DocsURL,
+		Owner:     res.Owner,
+		SecretRef: res.SecretRef,
+	}
+	set := ""
+	if shellName(res.Name) {
+		placeholder := "'<value>'"
+		if res.Example != "" {
+			placeholder = shellQuote(res.Example)
+		}
+		set = "export " + res.Name + "=" + placeholder
+	}
+
+	switch status {
+	case StatusMissing:
+		rem.Action, rem.Command = "set "+res.Name, set
+		if res.SecretRef != "" {
+			rem.Action = fmt.Sprintf("set %s, or store the secret at %s", res.Name, res.SecretRef)
+		}
+	case StatusInvalid:
+		rem.Action, rem.Command = fmt.Sprintf("replace the value of %s: %v", res.Name, res.Err), set
+	case StatusPending:
+		rem.Action, rem.Command = fmt.Sprintf("set %s before using the feature that needs it", res.Name), set
+		if res.Err != nil {
+			rem.Action = fmt.Sprintf("replace the value of %s: %v", res.Name, res.Err)
+		}
+	case StatusGrace:
+		rem.Action = fmt.Sprintf("set %s; it is required after %s", res.Name, res.RequiredAfter)
+		rem.Command = set
+	case StatusTimeout:
+		rem.Action = fmt.Sprintf("check the provider of %s; its lookup did not finish in time", res.Name)
+	case StatusIncomplete:
+		rem.Action = "set the rest of the group or none of it: " + detail
+	case StatusDeprecated:
+		name := res.Name
+		if res.From != "" {
+			name = res.From
+		}
+		if res.ReplacedBy == "" {
+			rem.Action = "stop setting " + name
+			if shellName(name) {
+				rem.Command = "unset " + name
+			}
+		} else {
+			rem.Action = fmt.Sprintf("rename %s to %s", name, res.ReplacedBy)
+			if shellName(name) && shellName(res.ReplacedBy) {
+				rem.Command = fmt.Sprintf(`export %s="$%s" && unset %s`, res.ReplacedBy, name, name)
+			}
+		}
+	default:
+		return nil
+	}
+	return rem
+}
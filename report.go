@@ -0,0 +1,319 @@
+package envreq
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// Report column widths. DETAILS is the last column and is not padded.
+var reportColumns = [...]struct {
+	title string
+	width int
+}{
+	{"ENV", 20},
+	{"SOURCE", 12},
+	{"REQUIRED", 8},
+	{"SENSITIVE", 9},
+	{"STATUS", 10},
+	{"DETAILS", 20},
+}
+
+// reportRowEstimate is the expected size of one rendered row, used to
+// preallocate the output buffer.
+const reportRowEstimate = 96
+
+// Report writes a safe report (no values printed; sensitive redacted).
+// Returns count of missing required variables.
+func Report(w io.Writer, results []Result) (missing int) {
+	missing, _ = ReportPaged(w, results, 0, nil)
+	return missing
+}
+
+// PageFunc is called by ReportPaged before every page after the first, e.g.
+// to emit a page break, wait for the user, or rotate output files. A non-nil
+// error stops rendering.
+type PageFunc func(w io.Writer, page, pages int) error
+
+// ReportPaged writes the report in pages of pageSize rows, each with its own
+// header, calling between before every page after the first. A pageSize of
+// 0 renders a single page. Rows are rendered into a reused, preallocated
+// buffer and written once per page, which keeps reports of thousands of
+// variables (fleet aggregation) cheap. Warnings are written after the last
+// page. It returns the count of missing required variables and the first
+// write or PageFunc error.
+func ReportPaged(w io.Writer, results []Result, pageSize int, between PageFunc) (missing int, err error) {
+	return reportPaged(w, results, pageSize, between, showValues(), nil)
+}
+
+var showValuesAllowed atomic.Bool
+
+// AllowShowValues lets ENVREQ_SHOW_VALUES=1 enable the debug value column
+// whatever the profile. Without it the variable is only honoured in a
+// non-production profile (see Profile), so that flipping one environment
+// variable in production cannot put internal values into logs.
+func AllowShowValues(allow bool) {
+	showValuesAllowed.Store(allow)
+}
+
+// showValues reports whether reports show (redacted) values. An unset
+// profile counts as production.
+func showValues() bool {
+	if os.Getenv("ENVREQ_SHOW_VALUES") != "1" {
+		return false
+	}
+	if showValuesAllowed.Load() {
+		return true
+	}
+	switch Profile() {
+	case "", "prod", "production":
+		return false
+	}
+	return true
+}
+
+// reportPaged implements ReportPaged. showValues enables the debug value
+// column; callers serving reports remotely always pass false. groups maps
+// the members of partially set groups to their detail, see
+// incompleteGroups.
+func reportPaged(w io.Writer, results []Result, pageSize int, between PageFunc, showValues bool, groups map[string]string) (missing int, err error) {
+	if pageSize <= 0 || pageSize > len(results) {
+		pageSize = len(results)
+	}
+	pages := 1
+	if pageSize > 0 {
+		pages = (len(results) + pageSize - 1) / pageSize
+	}
+
+	r := reportRenderer{showValues: showValues, groups: groups}
+	var buf bytes.Buffer
+	buf.Grow((pageSize + 2) * reportRowEstimate)
+
+	for page := 0; page < pages; page++ {
+		if page > 0 && between != nil {
+			if err := between(w, page, pages); err != nil {
+				return r.missing, err
+			}
+		}
+
+		buf.Reset()
+		r.header(&buf)
+		end := min((page+1)*pageSize, len(results))
+		for _, res := range results[page*pageSize : end] {
+			r.row(&buf, res)
+		}
+		if page == pages-1 {
+			r.footer(&buf)
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return r.missing, err
+		}
+	}
+	return r.missing, nil
+}
+
+// reportRenderer renders report rows and accumulates totals.
+type reportRenderer struct {
+	showValues bool
+	groups     map[string]string // see incompleteGroups
+	missing    int
+	overrides  int
+	shadowed   int
+	generated  int
+	deprecated int
+	grace      int
+	pending    int
+	oldest     time.Time // earliest ResolvedAt seen
+	newest     time.Time // latest ResolvedAt seen
+}
+
+func (r *reportRenderer) header(buf *bytes.Buffer) {
+	for i, c := range reportColumns {
+		r.cell(buf, c.title, i)
+	}
+	for i, c := range reportColumns {
+		r.cell(buf, strings.Repeat("-", c.width), i)
+	}
+}
+
+// cell writes one column, padding all but the last and ending the line
+// after the last.
+func (r *reportRenderer) cell(buf *bytes.Buffer, s string, col int) {
+	buf.WriteString(s)
+	if col == len(reportColumns)-1 {
+		buf.WriteByte('\n')
+		return
+	}
+	for n := utf8.RuneCountInString(s); n < reportColumns[col].width; n++ {
+		buf.WriteByte(' ')
+	}
+	buf.WriteByte(' ')
+}
+
+func (r *reportRenderer) row(buf *bytes.Buffer, res Result) {
+	required := "no"
+	if !res.Optional {
+		required = "yes"
+	}
+
+	sensitive := "no"
+	if res.Sensitive {
+		sensitive = "yes"
+	}
+
+	status := resultStatus(res)
+	details := res.Description
+	group, inGroup := r.groups[res.Name]
+	if inGroup && status == StatusOK {
+		status = StatusIncomplete
+	}
+
+	if status == StatusIncomplete {
+		details = "Error: " + group
+	} else if status == StatusMissing {
+		r.missing++
+	} else if status == StatusInvalid || status == StatusTimeout || (status == StatusPending && res.Err != nil) {
+		details = fmt.Sprintf("Error: %v", res.Err)
+		if !res.Optional {
+			r.missing++
+		}
+	} else if r.showValues && res.Present && res.DisplayFunc != nil && !res.NeverShow {
+		// The requirement decides how much of the value to show
+		details = fmt.Sprintf("%s (value: %s)", res.Description, res.DisplayFunc(res.Value))
+	} else if r.showValues && res.Present && !res.Sensitive {
+		// Only show values in debug mode for non-sensitive vars, with any
+		// credentials embedded in URLs masked
+		v := displayValue(res.Value)
+		if utf8.RuneCountInString(v) > 20 {
+			details = fmt.Sprintf("%s (value: %s...)", res.Description, string([]rune(v)[:17]))
+		} else {
+			details = fmt.Sprintf("%s (value: %s)", res.Description, v)
+		}
+	} else if r.showValues && res.Present && res.Sensitive && !res.NeverShow {
+		// Show redacted value for sensitive vars in debug mode
+		if len(res.Value) >= 4 {
+			details = fmt.Sprintf("%s (value: %s%s)", res.Description, styled("••••"), res.Value[len(res.Value)-4:])
+		} else {
+			details = fmt.Sprintf("%s (value: %s)", res.Description, styled("••••"))
+		}
+	}
+
+	if cond := res.condition(); cond != "" {
+		details += " [required if " + cond + "]"
+	}
+	if status == StatusDeprecated {
+		if res.ReplacedBy != "" {
+			details += " [deprecated: rename to " + res.ReplacedBy + "]"
+		} else {
+			details += " [deprecated]"
+		}
+		r.deprecated++
+	} else if res.From != "" {
+		details += " [from " + res.From + "]"
+	}
+	if res.Provenance == ProvenanceLocal {
+		details += " [local override]"
+		r.overrides++
+	}
+	if res.Provenance == ProvenanceGenerated {
+		details += " [generated]"
+		r.generated++
+	}
+	if status == StatusGrace {
+		details += " [required after " + res.RequiredAfter.String() + "]"
+		r.grace++
+	}
+	if status == StatusPending {
+		details += " [checked at first use]"
+		r.pending++
+	}
+	if res.Late {
+		details += " [late registration " + formatTime(res.ResolvedAt) + "]"
+	}
+	if res.ResolvedAt.After(r.newest) {
+		r.newest = res.ResolvedAt
+	}
+	if !res.ResolvedAt.IsZero() && (r.oldest.IsZero() || res.ResolvedAt.Before(r.oldest)) {
+		r.oldest = res.ResolvedAt
+	}
+	if len(res.Shadowed) > 0 {
+		details += " [shadows " + shadowedSources(res.Shadowed) + "]"
+		r.shadowed++
+	}
+
+	for i, s := range [...]string{res.Name, res.Source, required, sensitive, status, details} {
+		r.cell(buf, s, i)
+	}
+}
+
+func (r *reportRenderer) footer(buf *bytes.Buffer) {
+	if !r.newest.IsZero() {
+		fmt.Fprintf(buf, "\nResolved: %s (oldest value resolved %s earlier)\n",
+			formatTime(r.newest), formatDuration(r.newest.Sub(r.oldest)))
+	}
+	if r.overrides > 0 && !IsDevelopment() {
+		fmt.Fprintf(buf, "\nWARNING: %d value(s) come from local overrides outside development profile\n", r.overrides)
+	}
+	if r.deprecated > 0 {
+		fmt.Fprintf(buf, "\nWARNING: %d deprecated variable(s) in use; rename them before they are removed\n", r.deprecated)
+	}
+	if r.grace > 0 {
+		fmt.Fprintf(buf, "\nWARNING: %d variable(s) not set will become required; set them before their cutoff\n", r.grace)
+	}
+	if r.pending > 0 {
+		fmt.Fprintf(buf, "\nWARNING: %d variable(s) checked at first use are missing or invalid; code paths using them will fail\n", r.pending)
+	}
+	if r.generated > 0 {
+		fmt.Fprintf(buf, "\nWARNING: %d value(s) are generated placeholders for development; set real values before deploying\n", r.generated)
+	}
+	if r.shadowed > 0 {
+		fmt.Fprintf(buf, "\nWARNING: %d variable(s) are set in several sources with different values; the highest-precedence source wins\n", r.shadowed)
+	}
+}
+
+// Report statuses of a Result.
+const (
+	StatusOK         = "ok"
+	StatusMissing    = "missing"    // required and not set
+	StatusInvalid    = "invalid"    // set but rejected by its validator
+	StatusTimeout    = "timeout"    // lookup did not finish in time, see ErrTimeout
+	StatusDeprecated = "deprecated" // valid, but set under a deprecated name
+	StatusIncomplete = "incomplete" // set or optional, but in a partially set AllOrNone group
+	StatusGrace      = "grace"      // not set, but required once its RequiredAfter cutoff passes
+	StatusPending    = "pending"    // registered with FirstUse and missing or invalid; fails where it is used
+)
+
+// resultStatus returns the report status of res. A missing optional
+// variable is ok.
+func resultStatus(res Result) string {
+	switch {
+	case res.firstUse && ((!res.Present && !res.Optional) || res.Err != nil):
+		return StatusPending
+	case errors.Is(res.Err, ErrTimeout):
+		return StatusTimeout
+	case !res.Present && !res.Optional:
+		return StatusMissing
+	case !res.Present && res.Grace:
+		return StatusGrace
+	case res.Err != nil:
+		return StatusInvalid
+	case deprecatedInUse(res):
+		return StatusDeprecated
+	}
+	return StatusOK
+}
+
+// shadowedSources lists the provenance of shadowed values for reports.
+func shadowedSources(shadows []Shadow) string {
+	names := make([]string, len(shadows))
+	for i, sh := range shadows {
+		names[i] = sh.Provenance
+	}
+	return strings.Join(names, ", ")
+}
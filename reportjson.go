@@ -0,0 +1,99 @@
+package envreq
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// JSONReport is the document written by ReportJSON.
+type JSONReport struct {
+	Missing   int             `json:"missing"` // missing or invalid required variables
+	Vars      []ReportEntry   `json:"vars"`
+	Providers []ProviderEntry `json:"providers,omitempty"` // health of the source chain, see Providers
+	Build     *BuildInfo      `json:"build,omitempty"`     // see SetBuildInfo
+	Runtime   *RuntimeInfo    `json:"runtime,omitempty"`   // see IncludeRuntimeInfo
+}
+
+// ProviderEntry is the JSON form of a ProviderStatus.
+type ProviderEntry struct {
+	Name         string   `json:"name"`
+	Reachable    bool     `json:"reachable"`
+	Auth         string   `json:"auth,omitempty"` // AuthOK or AuthFailed
+	Lookups      uint64   `json:"lookups"`
+	Errors       uint64   `json:"errors"`
+	LatencyMS    float64  `json:"latencyMs"`              // mean lookup latency
+	CacheHitRate *float64 `json:"cacheHitRate,omitempty"` // 0 to 1; absent when not reported
+	LastError    string   `json:"lastError,omitempty"`
+}
+
+// ReportJSON writes the report as a single-line JSON document, for log
+// pipelines that should not have to parse the table. Like Report it never
+// includes values, sensitive or not. It returns the count of missing
+// required variables and any write error.
+func ReportJSON(w io.Writer, results []Result) (missing int, err error) {
+	rep := jsonReport(results)
+	return rep.Missing, json.NewEncoder(w).Encode(rep)
+}
+
+// ReportJSON writes the JSON report of all results in the registry,
+// including the build information (see SetBuildInfo), the runtime context
+// (see IncludeRuntimeInfo) and the providers section when the source chain
+// holds more than Env. Members of partially set AllOrNone groups have
+// status StatusIncomplete.
+func (g *Registry) ReportJSON(w io.Writer) (missing int, err error) {
+	results := g.CheckAll()
+	rep := jsonReport(results)
+	groups := incompleteGroups(g.constraintErrors(results))
+	for i, e := range rep.Vars {
+		if group, ok := groups[e.Name]; ok && e.Status == StatusOK {
+			rep.Vars[i].Status, rep.Vars[i].Error = StatusIncomplete, group
+			rep.Vars[i].Remediation = remediation(results[i], StatusIncomplete, group)
+		}
+	}
+	if b := g.BuildInfo(); !b.IsZero() {
+		rep.Build = &b
+	}
+	if g.runtimeInfo.Load() {
+		if r := CurrentRuntimeInfo(); !r.IsZero() {
+			rep.Runtime = &r
+		}
+	}
+	if providers := g.Providers(); remoteProviders(providers) {
+		for _, p := range providers {
+			rep.Providers = append(rep.Providers, providerEntry(p))
+		}
+	}
+	return rep.Missing, json.NewEncoder(w).Encode(rep)
+}
+
+// providerEntry converts p to its JSON form.
+func providerEntry(p ProviderStatus) ProviderEntry {
+	e := ProviderEntry{
+		Name:      p.Name,
+		Reachable: p.Reachable,
+		Auth:      p.Auth,
+		Lookups:   p.Lookups,
+		Errors:    p.Errors,
+		LatencyMS: float64(p.Latency) / float64(time.Millisecond),
+	}
+	if rate, ok := p.CacheHitRate(); ok {
+		e.CacheHitRate = &rate
+	}
+	if p.LastError != nil {
+		e.LastError = p.LastError.Error()
+	}
+	return e
+}
+
+// jsonReport builds the JSON form of results.
+func jsonReport(results []Result) JSONReport {
+	rep := JSONReport{Vars: make([]ReportEntry, len(results))}
+	for i, res := range results {
+		rep.Vars[i] = reportEntry(res)
+		if rep.Vars[i].Status != StatusOK && !res.Optional {
+			rep.Missing++
+		}
+	}
+	return rep
+}
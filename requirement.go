@@ -0,0 +1,61 @@
+package envreq
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors returned by Requirement.Verify. They are wrapped, so use errors.Is.
+var (
+	ErrEmptyName        = errors.New("requirement has no Name")
+	ErrInvalidName      = errors.New("requirement Name is not a valid environment variable name")
+	ErrSensitiveDefault = errors.New("required sensitive variable has a Default (a secret in source code)")
+	ErrInvalidDefault   = errors.New("requirement Default does not pass its Validate")
+)
+
+// NewRequirement returns r after checking it with Verify, so contradictory
+// declarations fail where they are written rather than at runtime.
+func NewRequirement(r Requirement) (Requirement, error) {
+	if err := r.Verify(); err != nil {
+		return Requirement{}, err
+	}
+	return r, nil
+}
+
+// Verify checks the declaration itself (not the environment) for mistakes:
+// an empty or malformed Name or alias, a Default on a required Sensitive
+// variable, and a Default that fails its own Validate. All problems are
+// joined.
+//
+// Check runs Verify on first registration and logs any error.
+func (r Requirement) Verify() error {
+	var errs []error
+
+	switch {
+	case r.Name == "":
+		errs = append(errs, ErrEmptyName)
+	case !validName(r.Name):
+		errs = append(errs, fmt.Errorf("%w: %q", ErrInvalidName, r.Name))
+	}
+	for _, alias := range r.Aliases {
+		if alias == "" || !validName(alias) {
+			errs = append(errs, fmt.Errorf("%s: alias: %w: %q", r.Name, ErrInvalidName, alias))
+		}
+	}
+
+	if r.Sensitive && !r.Optional && r.Default != "" {
+		errs = append(errs, fmt.Errorf("%s: %w", r.Name, ErrSensitiveDefault))
+	}
+
+	if err := checkDefault(r); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", r.Name, err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validName reports whether name can be an environment variable name.
+func validName(name string) bool {
+	return !strings.ContainsAny(name, "= \t\n\x00")
+}
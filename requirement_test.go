@@ -0,0 +1,60 @@
+package envreq_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestRequirementVerify(t *testing.T) {
+	tests := []struct {
+		name string
+		req  envreq.Requirement
+		want error
+	}{
+		{"valid", envreq.Requirement{Name: "OK_VAR", Default: "8080", Validate: envreq.Port}, nil},
+		{"empty name", envreq.Requirement{}, envreq.ErrEmptyName},
+		{"bad name", envreq.Requirement{Name: "BAD=NAME"}, envreq.ErrInvalidName},
+		{"sensitive default", envreq.Requirement{Name: "KEY", Sensitive: true, Default: "secret"}, envreq.ErrSensitiveDefault},
+		{"optional sensitive default", envreq.Requirement{Name: "KEY", Sensitive: true, Optional: true, Default: "dev"}, nil},
+		{"invalid default", envreq.Requirement{Name: "URL", Default: "nope", Validate: envreq.URL}, envreq.ErrInvalidDefault},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Verify()
+			if tt.want == nil && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := envreq.NewRequirement(envreq.Requirement{}); !errors.Is(err, envreq.ErrEmptyName) {
+		t.Errorf("Expected NewRequirement to reject an empty name, got %v", err)
+	}
+}
+
+func TestInvalidDefaultFailsEverywhere(t *testing.T) {
+	envreq.Reset()
+	defer envreq.Reset()
+	t.Setenv("CALLBACK_URL", "https://example.com/hook")
+
+	res := envreq.Check(envreq.Requirement{
+		Name:     "CALLBACK_URL",
+		Source:   "hooks",
+		Default:  "example.com/hook",
+		Validate: envreq.URL,
+	})
+	if !errors.Is(res.Err, envreq.ErrInvalidDefault) {
+		t.Errorf("Expected invalid default to fail even when env is valid, got %v", res.Err)
+	}
+
+	issues := envreq.Lint()
+	if len(issues) != 1 || issues[0].Name != "CALLBACK_URL" {
+		t.Errorf("Expected Lint to flag CALLBACK_URL, got %v", issues)
+	}
+}
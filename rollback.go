@@ -0,0 +1,103 @@
+package envreq
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// schemaFetchTimeout bounds fetching a schema over HTTP.
+const schemaFetchTimeout = 10 * time.Second
+
+// LoadSchema reads a schema written by WriteSchema from a file path or an
+// http(s) URL, e.g. the schema published with the previous release.
+func LoadSchema(location string) (Schema, error) {
+	var r io.ReadCloser
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		client := http.Client{Timeout: schemaFetchTimeout}
+		resp, err := client.Get(location)
+		if err != nil {
+			return Schema{}, fmt.Errorf("envreq: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return Schema{}, fmt.Errorf("envreq: GET %s: %s", location, resp.Status)
+		}
+		r = resp.Body
+	} else {
+		f, err := openFile(location)
+		if err != nil {
+			return Schema{}, fmt.Errorf("envreq: %w", err)
+		}
+		r = f
+	}
+	defer r.Close()
+
+	return ReadSchema(r)
+}
+
+// RollbackError lists the required variables of another release's schema
+// that the current environment does not satisfy.
+type RollbackError struct {
+	Problems []Problem
+}
+
+func (e *RollbackError) Error() string {
+	names := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		names[i] = p.String()
+	}
+	return fmt.Sprintf("envreq: rollback would fail: %d required variable(s) of the previous release missing or invalid: %s",
+		len(e.Problems), strings.Join(names, ", "))
+}
+
+// CheckRollback evaluates the variables of schema s, typically the previous
+// release's, against the current sources and returns their results with a
+// *RollbackError if a rollback to that release would start with missing
+// required variables. Each variable is read as the schema declares it, with
+// its aliases, replacement and the validators SchemaVar.Requirement can
+// resolve; variables also registered in the running release are validated
+// with their current validator instead. The registry is not modified.
+//
+// MustValidate runs it when ENVREQ_ROLLBACK_SCHEMA names a schema file or
+// URL, and reports the outcome without failing.
+func CheckRollback(s Schema) ([]Result, error) {
+	return std.CheckRollback(s)
+}
+
+// CheckRollback evaluates schema s against the registry's sources. See the
+// package-level CheckRollback.
+func (g *Registry) CheckRollback(s Schema) ([]Result, error) {
+	results := make([]Result, len(s.Vars))
+	for i, v := range s.Vars {
+		r := v.Requirement()
+		g.mu.RLock()
+		if cur, ok := g.reg[v.Name]; ok && cur.Validate != nil {
+			r.Validate = cur.Validate
+		}
+		g.mu.RUnlock()
+		results[i] = g.evaluate(r)
+	}
+
+	if p := problems(results); len(p) > 0 {
+		return results, &RollbackError{Problems: p}
+	}
+	return results, nil
+}
+
+// reportRollback writes whether the environment satisfies the schema at
+// location, for MustValidate.
+func (g *Registry) reportRollback(w io.Writer, location string) {
+	s, err := LoadSchema(location)
+	if err != nil {
+		fmt.Fprintf(w, "\nWARNING: cannot check rollback safety: %v\n", err)
+		return
+	}
+	if _, err := g.CheckRollback(s); err != nil {
+		fmt.Fprintf(w, "\nWARNING: %v\n", err)
+		return
+	}
+	fmt.Fprintf(w, "\nRollback: the environment satisfies the previous release's schema (%d vars)\n", len(s.Vars))
+}
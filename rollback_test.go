@@ -0,0 +1,76 @@
+package envreq_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestCheckRollback(t *testing.T) {
+	t.Setenv("RB_DATABASE_URL", "postgres://db/app")
+	t.Setenv("RB_PORT", "not-a-port")
+
+	g := envreq.New()
+	g.Check(envreq.Requirement{Name: "RB_DATABASE_URL", Source: "db"})
+	g.Check(envreq.Requirement{Name: "RB_PORT", Source: "server", Optional: true, Default: "8080", Validate: envreq.Port})
+
+	previous := envreq.Schema{Version: envreq.SchemaVersion, Vars: []envreq.SchemaVar{
+		{Name: "RB_DATABASE_URL", Required: true},
+		{Name: "RB_LEGACY_TOKEN", Required: true}, // removed in this release, still needed by the old one
+		{Name: "RB_PORT", Required: true},         // optional now, required before
+		{Name: "RB_OLD_OPTIONAL", Required: false},
+	}}
+
+	_, err := g.CheckRollback(previous)
+	var rb *envreq.RollbackError
+	if !errors.As(err, &rb) {
+		t.Fatalf("Expected a *RollbackError, got %v", err)
+	}
+	if len(rb.Problems) != 2 || rb.Problems[0].Name != "RB_LEGACY_TOKEN" || !rb.Problems[0].Missing ||
+		rb.Problems[1].Name != "RB_PORT" || rb.Problems[1].Err == nil {
+		t.Errorf("Unexpected problems: %+v", rb.Problems)
+	}
+
+	// The previous release read the same variables under other names
+	t.Setenv("RB_OLD_DB", "postgres://db/app")
+	t.Setenv("RB_SMTP_HOST", "mail.internal")
+	renamed := envreq.Schema{Version: envreq.SchemaVersion, Vars: []envreq.SchemaVar{
+		{Name: "RB_DB_URL", Required: true, Aliases: []string{"RB_OLD_DB"}, Validator: "envreq.URL"},
+		{Name: "RB_MAIL_HOST", Required: true, Deprecated: true, ReplacedBy: "RB_SMTP_HOST"},
+	}}
+	if _, err := g.CheckRollback(renamed); err != nil {
+		t.Errorf("Expected aliases and replacements honored, got %v", err)
+	}
+	t.Setenv("RB_OLD_DB", "not a url")
+	if _, err := g.CheckRollback(renamed); !errors.As(err, &rb) || len(rb.Problems) != 1 || rb.Problems[0].Err == nil {
+		t.Errorf("Expected the schema's named validator applied, got %v", err)
+	}
+
+	t.Setenv("RB_LEGACY_TOKEN", "x")
+	t.Setenv("RB_PORT", "9090")
+	if _, err := g.CheckRollback(previous); err != nil {
+		t.Errorf("Expected the rollback to be safe, got %v", err)
+	}
+}
+
+func TestLoadSchemaURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v41/schema.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"version":1,"vars":[{"name":"RB_REMOTE","required":true,"sensitive":false,"validated":false}]}`))
+	}))
+	defer srv.Close()
+
+	s, err := envreq.LoadSchema(srv.URL + "/v41/schema.json")
+	if err != nil || len(s.Vars) != 1 || s.Vars[0].Name != "RB_REMOTE" {
+		t.Errorf("LoadSchema = %+v, %v", s, err)
+	}
+	if _, err := envreq.LoadSchema(srv.URL + "/missing.json"); err == nil {
+		t.Error("Expected an error for a 404")
+	}
+}
@@ -0,0 +1,83 @@
+package envreq
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// RuntimeInfo is the deployment location of the process, included in
+// exports with IncludeRuntimeInfo so that fleet-wide configuration
+// inventories can be navigated by where each report came from.
+type RuntimeInfo struct {
+	Hostname  string `json:"hostname,omitempty"`
+	Pod       string `json:"pod,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Region    string `json:"region,omitempty"`
+}
+
+// IsZero reports whether no field is set.
+func (r RuntimeInfo) IsZero() bool {
+	return r == RuntimeInfo{}
+}
+
+// Well-known variables holding the runtime context, first set wins.
+var (
+	podVars       = []string{"POD_NAME", "K8S_POD_NAME", "MY_POD_NAME"}
+	namespaceVars = []string{"POD_NAMESPACE", "K8S_NAMESPACE", "MY_POD_NAMESPACE", "NAMESPACE"}
+	regionVars    = []string{"REGION", "AWS_REGION", "AWS_DEFAULT_REGION", "GOOGLE_CLOUD_REGION", "CLOUD_RUN_REGION", "AZURE_REGION", "FLY_REGION"}
+)
+
+// namespaceFile is where Kubernetes mounts the pod's namespace.
+const namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// CurrentRuntimeInfo reads the runtime context: the host name, and the pod
+// name, namespace and region from well-known environment variables
+// (POD_NAME, POD_NAMESPACE, AWS_REGION, GOOGLE_CLOUD_REGION, ...). Inside
+// Kubernetes the pod name falls back to HOSTNAME and the namespace to the
+// service account mount. The variables are read from the process
+// environment directly; they describe the machine, not the configuration.
+func CurrentRuntimeInfo() RuntimeInfo {
+	var r RuntimeInfo
+	r.Hostname, _ = os.Hostname()
+	r.Pod = firstEnv(podVars)
+	r.Namespace = firstEnv(namespaceVars)
+	r.Region = firstEnv(regionVars)
+
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		if r.Pod == "" {
+			r.Pod = os.Getenv("HOSTNAME")
+		}
+		if r.Namespace == "" {
+			if f, err := openFile(namespaceFile); err == nil {
+				b, _ := io.ReadAll(io.LimitReader(f, 256))
+				f.Close()
+				r.Namespace = strings.TrimSpace(string(b))
+			}
+		}
+	}
+	return r
+}
+
+// firstEnv returns the value of the first set variable of names.
+func firstEnv(names []string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// IncludeRuntimeInfo adds CurrentRuntimeInfo to the exports of the default
+// registry meant for aggregation: the JSON report (runtime) and snapshots.
+// It is off by default, as host and pod names describe the infrastructure.
+func IncludeRuntimeInfo(on bool) {
+	std.IncludeRuntimeInfo(on)
+}
+
+// IncludeRuntimeInfo adds CurrentRuntimeInfo to the registry's exports.
+// See the package-level IncludeRuntimeInfo.
+func (g *Registry) IncludeRuntimeInfo(on bool) {
+	g.runtimeInfo.Store(on)
+}
@@ -0,0 +1,245 @@
+package envreq
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// SchemaVersion is the version written to Schema.Version.
+const SchemaVersion = 1
+
+// Schema is a serializable description of the registered requirements. It
+// never contains values, and omits defaults of sensitive variables.
+type Schema struct {
+	Version int         `json:"version"`
+	Vars    []SchemaVar `json:"vars"`
+}
+
+// SchemaVar is the serializable form of a Requirement.
+type SchemaVar struct {
+	Name             string   `json:"name"`
+	Source           string   `json:"source,omitempty"`
+	Description      string   `json:"description,omitempty"`
+	Owner            string   `json:"owner,omitempty"`
+	Example          string   `json:"example,omitempty"`
+	DocsURL          string   `json:"docsUrl,omitempty"`
+	Default          string   `json:"default,omitempty"`
+	DefaultFunc      string   `json:"defaultFunc,omitempty"` // function computing the default, e.g. "os.Hostname"; "custom" when unnamed
+	Required         bool     `json:"required"`
+	Sensitive        bool     `json:"sensitive"`
+	NeverShow        bool     `json:"neverShow,omitempty"`        // no value details even in debug output
+	Validated        bool     `json:"validated"`                  // a validator is attached
+	Validator        string   `json:"validator,omitempty"`        // validator function name, e.g. "envreq.URL"
+	List             bool     `json:"list,omitempty"`             // comma-separated value
+	ElementValidator string   `json:"elementValidator,omitempty"` // validator of each List element, e.g. "envreq.HostPort"
+	DeepValidator    string   `json:"deepValidator,omitempty"`    // DeepValidate function name; "custom" when unnamed
+	RequiredIf       string   `json:"requiredIf,omitempty"`       // condition making the var required, e.g. "APP_ENV=production"
+	RequiredAfter    string   `json:"requiredAfter,omitempty"`    // grace period cutoff, e.g. "2025-03-01" or "version 2.0.0"
+	Deprecated       bool     `json:"deprecated,omitempty"`
+	ReplacedBy       string   `json:"replacedBy,omitempty"` // name replacing a deprecated var
+	Aliases          []string `json:"aliases,omitempty"`    // other names tried when Name is unset
+	External         bool     `json:"external,omitempty"`   // read by child processes, not this binary
+}
+
+// Describe returns the schema of all registered requirements, sorted by name.
+// It does not load or validate any values.
+func Describe() Schema {
+	return std.Describe()
+}
+
+// Describe returns the schema of the registry's requirements.
+func (g *Registry) Describe() Schema {
+	g.mu.RLock()
+	vars := make([]SchemaVar, 0, len(g.reg))
+	for _, r := range g.reg {
+		vars = append(vars, schemaVar(r))
+	}
+	g.mu.RUnlock()
+
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].Name < vars[j].Name
+	})
+	return Schema{Version: SchemaVersion, Vars: vars}
+}
+
+// schemaVar converts r to its serializable form.
+func schemaVar(r Requirement) SchemaVar {
+	v := SchemaVar{
+		Name:             r.Name,
+		Source:           r.Source,
+		Description:      r.Description,
+		Owner:            r.Owner,
+		Example:          r.Example,
+		DocsURL:          r.DocsURL,
+		Required:         !r.Optional && !r.conditional(),
+		Sensitive:        r.Sensitive,
+		NeverShow:        r.NeverShow,
+		Validated:        r.Validate != nil,
+		Validator:        validatorName(r.Validate),
+		List:             r.List,
+		ElementValidator: validatorName(r.ElementValidator),
+		RequiredIf:       r.condition(),
+		RequiredAfter:    r.RequiredAfter.String(),
+		Deprecated:       r.Deprecated,
+		ReplacedBy:       r.ReplacedBy,
+		Aliases:          r.Aliases,
+		External:         r.External,
+	}
+	if !r.Sensitive {
+		v.Default = r.Default
+	}
+	if r.DefaultFunc != nil {
+		v.DefaultFunc = funcName(reflect.ValueOf(r.DefaultFunc).Pointer())
+		if v.DefaultFunc == "" {
+			v.DefaultFunc = "custom"
+		}
+	}
+	if r.DeepValidate != nil {
+		v.DeepValidator = validatorName(r.DeepValidate)
+		if v.DeepValidator == "" {
+			v.DeepValidator = "custom"
+		}
+	}
+	return v
+}
+
+// Requirement returns the Requirement v describes, as far as a schema can,
+// for tools that check environments against a schema file. Validator and
+// ElementValidator are resolved when they name a validator available as
+// an envreq struct tag, e.g. "envreq.URL"; custom functions and factories
+// such as envreq.MinLen cannot be rebuilt from a name and are left out.
+// Required is taken as is, without its RequiredIf condition, and
+// DefaultFunc, DeepValidator and External are not carried over.
+func (v SchemaVar) Requirement() Requirement {
+	return Requirement{
+		Name:             v.Name,
+		Source:           v.Source,
+		Description:      v.Description,
+		Owner:            v.Owner,
+		Example:          v.Example,
+		DocsURL:          v.DocsURL,
+		Default:          v.Default,
+		Optional:         !v.Required,
+		Sensitive:        v.Sensitive,
+		NeverShow:        v.NeverShow,
+		Validate:         namedValidators()[v.Validator],
+		List:             v.List,
+		ElementValidator: namedValidators()[v.ElementValidator],
+		Deprecated:       v.Deprecated,
+		ReplacedBy:       v.ReplacedBy,
+		Aliases:          v.Aliases,
+	}
+}
+
+// namedValidators maps the validatorName of the struct tag validators to
+// the validators.
+var namedValidators = sync.OnceValue(func() map[string]func(string) error {
+	m := make(map[string]func(string) error, len(tagValidators))
+	for _, fn := range tagValidators {
+		m[validatorName(fn)] = fn
+	}
+	return m
+})
+
+// validatorName returns the package-qualified name of fn, e.g.
+// "envreq.URL", with closure suffixes dropped so that validators built by
+// factories are named after the factory ("envreq.OneOf"). It returns ""
+// for nil and for other function literals.
+func validatorName(fn func(string) error) string {
+	if fn == nil {
+		return ""
+	}
+	return funcName(reflect.ValueOf(fn).Pointer())
+}
+
+// funcName names the function at pc like validatorName.
+func funcName(pc uintptr) string {
+	f := runtime.FuncForPC(pc)
+	if f == nil {
+		return ""
+	}
+	name := strings.TrimSuffix(f.Name(), "-fm")
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	closure := false
+	for {
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 || !strings.HasPrefix(name[i+1:], "func") {
+			break
+		}
+		name, closure = name[:i], true
+	}
+	if closure {
+		// Only exported factories name their closures usefully; a literal
+		// in main or init would be reported as "main.main"
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 || i == len(name)-1 || !unicode.IsUpper(rune(name[i+1])) {
+			return ""
+		}
+	}
+	return name
+}
+
+// WriteSchema writes the registry schema as indented JSON.
+func WriteSchema(w io.Writer) error {
+	return std.WriteSchema(w)
+}
+
+// WriteSchema writes the registry schema as indented JSON.
+func (g *Registry) WriteSchema(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g.Describe())
+}
+
+// describeMode handles ENVREQ_DESCRIBE. When set to "1" the schema is written
+// to stdout; any other value is used as the output file path. It reports
+// whether describe mode was requested.
+func (g *Registry) describeMode() bool {
+	target := os.Getenv("ENVREQ_DESCRIBE")
+	if target == "" {
+		return false
+	}
+
+	var err error
+	if target == "1" {
+		err = g.WriteSchema(os.Stdout)
+	} else {
+		var f io.WriteCloser
+		if f, err = createFile(target); err == nil {
+			err = g.WriteSchema(f)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "envreq: describe: %v\n", err)
+	}
+	return true
+}
+
+// ReadSchema decodes a schema previously written by WriteSchema. With
+// CompactMemory enabled on the default registry, metadata strings are interned.
+func ReadSchema(r io.Reader) (Schema, error) {
+	var s Schema
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return Schema{}, fmt.Errorf("envreq: invalid schema: %w", err)
+	}
+	if s.Version > SchemaVersion {
+		return Schema{}, fmt.Errorf("envreq: unsupported schema version %d", s.Version)
+	}
+	if std.compact.Load() {
+		std.internSchema(&s)
+	}
+	return s, nil
+}
@@ -0,0 +1,194 @@
+//go:build !envreq_nofiles
+
+package envreq_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestSchemaRoundTripAndScore(t *testing.T) {
+	envreq.Reset()
+	defer envreq.Reset()
+
+	envreq.Check(envreq.Requirement{
+		Name:        "DOC_URL",
+		Source:      "docs",
+		Description: "Documentation site",
+		Owner:       "team-docs",
+		Example:     "https://docs.example.com",
+		Validate:    envreq.URL,
+	})
+	envreq.Check(envreq.Requirement{
+		Name:      "DOC_TOKEN",
+		Source:    "docs",
+		Optional:  true,
+		Default:   "dev-token",
+		Sensitive: true,
+	})
+
+	var buf bytes.Buffer
+	if err := envreq.WriteSchema(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "dev-token") {
+		t.Error("Schema must not contain sensitive defaults")
+	}
+
+	schema, err := envreq.ReadSchema(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Vars) != 2 || schema.Vars[1].Name != "DOC_URL" || !schema.Vars[1].Required {
+		t.Fatalf("Unexpected schema: %+v", schema)
+	}
+
+	sc := schema.Score()
+	// DOC_URL earns 4 points, DOC_TOKEN earns none
+	if got := sc.Ratio(); got != 0.5 {
+		t.Errorf("Expected ratio 0.5, got %v (%s)", got, sc)
+	}
+
+	buf.Reset()
+	if err := sc.WriteBadge(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"message":"50%"`) {
+		t.Errorf("Unexpected badge: %s", buf.String())
+	}
+
+	// Back from the schema, named validators are resolved
+	r := schema.Vars[1].Requirement()
+	if r.Validate == nil || r.Validate("nope") == nil || r.Validate("https://docs.example.com") != nil || r.Optional || r.Owner != "team-docs" {
+		t.Errorf("Expected DOC_URL with envreq.URL, got %+v", r)
+	}
+	if r := (envreq.SchemaVar{Name: "DOC_NAME", Validator: "envreq.MinLen"}).Requirement(); r.Validate != nil {
+		t.Error("Expected a factory validator not to be resolved")
+	}
+}
+
+func TestVerifySchema(t *testing.T) {
+	envreq.Reset()
+	defer envreq.Reset()
+
+	envreq.Check(envreq.Requirement{Name: "KEEP_VAR", Source: "test", Optional: true})
+	envreq.Check(envreq.Requirement{Name: "FLIP_VAR", Source: "test", Optional: true})
+
+	path := filepath.Join(t.TempDir(), "schema.json")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envreq.WriteSchema(f)
+	f.Close()
+
+	if err := envreq.VerifySchema(path); err != nil {
+		t.Fatalf("Expected matching schema, got %v", err)
+	}
+
+	envreq.Reset()
+	envreq.Check(envreq.Requirement{Name: "KEEP_VAR", Source: "test", Optional: true})
+	envreq.Check(envreq.Requirement{Name: "FLIP_VAR", Source: "test"})
+	envreq.Check(envreq.Requirement{Name: "NEW_VAR", Source: "test", Optional: true})
+
+	var drift *envreq.SchemaDriftError
+	if err := envreq.VerifySchema(path); !errors.As(err, &drift) {
+		t.Fatalf("Expected SchemaDriftError, got %v", err)
+	}
+	if len(drift.Unexpected) != 1 || drift.Unexpected[0] != "NEW_VAR" {
+		t.Errorf("Expected NEW_VAR unexpected, got %v", drift.Unexpected)
+	}
+	if len(drift.Changed) != 1 || !strings.HasPrefix(drift.Changed[0], "FLIP_VAR") {
+		t.Errorf("Expected FLIP_VAR changed, got %v", drift.Changed)
+	}
+
+	envreq.Reset()
+	envreq.Check(envreq.Requirement{Name: "KEEP_VAR", Source: "test", Optional: true})
+	if err := envreq.VerifySchema(path); !errors.As(err, &drift) || len(drift.Unregistered) != 1 {
+		t.Errorf("Expected FLIP_VAR unregistered, got %v", err)
+	}
+}
+
+func TestCompactMemory(t *testing.T) {
+	envreq.Reset()
+	defer envreq.Reset()
+	envreq.CompactMemory(true)
+	defer envreq.CompactMemory(false)
+
+	envreq.Check(envreq.Requirement{Name: "COMPACT_A", Source: "shared", Optional: true})
+	res := envreq.Check(envreq.Requirement{Name: "COMPACT_A", Source: "shared", Sensitive: true})
+
+	// The cached result reflects the current (merged) registry entry
+	if !res.Sensitive || res.Optional {
+		t.Errorf("Expected merged requirement in cached result, got %+v", res.Requirement)
+	}
+
+	schema, err := envreq.ReadSchema(strings.NewReader(`{"version":1,"vars":[{"name":"X","source":"shared"},{"name":"Y","source":"shared"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.Vars[0].Source != "shared" || schema.Vars[1].Source != "shared" {
+		t.Errorf("Unexpected schema after interning: %+v", schema.Vars)
+	}
+}
+
+func TestWriteExample(t *testing.T) {
+	g := envreq.New()
+	g.Check(envreq.Requirement{Name: "EX_DB_URL", Source: "database", Description: "PostgreSQL connection string", Example: "postgres://localhost/app", Validate: envreq.URL})
+	g.Check(envreq.Requirement{Name: "EX_API_KEY", Source: "auth", Description: "External API key", Sensitive: true, Optional: true, Default: "dev-key"})
+	g.Check(envreq.Requirement{Name: "EX_PORT", Source: "server", Optional: true, Default: "8080"})
+
+	var buf bytes.Buffer
+	if err := g.WriteExample(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# --- database ---\n\n# PostgreSQL connection string\n# REQUIRED\nEX_DB_URL=postgres://localhost/app\n",
+		"# External API key\n# SENSITIVE\nEX_API_KEY=\n",
+		"EX_PORT=8080\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Example lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "dev-key") {
+		t.Errorf("Sensitive default leaked:\n%s", out)
+	}
+	if strings.Index(out, "EX_API_KEY") > strings.Index(out, "EX_DB_URL") {
+		t.Errorf("Expected variables grouped by source in order:\n%s", out)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	g := envreq.New()
+	g.Check(envreq.Requirement{Name: "MD_DB_URL", Source: "database", Description: "Primary | replica DSN", Validate: envreq.URL})
+	g.Check(envreq.Requirement{Name: "MD_MODE", Source: "app", Optional: true, Default: "live", Validate: envreq.OneOf("live", "test")})
+	g.Check(envreq.Requirement{Name: "MD_KEY", Source: "app", Optional: true, Default: "dev-key", Sensitive: true})
+
+	var buf bytes.Buffer
+	if err := g.WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"### app\n\n| Variable |",
+		"| `MD_MODE` | no | `live` | `envreq.OneOf` |  |\n",
+		"| `MD_KEY` | no | *(sensitive)* |  |  |\n",
+		"### database\n",
+		"| `MD_DB_URL` | yes |  | `envreq.URL` | Primary \\| replica DSN |\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Markdown lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "dev-key") {
+		t.Errorf("Sensitive default leaked:\n%s", out)
+	}
+}
@@ -0,0 +1,76 @@
+package envreq
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Score measures how completely the configuration surface is annotated.
+// Each variable can earn four points: a description, a validator, an owner,
+// and an example (or non-sensitive default).
+type Score struct {
+	Vars      int // number of variables scored
+	Described int // vars with a Description
+	Validated int // vars with a Validate func
+	Owned     int // vars with an Owner
+	Examples  int // vars with an Example or a non-sensitive Default
+}
+
+// Score computes the completeness score of the schema.
+func (s Schema) Score() Score {
+	sc := Score{Vars: len(s.Vars)}
+	for _, v := range s.Vars {
+		if v.Description != "" {
+			sc.Described++
+		}
+		if v.Validated {
+			sc.Validated++
+		}
+		if v.Owner != "" {
+			sc.Owned++
+		}
+		if v.Example != "" || v.Default != "" {
+			sc.Examples++
+		}
+	}
+	return sc
+}
+
+// Ratio returns the fraction of points earned, from 0 to 1. An empty
+// schema scores 1.
+func (sc Score) Ratio() float64 {
+	if sc.Vars == 0 {
+		return 1
+	}
+	earned := sc.Described + sc.Validated + sc.Owned + sc.Examples
+	return float64(earned) / float64(4*sc.Vars)
+}
+
+// String renders a one-line human summary.
+func (sc Score) String() string {
+	return fmt.Sprintf("%.0f%% (%d vars: %d described, %d validated, %d owned, %d with examples)",
+		sc.Ratio()*100, sc.Vars, sc.Described, sc.Validated, sc.Owned, sc.Examples)
+}
+
+// WriteBadge writes a shields.io endpoint badge JSON document for the score,
+// e.g. for https://img.shields.io/endpoint?url=...
+func (sc Score) WriteBadge(w io.Writer) error {
+	pct := sc.Ratio() * 100
+	color := "red"
+	switch {
+	case pct >= 90:
+		color = "brightgreen"
+	case pct >= 75:
+		color = "green"
+	case pct >= 50:
+		color = "yellow"
+	}
+
+	return json.NewEncoder(w).Encode(map[string]any{
+		"schemaVersion": 1,
+		"label":         "config docs",
+		"message":       fmt.Sprintf("%.0f%%", pct),
+		"color":         color,
+	})
+}
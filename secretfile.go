@@ -0,0 +1,80 @@
+package envreq
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FileSuffix is appended to a variable name to find the variable naming a
+// file that holds its value, the Docker/Kubernetes secrets convention:
+// STRIPE_API_KEY_FILE=/run/secrets/stripe.
+const FileSuffix = "_FILE"
+
+// maxSecretFileSize bounds the size of a NAME_FILE file.
+const maxSecretFileSize = 1 << 20
+
+// fileValue reads the value of name from the file named by name+FileSuffix,
+// trimming one trailing newline. It is a layer below the source chain, so
+// a value set directly wins (and the file is reported as shadowed).
+//
+// The file name is only looked up where files are named: local overrides,
+// the local sources of the chain (see localSource) and dotenv files. A
+// remote store would cost a round trip per variable for a name it never
+// holds.
+func (g *Registry) fileValue(name string) (string, bool, error) {
+	g.mu.RLock()
+	sources := g.sources
+	g.mu.RUnlock()
+
+	path, ok := g.localOverride(name + FileSuffix)
+	for _, s := range sources {
+		if ok {
+			break
+		}
+		if !localSource(s) {
+			continue
+		}
+		var err error
+		if path, ok, err = s.Lookup(name + FileSuffix); err != nil {
+			return "", false, err
+		}
+	}
+	if !ok {
+		path, ok = g.dotenvValue(name + FileSuffix)
+	}
+	if !ok || path == "" {
+		return "", false, nil
+	}
+
+	f, err := openFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+
+	// Read one byte past the limit: a truncated secret is a wrong secret
+	data, err := io.ReadAll(io.LimitReader(f, maxSecretFileSize+1))
+	if err != nil {
+		return "", false, err
+	}
+	if len(data) > maxSecretFileSize {
+		return "", false, fmt.Errorf("%s is larger than %d bytes", path, maxSecretFileSize)
+	}
+	v := strings.TrimSuffix(string(data), "\n")
+	v = strings.TrimSuffix(v, "\r")
+	return v, true, nil
+}
+
+// localSource reports whether s is in process: the environment or a
+// MapSource.
+func localSource(s Source) bool {
+	if n, ok := s.(named); ok {
+		if n.name == ProvenanceEnv {
+			return true
+		}
+		s = n.Source
+	}
+	_, ok := s.(MapSource)
+	return ok
+}
@@ -0,0 +1,75 @@
+//go:build !envreq_nofiles
+
+package envreq_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestSecretFile(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "stripe")
+	if err := os.WriteFile(secret, []byte("sk_live_fromfile\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FILE_STRIPE_KEY_FILE", secret)
+	t.Setenv("FILE_BAD_URL_FILE", secret)
+	t.Setenv("FILE_GONE_FILE", filepath.Join(dir, "missing"))
+
+	g := envreq.New()
+	res := g.Check(envreq.Requirement{Name: "FILE_STRIPE_KEY", Source: "test", Sensitive: true})
+	if res.Value != "sk_live_fromfile" || res.Provenance != envreq.ProvenanceFile {
+		t.Errorf("Expected the trimmed file contents from file, got %q from %s", res.Value, res.Provenance)
+	}
+
+	if res := g.Check(envreq.Requirement{Name: "FILE_BAD_URL", Source: "test", Validate: envreq.URL}); res.Err == nil {
+		t.Error("Expected file contents to be validated")
+	}
+	if res := g.Check(envreq.Requirement{Name: "FILE_GONE", Source: "test"}); res.Present || res.Err == nil {
+		t.Errorf("Expected an error for a missing secret file, got %+v", res)
+	}
+
+	// A value set directly wins over the file
+	t.Setenv("FILE_DIRECT", "direct")
+	t.Setenv("FILE_DIRECT_FILE", secret)
+	res = g.Check(envreq.Requirement{Name: "FILE_DIRECT", Source: "test"})
+	if res.Value != "direct" || len(res.Shadowed) != 1 || res.Shadowed[0].Provenance != envreq.ProvenanceFile {
+		t.Errorf("Expected the direct value to shadow the file, got %q %+v", res.Value, res.Shadowed)
+	}
+	t.Setenv("FILE_DIRECT_KEY", "direct")
+	t.Setenv("FILE_DIRECT_KEY_FILE", secret)
+	res = g.Check(envreq.Requirement{Name: "FILE_DIRECT_KEY", Source: "test", Sensitive: true})
+	if len(res.Shadowed) != 1 || res.Shadowed[0].Fingerprint != "" {
+		t.Errorf("Expected a shadowed secret recorded without a fingerprint, got %+v", res.Shadowed)
+	}
+
+	// A file over the limit is an error, not a truncated secret
+	big := filepath.Join(dir, "big")
+	if err := os.WriteFile(big, bytes.Repeat([]byte("k"), 1<<20+1), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FILE_BIG_FILE", big)
+	if res := g.Check(envreq.Requirement{Name: "FILE_BIG", Source: "test"}); res.Present || res.Err == nil {
+		t.Errorf("Expected an error for an oversized secret file, got present=%v err=%v", res.Present, res.Err)
+	}
+}
+
+func TestSecretFileRemote(t *testing.T) {
+	var asked []string
+	remote := envreq.NamedSource("vault", envreq.SourceFunc(func(name string) (string, bool, error) {
+		asked = append(asked, name)
+		return "", false, nil
+	}))
+	g := envreq.New()
+	g.SetSources(envreq.Env, remote)
+	g.Check(envreq.Requirement{Name: "FILE_REMOTE_KEY", Source: "test", Optional: true})
+	if !slices.Equal(asked, []string{"FILE_REMOTE_KEY"}) {
+		t.Errorf("Expected the remote source asked for the variable only, got %v", asked)
+	}
+}
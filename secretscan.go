@@ -0,0 +1,112 @@
+package envreq
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"strings"
+)
+
+// ErrLooksLikeSecret is reported by Lint and MustValidate for a variable
+// that is not Sensitive but whose value looks like a credential, so that
+// reports and snapshots would expose it.
+var ErrLooksLikeSecret = errors.New("value looks like a credential; mark the variable Sensitive")
+
+// secretPrefixes are prefixes of well-known credential formats.
+var secretPrefixes = []struct{ prefix, what string }{
+	{"sk_live_", "Stripe secret key"},
+	{"rk_live_", "Stripe restricted key"},
+	{"whsec_", "Stripe webhook secret"},
+	{"AKIA", "AWS access key ID"},
+	{"ASIA", "AWS temporary access key ID"},
+	{"ghp_", "GitHub token"},
+	{"gho_", "GitHub token"},
+	{"ghs_", "GitHub token"},
+	{"ghu_", "GitHub token"},
+	{"github_pat_", "GitHub token"},
+	{"glpat-", "GitLab token"},
+	{"xoxb-", "Slack token"},
+	{"xoxp-", "Slack token"},
+	{"AIza", "Google API key"},
+	{"SG.", "SendGrid API key"},
+	{"-----BEGIN", "PEM block"},
+}
+
+// looksLikeSecret reports whether v resembles a credential, and why: a
+// known token prefix, a URL with a password, or a long high-entropy token.
+// It errs on the side of silence; identifiers, paths and ordinary URLs are
+// not flagged.
+func looksLikeSecret(v string) (string, bool) {
+	v = strings.TrimSpace(v)
+	for _, p := range secretPrefixes {
+		// Require some payload so that e.g. "AKIA" alone is not flagged
+		if strings.HasPrefix(v, p.prefix) && len(v) >= len(p.prefix)+12 {
+			return p.what, true
+		}
+	}
+
+	if strings.Contains(v, "://") {
+		if u, err := url.Parse(v); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				return "URL with a password", true
+			}
+		}
+		return "", false
+	}
+
+	if strings.ContainsAny(v, " \t\n") || strings.HasPrefix(v, "/") || strings.HasPrefix(v, ".") {
+		return "", false
+	}
+	hex := strings.Trim(v, "0123456789abcdefABCDEF") == ""
+	switch {
+	case hex && len(v) >= 32 && entropy(v) >= 3.5:
+		return "high-entropy hex string", true
+	case !hex && len(v) >= 20 && entropy(v) >= 4.0 && strings.ContainsAny(v, "0123456789"):
+		return "high-entropy string", true
+	}
+	return "", false
+}
+
+// entropy returns the Shannon entropy of s in bits per byte.
+func entropy(s string) float64 {
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	var h float64
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / float64(len(s))
+			h -= p * math.Log2(p)
+		}
+	}
+	return h
+}
+
+// secretIssue returns a LintIssue when r is not Sensitive and value looks
+// like a credential.
+func secretIssue(r Requirement, value string) (LintIssue, bool) {
+	if r.Sensitive || value == "" {
+		return LintIssue{}, false
+	}
+	what, ok := looksLikeSecret(value)
+	if !ok {
+		return LintIssue{}, false
+	}
+	return LintIssue{Name: r.Name, Source: r.Source, Err: fmt.Errorf("%w (%s)", ErrLooksLikeSecret, what)}, true
+}
+
+// reportSecrets warns on w about non-sensitive results whose values look
+// like credentials.
+func reportSecrets(w io.Writer, results []Result) {
+	for _, res := range results {
+		if !res.Present {
+			continue
+		}
+		if issue, ok := secretIssue(res.Requirement, res.Value); ok {
+			fmt.Fprintf(w, "\nWARNING: %s (from %s): %v\n", issue.Name, issue.Source, issue.Err)
+		}
+	}
+}
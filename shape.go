@@ -0,0 +1,83 @@
+package envreq
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValueShape is coarse, non-sensitive metadata about a value: how long it
+// is and which characters it uses, never the value itself. Dashboards can
+// compare shapes across a fleet to spot anomalies such as an API key that
+// is present but empty, or a hex key that turned into a URL.
+type ValueShape struct {
+	Length    string `json:"length"`            // length class in characters: "0", "1-7", "8-15", ..., "128-255" or "256+"; "0" or "1+" when Sensitive
+	Charset   string `json:"charset,omitempty"` // narrowest of "empty", "digits", "hex", "alphanumeric", "base64", "printable", "unicode"; "" when Sensitive
+	Defaulted bool   `json:"defaulted"`         // the value came from Default or DefaultFunc
+}
+
+// Shape returns the shape of the value of r, or false when r is not set
+// or is NeverShow, whose values disclose nothing, not even their shape.
+// A Sensitive value only discloses whether it is empty: the length and
+// charset of a short PIN or token would narrow its search space.
+func (r Result) Shape() (ValueShape, bool) {
+	if !r.Present || r.NeverShow {
+		return ValueShape{}, false
+	}
+	if r.Sensitive {
+		shape := ValueShape{Length: "1+", Defaulted: r.Provenance == ProvenanceDefault}
+		if r.Value == "" {
+			shape.Length = "0"
+		}
+		return shape, true
+	}
+	return ValueShape{
+		Length:    lengthClass(utf8.RuneCountInString(r.Value)),
+		Charset:   charsetClass(r.Value),
+		Defaulted: r.Provenance == ProvenanceDefault,
+	}, true
+}
+
+// lengthClass returns the power-of-two class of n.
+func lengthClass(n int) string {
+	switch {
+	case n == 0:
+		return "0"
+	case n < 8:
+		return "1-7"
+	case n >= 256:
+		return "256+"
+	}
+	lo := 8
+	for lo*2 <= n {
+		lo *= 2
+	}
+	return fmt.Sprintf("%d-%d", lo, lo*2-1)
+}
+
+// charsetClass returns the narrowest character class of v.
+func charsetClass(v string) string {
+	const (
+		digits = "0123456789"
+		hex    = digits + "abcdefABCDEF"
+		alnum  = digits + "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	)
+	switch {
+	case v == "":
+		return "empty"
+	case strings.Trim(v, digits) == "":
+		return "digits"
+	case strings.Trim(v, hex) == "":
+		return "hex"
+	case strings.Trim(v, alnum) == "":
+		return "alphanumeric"
+	case strings.Trim(v, alnum+"+/-_=") == "":
+		return "base64"
+	}
+	for i := 0; i < len(v); i++ {
+		if v[i] >= 0x80 {
+			return "unicode"
+		}
+	}
+	return "printable"
+}
@@ -0,0 +1,74 @@
+package envreq_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestShape(t *testing.T) {
+	t.Setenv("SHP_API_KEY", "")
+	t.Setenv("SHP_HEX_KEY", strings.Repeat("a1", 16))
+	t.Setenv("SHP_URL", "https://api.example.com")
+	t.Setenv("SHP_NAME", "Zoë")
+	t.Setenv("SHP_SECRET", "s3cr3t")
+	t.Setenv("SHP_PIN", "4921")
+	t.Setenv("SHP_EMPTY_TOKEN", "")
+
+	g := envreq.New()
+	for _, tc := range []struct {
+		req  envreq.Requirement
+		want envreq.ValueShape
+	}{
+		{envreq.Requirement{Name: "SHP_API_KEY", Optional: true}, envreq.ValueShape{Length: "0", Charset: "empty"}},
+		{envreq.Requirement{Name: "SHP_HEX_KEY"}, envreq.ValueShape{Length: "32-63", Charset: "hex"}},
+		{envreq.Requirement{Name: "SHP_URL"}, envreq.ValueShape{Length: "16-31", Charset: "printable"}},
+		{envreq.Requirement{Name: "SHP_NAME"}, envreq.ValueShape{Length: "1-7", Charset: "unicode"}},
+		{envreq.Requirement{Name: "SHP_PORT", Default: "8080"}, envreq.ValueShape{Length: "1-7", Charset: "digits", Defaulted: true}},
+		// Sensitive values only tell whether they are empty
+		{envreq.Requirement{Name: "SHP_PIN", Sensitive: true}, envreq.ValueShape{Length: "1+"}},
+		{envreq.Requirement{Name: "SHP_EMPTY_TOKEN", Sensitive: true, Optional: true}, envreq.ValueShape{Length: "0"}},
+	} {
+		shape, ok := g.Check(tc.req).Shape()
+		if !ok || shape != tc.want {
+			t.Errorf("%s: Shape() = %+v, %v, want %+v", tc.req.Name, shape, ok, tc.want)
+		}
+	}
+	if _, ok := g.Check(envreq.Requirement{Name: "SHP_SECRET", NeverShow: true}).Shape(); ok {
+		t.Error("Expected no shape for a NeverShow variable")
+	}
+	if _, ok := g.Check(envreq.Requirement{Name: "SHP_UNSET", Optional: true}).Shape(); ok {
+		t.Error("Expected no shape for an unset variable")
+	}
+
+	var buf bytes.Buffer
+	if _, err := g.ReportJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "a1a1") || strings.Contains(buf.String(), "s3cr3t") {
+		t.Errorf("Report leaks a value: %s", buf.String())
+	}
+	var rep envreq.JSONReport
+	if err := json.Unmarshal(buf.Bytes(), &rep); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range rep.Vars {
+		switch e.Name {
+		case "SHP_API_KEY":
+			if e.Shape == nil || e.Shape.Charset != "empty" {
+				t.Errorf("Expected the empty key's shape in the report, got %+v", e.Shape)
+			}
+		case "SHP_PIN":
+			if e.Shape == nil || e.Shape.Length != "1+" || e.Shape.Charset != "" {
+				t.Errorf("Expected only presence for a Sensitive variable, got %+v", e.Shape)
+			}
+		case "SHP_SECRET", "SHP_UNSET":
+			if e.Shape != nil {
+				t.Errorf("%s: expected no shape in the report, got %+v", e.Name, e.Shape)
+			}
+		}
+	}
+}
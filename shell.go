@@ -0,0 +1,126 @@
+package envreq
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Shell is a command syntax written by ExportShellAs.
+type Shell string
+
+// Shells supported by ExportShellAs.
+const (
+	ShellPOSIX      Shell = "sh"         // export NAME='value' (sh, bash, zsh)
+	ShellPowerShell Shell = "powershell" // $env:NAME = 'value'
+	ShellFish       Shell = "fish"       // set -gx NAME 'value'
+)
+
+// ExportShell validates the default registry and writes its resolved
+// configuration as POSIX shell commands, one "export NAME='value'" line per
+// set variable, so that a validated environment can be loaded into a shell:
+//
+//	eval "$(envreq env -profile dev schema.json)"
+//
+// Sensitive variables are never written: they appear as a comment instead,
+// naming their SecretRef when they have one. Nothing is written when
+// validation fails; the *ValidationError is returned.
+func ExportShell(w io.Writer) error {
+	return std.ExportShellAs(w, ShellPOSIX)
+}
+
+// ExportShellAs is like ExportShell, in the syntax of shell.
+func ExportShellAs(w io.Writer, shell Shell) error {
+	return std.ExportShellAs(w, shell)
+}
+
+// ExportShell writes the registry's resolved configuration as shell
+// commands. See the package-level ExportShell.
+func (g *Registry) ExportShell(w io.Writer) error {
+	return g.ExportShellAs(w, ShellPOSIX)
+}
+
+// ExportShellAs writes the registry's resolved configuration in the syntax
+// of shell. See the package-level ExportShell.
+func (g *Registry) ExportShellAs(w io.Writer, shell Shell) error {
+	var assign func(name, value string) string
+	switch shell {
+	case ShellPOSIX:
+		assign = func(name, value string) string {
+			return "export " + name + "=" + shellQuote(value)
+		}
+	case ShellPowerShell:
+		assign = func(name, value string) string {
+			return "$env:" + name + " = " + psQuote(value)
+		}
+	case ShellFish:
+		assign = func(name, value string) string {
+			return "set -gx " + name + " " + fishQuote(value)
+		}
+	default:
+		return fmt.Errorf("envreq: unknown shell %q", shell)
+	}
+
+	results, err := g.ValidateResults()
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, res := range results {
+		switch {
+		case !res.Present:
+			continue
+		case res.Sensitive && res.SecretRef != "":
+			bw.WriteString("# " + res.Name + " omitted (sensitive; " + res.SecretRef + ")\n")
+		case res.Sensitive:
+			bw.WriteString("# " + res.Name + " omitted (sensitive)\n")
+		case !shellName(res.Name):
+			bw.WriteString("# " + res.Name + " omitted (not a shell variable name)\n")
+		default:
+			bw.WriteString(assign(res.Name, res.Value) + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// shellName reports whether name is a valid shell variable name.
+func shellName(name string) bool {
+	for i, c := range name {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c == '_':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+// shellQuote single-quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// psQuoteReplacer doubles the characters PowerShell accepts as single quotes.
+var psQuoteReplacer = strings.NewReplacer(
+	"'", "''",
+	"\u2018", "\u2018\u2018",
+	"\u2019", "\u2019\u2019",
+	"\u201a", "\u201a\u201a",
+	"\u201b", "\u201b\u201b",
+)
+
+// psQuote single-quotes s for PowerShell, which also ends single-quoted
+// strings at the typographic quotes ‘ ’ ‚ and ‛.
+func psQuote(s string) string {
+	return "'" + psQuoteReplacer.Replace(s) + "'"
+}
+
+// fishQuote single-quotes s for fish, where backslashes and quotes are
+// escaped inside single quotes.
+func fishQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
@@ -0,0 +1,65 @@
+package envreq_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestExportShell(t *testing.T) {
+	g := envreq.New()
+	g.SetSources(envreq.MapSource(map[string]string{
+		"SH_GREETING": "it's $HOME",
+		"SH_TOKEN":    "s3cr3t",
+		"sh.dotted":   "x",
+	}))
+	g.Check(envreq.Requirement{Name: "SH_GREETING", Source: "app"})
+	g.Check(envreq.Requirement{Name: "SH_TOKEN", Source: "app", Sensitive: true})
+	g.Check(envreq.Requirement{Name: "SH_API_KEY", Source: "app", Sensitive: true, Default: "k", SecretRef: "vault:secret/data/app#key"})
+	g.Check(envreq.Requirement{Name: "SH_UNSET", Source: "app", Optional: true})
+	g.Check(envreq.Requirement{Name: "sh.dotted", Source: "app"})
+
+	var buf bytes.Buffer
+	if err := g.ExportShell(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `# SH_API_KEY omitted (sensitive; vault:secret/data/app#key)
+export SH_GREETING='it'\''s $HOME'
+# SH_TOKEN omitted (sensitive)
+# sh.dotted omitted (not a shell variable name)
+`
+	if buf.String() != want {
+		t.Errorf("Unexpected export:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	for shell, want := range map[envreq.Shell]string{
+		envreq.ShellPowerShell: `$env:SH_GREETING = 'it''s $HOME'`,
+		envreq.ShellFish:       `set -gx SH_GREETING 'it\'s $HOME'`,
+	} {
+		buf.Reset()
+		if err := g.ExportShellAs(&buf, shell); err != nil || !strings.Contains(buf.String(), "\n"+want+"\n") {
+			t.Errorf("Expected %s to write %s, got %v:\n%s", shell, want, err, buf.String())
+		}
+	}
+
+	ps := envreq.New()
+	ps.SetSources(envreq.MapSource(map[string]string{"SH_CURLY": "a‘b’c‚d‛e'f"}))
+	ps.Check(envreq.Requirement{Name: "SH_CURLY", Source: "app"})
+	buf.Reset()
+	if err := ps.ExportShellAs(&buf, envreq.ShellPowerShell); err != nil || buf.String() != "$env:SH_CURLY = 'a‘‘b’’c‚‚d‛‛e''f'\n" {
+		t.Errorf("Expected curly single quotes doubled for PowerShell, got %v: %q", err, buf.String())
+	}
+	if err := g.ExportShellAs(&buf, "tcsh"); err == nil {
+		t.Error("Expected an error for an unknown shell")
+	}
+
+	buf.Reset()
+	g.Check(envreq.Requirement{Name: "SH_MISSING", Source: "app"})
+	var verr *envreq.ValidationError
+	if err := g.ExportShell(&buf); !errors.As(err, &verr) || buf.Len() != 0 {
+		t.Errorf("Expected nothing exported for an invalid config, got %v and %q", err, buf.String())
+	}
+}
@@ -0,0 +1,172 @@
+package envreq
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// snapshotHeader starts the text form of a Snapshot.
+const snapshotHeader = "envreq-snapshot v1"
+
+// Snapshot is a compact, redacted record of the configuration state,
+// meant to be attached to panic and crash reports. It holds names,
+// statuses, provenance and fingerprints of non-sensitive values, never
+// values.
+type Snapshot struct {
+	Taken   time.Time
+	Build   BuildInfo   // see SetBuildInfo
+	Runtime RuntimeInfo // see IncludeRuntimeInfo
+	Vars    []SnapshotVar
+}
+
+// SnapshotVar is the state of one variable in a Snapshot.
+type SnapshotVar struct {
+	Name        string
+	Status      string // one of the Status constants, e.g. StatusOK
+	Provenance  string // "" when the variable is not set
+	Fingerprint string // stable digest of the value, "" when not set or Sensitive
+}
+
+// TakeSnapshot records the current state of all registered variables.
+func TakeSnapshot() Snapshot {
+	return std.TakeSnapshot()
+}
+
+// TakeSnapshot records the current state of the registry's variables.
+func (g *Registry) TakeSnapshot() Snapshot {
+	results := g.CheckAll()
+	s := Snapshot{Taken: time.Now().UTC(), Build: g.BuildInfo(), Vars: make([]SnapshotVar, len(results))}
+	if g.runtimeInfo.Load() {
+		s.Runtime = CurrentRuntimeInfo()
+	}
+	for i, res := range results {
+		v := SnapshotVar{Name: res.Name, Status: resultStatus(res)}
+		if res.Present {
+			v.Provenance = res.Provenance
+			// An unsalted digest of a short secret can be brute-forced
+			if !res.Sensitive {
+				v.Fingerprint = fingerprint(res.Value)
+			}
+		}
+		s.Vars[i] = v
+	}
+	return s
+}
+
+// String returns the text form: a header line with the time taken and the
+// set build and runtime fields, then one tab-separated line per variable.
+//
+//	envreq-snapshot v1 2024-03-10T01:30:00Z	service=payments-svc	version=1.4.2
+//	DATABASE_URL	ok	env	3fa1c09b22de
+//	API_KEY	missing
+func (s Snapshot) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s", snapshotHeader, s.Taken.UTC().Format(time.RFC3339))
+	for _, f := range s.headerFields() {
+		if *f.value != "" {
+			b.WriteString("\t" + f.key + "=" + strings.Join(strings.Fields(*f.value), " "))
+		}
+	}
+	b.WriteByte('\n')
+	for _, v := range s.Vars {
+		b.WriteString(v.Name + "\t" + v.Status)
+		if v.Provenance != "" {
+			b.WriteString("\t" + v.Provenance + "\t" + v.Fingerprint)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// headerFields returns the build and runtime fields of s with their header
+// keys.
+func (s *Snapshot) headerFields() []struct {
+	key   string
+	value *string
+} {
+	return []struct {
+		key   string
+		value *string
+	}{
+		{"service", &s.Build.Service},
+		{"version", &s.Build.Version},
+		{"commit", &s.Build.Commit},
+		{"built", &s.Build.BuildTime},
+		{"host", &s.Runtime.Hostname},
+		{"pod", &s.Runtime.Pod},
+		{"namespace", &s.Runtime.Namespace},
+		{"region", &s.Runtime.Region},
+	}
+}
+
+// Encode returns the gzip-compressed text form as base64, small enough to
+// attach to a crash report field or log line. DecodeSnapshot reverses it.
+func (s Snapshot) Encode() string {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	io.WriteString(zw, s.String())
+	zw.Close() // writes to a bytes.Buffer cannot fail
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// DecodeSnapshot parses a Snapshot from the output of Encode or String.
+func DecodeSnapshot(text string) (Snapshot, error) {
+	if !strings.HasPrefix(text, snapshotHeader) {
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("envreq: snapshot: %w", err)
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("envreq: snapshot: %w", err)
+		}
+		plain, err := io.ReadAll(zr)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("envreq: snapshot: %w", err)
+		}
+		text = string(plain)
+	}
+
+	var s Snapshot
+	sc := bufio.NewScanner(strings.NewReader(text))
+	if !sc.Scan() {
+		return s, fmt.Errorf("envreq: snapshot: empty")
+	}
+	header, ok := strings.CutPrefix(sc.Text(), snapshotHeader+" ")
+	if !ok {
+		return s, fmt.Errorf("envreq: snapshot: bad header %q", sc.Text())
+	}
+	taken, build, _ := strings.Cut(header, "\t")
+	for _, kv := range strings.Split(build, "\t") {
+		key, value, _ := strings.Cut(kv, "=")
+		for _, f := range s.headerFields() {
+			if f.key == key {
+				*f.value = value
+			}
+		}
+	}
+	t, err := time.Parse(time.RFC3339, taken)
+	if err != nil {
+		return s, fmt.Errorf("envreq: snapshot: %w", err)
+	}
+	s.Taken = t
+
+	for sc.Scan() {
+		f := strings.Split(sc.Text(), "\t")
+		if len(f) != 2 && len(f) != 4 {
+			return s, fmt.Errorf("envreq: snapshot: bad line %q", sc.Text())
+		}
+		v := SnapshotVar{Name: f[0], Status: f[1]}
+		if len(f) == 4 {
+			v.Provenance, v.Fingerprint = f[2], f[3]
+		}
+		s.Vars = append(s.Vars, v)
+	}
+	return s, sc.Err()
+}
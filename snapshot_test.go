@@ -0,0 +1,53 @@
+package envreq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestSnapshot(t *testing.T) {
+	g := envreq.New()
+	t.Setenv("SNAP_URL", "https://db.example.com")
+	t.Setenv("SNAP_KEY", "sk_live_topsecret")
+	g.Check(envreq.Requirement{Name: "SNAP_URL", Source: "test"})
+	g.Check(envreq.Requirement{Name: "SNAP_KEY", Source: "test", Sensitive: true})
+	g.Check(envreq.Requirement{Name: "SNAP_MISSING", Source: "test"})
+
+	s := g.TakeSnapshot()
+	text := s.String()
+	if strings.Contains(text, "topsecret") || strings.Contains(text, "db.example.com") {
+		t.Errorf("Snapshot leaks values:\n%s", text)
+	}
+	if !strings.Contains(text, "SNAP_MISSING\tmissing\n") || !strings.Contains(text, "SNAP_KEY\tok\tenv\t") {
+		t.Errorf("Unexpected snapshot:\n%s", text)
+	}
+
+	for _, v := range s.Vars {
+		if v.Name == "SNAP_KEY" && v.Fingerprint != "" {
+			t.Errorf("Expected no fingerprint of a sensitive value, got %q", v.Fingerprint)
+		}
+	}
+
+	for _, enc := range []string{s.Encode(), text} {
+		got, err := envreq.DecodeSnapshot(enc)
+		if err != nil {
+			t.Fatalf("DecodeSnapshot: %v", err)
+		}
+		if !got.Taken.Equal(s.Taken.Truncate(1e9)) || !reflect.DeepEqual(got.Vars, s.Vars) {
+			t.Errorf("Round trip mismatch:\n got %+v\nwant %+v", got, s)
+		}
+	}
+
+	g.SetBuildInfo(envreq.BuildInfo{Service: "payments-svc", Version: "1.4.2", Commit: "3d45ed4"})
+	s = g.TakeSnapshot()
+	if got, err := envreq.DecodeSnapshot(s.Encode()); err != nil || got.Build.Version != "1.4.2" || got.Build.Commit != "3d45ed4" {
+		t.Errorf("Expected the build info to round trip, got %+v, %v", got.Build, err)
+	}
+
+	if _, err := envreq.DecodeSnapshot("not a snapshot"); err == nil {
+		t.Error("Expected an error for garbage input")
+	}
+}
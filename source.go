@@ -0,0 +1,110 @@
+package envreq
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Source supplies variable values to Check, e.g. the process environment,
+// a map in tests, a file or a remote secret store. Lookup reports whether
+// name is set; an error means the source could not be consulted.
+type Source interface {
+	Lookup(name string) (string, bool, error)
+}
+
+// RefSource is a Source that also resolves Requirement.SecretRef values of
+// the form "<scheme>:<ref>", e.g. "vault:secret/data/payments#stripe_key".
+// Requirements whose SecretRef has the source's scheme are looked up with
+// LookupRef instead of Lookup.
+type RefSource interface {
+	Source
+	Scheme() string
+	LookupRef(ref string) (string, bool, error)
+}
+
+// lookup consults s for r, by SecretRef when s resolves its scheme.
+func lookup(s Source, r Requirement) (string, bool, error) {
+	if n, ok := s.(named); ok {
+		s = n.Source
+	}
+	if rs, ok := s.(RefSource); ok && r.SecretRef != "" {
+		if ref, ok := strings.CutPrefix(r.SecretRef, rs.Scheme()+":"); ok {
+			return rs.LookupRef(ref)
+		}
+	}
+	return s.Lookup(r.Name)
+}
+
+// SourceFunc adapts a function to a Source.
+type SourceFunc func(name string) (string, bool, error)
+
+// Lookup calls f(name).
+func (f SourceFunc) Lookup(name string) (string, bool, error) {
+	return f(name)
+}
+
+// Env is the Source backed by the process environment.
+var Env Source = NamedSource(ProvenanceEnv, SourceFunc(func(name string) (string, bool, error) {
+	v, ok := os.LookupEnv(name)
+	return v, ok, nil
+}))
+
+// MapSource is a Source backed by a map, handy in tests.
+type MapSource map[string]string
+
+// Lookup returns m[name].
+func (m MapSource) Lookup(name string) (string, bool, error) {
+	v, ok := m[name]
+	return v, ok, nil
+}
+
+// Name returns "map".
+func (m MapSource) Name() string {
+	return "map"
+}
+
+// NamedSource returns s with a name, which is recorded as the Provenance
+// of the values it supplies.
+func NamedSource(name string, s Source) Source {
+	return named{Source: s, name: name}
+}
+
+type named struct {
+	Source
+	name string
+}
+
+func (n named) Name() string {
+	return n.name
+}
+
+// sourceName returns the provenance recorded for values from s.
+func sourceName(s Source) string {
+	if n, ok := s.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+	return fmt.Sprintf("%T", s)
+}
+
+// SetSources replaces the chain of sources consulted by Check, highest
+// precedence first. With no arguments the chain is reset to Env alone.
+// Local overrides (LoadLocalOverrides) stay above the chain, and dotenv
+// files (LoadDotenv) and defaults below it. Cached results are dropped.
+func SetSources(sources ...Source) {
+	std.SetSources(sources...)
+}
+
+// SetSources replaces the registry's source chain. See the package-level
+// SetSources.
+func (g *Registry) SetSources(sources ...Source) {
+	if len(sources) == 0 {
+		sources = []Source{Env}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.sources = append([]Source(nil), sources...)
+	g.providers = newProviderStats(len(sources))
+	g.cache = map[string]resolved{}
+}
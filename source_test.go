@@ -0,0 +1,176 @@
+package envreq_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestSourceChain(t *testing.T) {
+	g := envreq.New()
+	t.Setenv("SRC_HOST", "from-env")
+
+	g.SetSources(envreq.MapSource{"SRC_HOST": "from-map", "SRC_PORT": "5432"}, envreq.Env)
+
+	host := g.Check(envreq.Requirement{Name: "SRC_HOST", Source: "test"})
+	if host.Value != "from-map" || host.Provenance != "map" {
+		t.Errorf("Expected the first source to win, got %q from %s", host.Value, host.Provenance)
+	}
+	if len(host.Shadowed) != 1 || host.Shadowed[0].Provenance != envreq.ProvenanceEnv {
+		t.Errorf("Expected env to be shadowed, got %+v", host.Shadowed)
+	}
+
+	// Without Env in the chain the process environment is not consulted
+	g.SetSources(envreq.NamedSource("fixture", envreq.MapSource{"SRC_PORT": "5432"}))
+	if res := g.Check(envreq.Requirement{Name: "SRC_HOST", Source: "test"}); res.Present {
+		t.Errorf("Expected SRC_HOST to be missing, got %q from %s", res.Value, res.Provenance)
+	}
+	if res := g.Check(envreq.Requirement{Name: "SRC_PORT", Source: "test"}); res.Provenance != "fixture" {
+		t.Errorf("Expected provenance of a named source, got %s", res.Provenance)
+	}
+
+	// Reset restores the environment
+	g.SetSources()
+	if res := g.Check(envreq.Requirement{Name: "SRC_HOST", Source: "test"}); res.Value != "from-env" {
+		t.Errorf("Expected env after SetSources(), got %q", res.Value)
+	}
+}
+
+func TestSourceError(t *testing.T) {
+	g := envreq.New()
+	errDown := errors.New("vault sealed")
+	g.SetSources(envreq.NamedSource("vault", envreq.SourceFunc(func(string) (string, bool, error) {
+		return "", false, errDown
+	})), envreq.MapSource{"SRC_FALLBACK": "x"})
+
+	if res := g.Check(envreq.Requirement{Name: "SRC_SECRET", Source: "test"}); !errors.Is(res.Err, errDown) {
+		t.Errorf("Expected the source error on a missing value, got %v", res.Err)
+	}
+	if res := g.Check(envreq.Requirement{Name: "SRC_FALLBACK", Source: "test"}); res.Err != nil || res.Value != "x" {
+		t.Errorf("Expected a lower source to supply the value, got %q, %v", res.Value, res.Err)
+	}
+}
+
+func TestConsulted(t *testing.T) {
+	g := envreq.New()
+	g.SetSources(envreq.Env, envreq.NamedSource("vault", envreq.SourceFunc(func(string) (string, bool, error) {
+		return "", false, errors.New("permission denied")
+	})))
+
+	res := g.Check(envreq.Requirement{Name: "CONSULTED_DB_URL", Default: "postgres://localhost/dev"})
+	if res.Provenance != envreq.ProvenanceDefault {
+		t.Fatalf("Expected the default, got %+v", res)
+	}
+	for _, want := range []string{
+		"env CONSULTED_DB_URL: unset",
+		"vault CONSULTED_DB_URL: permission denied",
+		"default CONSULTED_DB_URL: used",
+	} {
+		if !strings.Contains(res.Explain(), want) {
+			t.Errorf("Expected %q in %q", want, res.Explain())
+		}
+	}
+
+	// The trace is cached with the value
+	for _, cached := range g.CheckAll() {
+		if cached.Name == "CONSULTED_DB_URL" && cached.Explain() != res.Explain() {
+			t.Errorf("Expected the cached trace %q, got %q", res.Explain(), cached.Explain())
+		}
+	}
+}
+
+func TestLookupCache(t *testing.T) {
+	calls := map[string]int{}
+	g := envreq.New()
+	g.SetSources(envreq.Env, envreq.NamedSource("vault", envreq.SourceFunc(func(name string) (string, bool, error) {
+		calls[name]++
+		switch name {
+		case "CACHE_HOST":
+			return "db", true, nil
+		case "CACHE_URL":
+			return "postgres://${CACHE_HOST}/app", true, nil
+		}
+		return "", false, nil
+	})))
+
+	g.Check(envreq.Requirement{Name: "CACHE_HOST"})
+	if res := g.Check(envreq.Requirement{Name: "CACHE_URL"}); res.Value != "postgres://db/app" {
+		t.Fatalf("Expected the reference expanded, got %q", res.Value)
+	}
+	if calls["CACHE_HOST"] != 1 {
+		t.Errorf("Expected the expansion to reuse the cached lookup, got %d lookups", calls["CACHE_HOST"])
+	}
+
+	results, _ := g.CheckRollback(g.Describe())
+	if len(results) != 2 || !strings.Contains(results[0].Explain(), "vault CACHE_HOST: used (cached)") {
+		t.Errorf("Expected the cached lookup in the trace, got %v", results)
+	}
+	if calls["CACHE_HOST"] != 1 || calls["CACHE_URL"] != 1 {
+		t.Errorf("Expected no further lookups, got %v", calls)
+	}
+
+	// Revalidate asks the sources again
+	g.Revalidate()
+	if calls["CACHE_HOST"] != 2 || calls["CACHE_URL"] != 2 {
+		t.Errorf("Expected Revalidate to bypass the cache, got %v", calls)
+	}
+}
+
+func TestProviders(t *testing.T) {
+	g := envreq.New()
+	denied := envreq.NamedSource("vault", envreq.SourceFunc(func(string) (string, bool, error) {
+		return "", false, fmt.Errorf("vault: 403 Forbidden: %w", fs.ErrPermission)
+	}))
+	down := envreq.NamedSource("gcp", envreq.SourceFunc(func(string) (string, bool, error) {
+		return "", false, errors.New("connection refused")
+	}))
+	g.SetSources(envreq.Env, envreq.MapSource{"PRV_HOST": "db"}, denied, down)
+	g.Check(envreq.Requirement{Name: "PRV_HOST", Source: "test"})
+
+	providers := g.Providers()
+	if len(providers) != 4 {
+		t.Fatalf("Expected one status per source, got %+v", providers)
+	}
+	if p := providers[1]; p.Name != "map" || !p.Reachable || p.Lookups != 1 || p.Errors != 0 {
+		t.Errorf("Expected a healthy map source, got %+v", p)
+	}
+	if p := providers[2]; !p.Reachable || p.Auth != envreq.AuthFailed || p.Errors != 1 {
+		t.Errorf("Expected vault reachable with failed auth, got %+v", p)
+	}
+	if p := providers[3]; p.Reachable || p.Auth != "" || p.LastError == nil {
+		t.Errorf("Expected gcp unreachable, got %+v", p)
+	}
+
+	var buf strings.Builder
+	g.Report(&buf)
+	for _, want := range []string{"Providers:", "auth failed", "UNREACHABLE", "connection refused"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected %q in the report:\n%s", want, buf.String())
+		}
+	}
+
+	var js strings.Builder
+	if _, err := g.ReportJSON(&js); err != nil {
+		t.Fatal(err)
+	}
+	var rep envreq.JSONReport
+	if err := json.Unmarshal([]byte(js.String()), &rep); err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Providers) != 4 || rep.Providers[2].Auth != envreq.AuthFailed || rep.Providers[3].LastError != "connection refused" {
+		t.Errorf("Expected providers in the JSON report, got %+v", rep.Providers)
+	}
+
+	// Env alone needs no providers section
+	g.SetSources()
+	buf.Reset()
+	g.Report(&buf)
+	if strings.Contains(buf.String(), "Providers:") {
+		t.Errorf("Expected no providers section for Env alone:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,251 @@
+package envreq
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stage is a phase of the validation pass run by Validate and
+// MustValidate. Stages run cheapest first, so a typo in a URL is reported
+// before a slow reachability check tries to dial it.
+type Stage int
+
+// Validation stages, in the order they run.
+const (
+	// StageSyntax: presence and Validate of every variable, as run by Check.
+	StageSyntax Stage = iota + 1
+	// StageConstraints: group constraints and cross-checks, see AllOrNone
+	// and AddCrossCheck.
+	StageConstraints
+	// StageDeep: Requirement.DeepValidate and the checks added with
+	// AddDeepCheck, e.g. that a broker is reachable.
+	StageDeep
+)
+
+func (s Stage) String() string {
+	switch s {
+	case StageSyntax:
+		return "syntax"
+	case StageConstraints:
+		return "constraints"
+	case StageDeep:
+		return "deep"
+	}
+	return fmt.Sprintf("Stage(%d)", int(s))
+}
+
+// Severity grades the problems found by a validation stage, see
+// SetStopSeverity.
+type Severity int
+
+// Severities of validation problems.
+const (
+	SeverityWarning Severity = iota + 1 // an optional variable is invalid, or one in its grace period unset
+	SeverityError                       // a required variable is missing or invalid, or a constraint is violated
+)
+
+// SetStopSeverity stops the validation pass of the default registry after
+// the first stage that finds a problem of severity s or worse; the stages
+// after it are listed in ValidationError.Skipped instead of being run:
+//
+//	envreq.SetStopSeverity(envreq.SeverityError) // no deep checks against a broken config
+//
+// The zero Severity, the default, runs every stage.
+func SetStopSeverity(s Severity) {
+	std.SetStopSeverity(s)
+}
+
+// SetStopSeverity sets the severity that stops the registry's validation
+// pass. See the package-level SetStopSeverity.
+func (g *Registry) SetStopSeverity(s Severity) {
+	g.stopSeverity.Store(int32(s))
+}
+
+// AddDeepCheck registers a cross-variable check like AddCrossCheck, run in
+// StageDeep: only by Validate and MustValidate, after every other check.
+// Use it for checks that are slow or touch the network, e.g. logging in
+// with DB_USER and DB_PASSWORD:
+//
+//	envreq.AddDeepCheck(dbLogin, envreq.DeepTimeout(3*time.Second))
+//
+// Deep checks and Requirement.DeepValidate run concurrently, each within
+// its own budget, so one slow dependency neither delays nor hides the
+// others.
+func AddDeepCheck(check func(lookup func(name string) Result) error, opts ...DeepCheckOption) {
+	std.AddDeepCheck(check, opts...)
+}
+
+// AddDeepCheck registers a deep cross-variable check with the registry.
+// See the package-level AddDeepCheck.
+func (g *Registry) AddDeepCheck(check func(lookup func(name string) Result) error, opts ...DeepCheckOption) {
+	c := constraint{check: check, stage: StageDeep}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	g.mu.Lock()
+	g.constraints = append(g.constraints, c)
+	g.mu.Unlock()
+}
+
+// DeepCheckOption configures a check added with AddDeepCheck.
+type DeepCheckOption func(*constraint)
+
+// DeepTimeout limits a deep check to d, like Requirement.DeepTimeout: a
+// check still running after d fails with ErrTimeout.
+func DeepTimeout(d time.Duration) DeepCheckOption {
+	return func(c *constraint) {
+		c.timeout = d
+	}
+}
+
+// DeepSeverity sets the severity of a deep check's failures, like
+// Requirement.DeepSeverity: with SeverityWarning they are reported in
+// ValidationError.Warnings without failing validation.
+func DeepSeverity(s Severity) DeepCheckOption {
+	return func(c *constraint) {
+		c.severity = s
+	}
+}
+
+// stops reports whether the problems found so far, in results and
+// constraints, end the validation pass.
+func (g *Registry) stops(results []Result, constraints []ConstraintError) bool {
+	threshold := Severity(g.stopSeverity.Load())
+	return threshold > 0 && severity(results, constraints) >= threshold
+}
+
+// severity returns the worst severity of the problems in results and
+// constraints, or 0 when there are none.
+func severity(results []Result, constraints []ConstraintError) Severity {
+	if len(constraints) > 0 || len(problems(results)) > 0 {
+		return SeverityError
+	}
+	for _, res := range results {
+		if res.Err != nil || (res.Grace && !res.Present) {
+			return SeverityWarning
+		}
+	}
+	return 0
+}
+
+// resultWarnings returns the warnings of severity in results, the invalid
+// optional variables and the unset ones in a grace period, as
+// ConstraintErrors.
+func resultWarnings(results []Result) []ConstraintError {
+	var warnings []ConstraintError
+	for _, res := range results {
+		switch {
+		case res.Err != nil:
+			warnings = append(warnings, ConstraintError{Names: []string{res.Name}, Err: fmt.Errorf("%s: %w", res.Name, res.Err)})
+		case res.Grace && !res.Present:
+			warnings = append(warnings, ConstraintError{Names: []string{res.Name}, Err: fmt.Errorf("%s: not set, required after %v", res.Name, res.RequiredAfter)})
+		}
+	}
+	return warnings
+}
+
+// deepStage runs the DeepValidate of results that are set and valid and
+// the registry's deep checks, concurrently and each within its budget and
+// ctx. Failures of SeverityError are recorded on a copy of results or
+// returned as errs, in registration order; those of SeverityWarning are
+// returned as warnings. The cache is left untouched.
+func (g *Registry) deepStage(ctx context.Context, results []Result) (checked []Result, errs, warnings []ConstraintError) {
+	checked = slices.Clone(results)
+	checks := g.stageConstraints(StageDeep)
+	t := g.lookupTable(results)
+	varWarnings := make([]*ConstraintError, len(results))
+	outcomes := make([]*ConstraintError, len(checks))
+
+	var wg sync.WaitGroup
+	for i, res := range results {
+		if res.DeepValidate == nil || !res.Present || res.Err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err, terr := withBudget(ctx, res.DeepTimeout, func() error { return res.DeepValidate(res.Value) })
+			if terr != nil {
+				err = terr
+			}
+			if err = conceal(res.Requirement, err); err == nil {
+				return
+			}
+			if res.DeepSeverity == SeverityWarning {
+				varWarnings[i] = &ConstraintError{Names: []string{res.Name}, Err: fmt.Errorf("%s: %w", res.Name, err)}
+				return
+			}
+			checked[i].Err = err
+		}()
+	}
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			type outcome struct {
+				ce     ConstraintError
+				failed bool
+			}
+			o, terr := withBudget(ctx, c.timeout, func() outcome {
+				ce, failed := t.evaluate(c)
+				return outcome{ce, failed}
+			})
+			if terr != nil {
+				o = outcome{ConstraintError{Err: fmt.Errorf("deep check not finished: %w", terr)}, true}
+			}
+			if o.failed {
+				outcomes[i] = &o.ce
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, w := range varWarnings {
+		if w != nil {
+			warnings = append(warnings, *w)
+		}
+	}
+	for i, ce := range outcomes {
+		switch {
+		case ce == nil:
+		case checks[i].severity == SeverityWarning:
+			warnings = append(warnings, *ce)
+		default:
+			errs = append(errs, *ce)
+		}
+	}
+	return checked, errs, warnings
+}
+
+// withBudget runs f like withContext, bounded by ctx and, when budget is
+// positive, by budget. The error is non-nil when f did not finish in time.
+func withBudget[T any](ctx context.Context, budget time.Duration, f func() T) (T, error) {
+	parent := ctx
+	if budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+	v, ok := withContext(ctx, f)
+	switch {
+	case ok:
+		return v, nil
+	case parent.Err() != nil:
+		return v, timeoutError(parent)
+	default:
+		return v, fmt.Errorf("%w: exceeded its %v budget", ErrTimeout, budget)
+	}
+}
+
+// stageList returns e.g. "constraints, deep".
+func stageList(stages []Stage) string {
+	names := make([]string, len(stages))
+	for i, s := range stages {
+		names[i] = s.String()
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,174 @@
+package envreq_test
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestDeepStage(t *testing.T) {
+	t.Setenv("STG_BROKER", "kafka-1:9092")
+	t.Setenv("STG_DB_USER", "app")
+
+	dialed := 0
+	g := envreq.New()
+	res := g.Check(envreq.Requirement{
+		Name:     "STG_BROKER",
+		Source:   "queue",
+		Validate: envreq.HostPort,
+		DeepValidate: func(string) error {
+			dialed++
+			return errors.New("connection refused")
+		},
+	})
+	if res.Err != nil || dialed != 0 {
+		t.Fatalf("Expected Check to skip the deep validator, got %v after %d dial(s)", res.Err, dialed)
+	}
+	logins := 0
+	g.AddDeepCheck(func(lookup func(string) envreq.Result) error {
+		logins++
+		lookup("STG_DB_USER")
+		return errors.New("login failed")
+	})
+
+	var buf bytes.Buffer
+	g.Report(&buf)
+	if dialed != 0 || logins != 0 {
+		t.Errorf("Expected Report to skip the deep stage, got %d dial(s) and %d login(s)", dialed, logins)
+	}
+
+	var verr *envreq.ValidationError
+	if err := g.Validate(); !errors.As(err, &verr) {
+		t.Fatalf("Expected a *ValidationError, got %v", err)
+	}
+	if len(verr.Problems) != 1 || verr.Problems[0].Name != "STG_BROKER" || verr.Problems[0].Err.Error() != "connection refused" {
+		t.Errorf("Expected the deep validation failure as a problem, got %+v", verr.Problems)
+	}
+	if len(verr.Constraints) != 1 || verr.Constraints[0].Err.Error() != "STG_DB_USER: login failed" {
+		t.Errorf("Expected the failed deep check, got %+v", verr.Constraints)
+	}
+	if res := g.Check(envreq.Requirement{Name: "STG_BROKER"}); res.Err != nil {
+		t.Errorf("Expected the deep stage to leave the cached result alone, got %v", res.Err)
+	}
+
+	schema := g.Describe()
+	if v := schema.Vars[0]; v.DeepValidator == "" || v.Validator != "envreq.HostPort" {
+		t.Errorf("Expected the schema to record the deep validator, got %+v", v)
+	}
+}
+
+func TestStopSeverity(t *testing.T) {
+	t.Setenv("STP_TLS_CERT", "/etc/tls/cert.pem")
+	t.Setenv("STP_LEVEL", "loud")
+
+	deep := 0
+	g := envreq.New()
+	g.SetStopSeverity(envreq.SeverityError)
+	register := func() {
+		g.Reset()
+		g.Check(envreq.Requirement{Name: "STP_TOKEN", Source: "api"})
+		g.Check(envreq.Requirement{Name: "STP_LEVEL", Source: "api", Optional: true, Validate: envreq.OneOf("debug", "info")})
+		g.Implies("STP_TLS_CERT", "STP_TLS_KEY")
+		g.AddDeepCheck(func(func(string) envreq.Result) error {
+			deep++
+			return nil
+		})
+	}
+	register()
+
+	var verr *envreq.ValidationError
+	if err := g.Validate(); !errors.As(err, &verr) {
+		t.Fatalf("Expected a *ValidationError, got %v", err)
+	}
+	if !slices.Equal(verr.Skipped, []envreq.Stage{envreq.StageConstraints, envreq.StageDeep}) || len(verr.Constraints) != 0 || deep != 0 {
+		t.Errorf("Expected a missing variable to stop before the constraints, got %v", verr)
+	}
+
+	t.Setenv("STP_TOKEN", "s3cr3t")
+	register()
+	if err := g.Validate(); !errors.As(err, &verr) {
+		t.Fatalf("Expected a *ValidationError, got %v", err)
+	}
+	if !slices.Equal(verr.Skipped, []envreq.Stage{envreq.StageDeep}) || len(verr.Constraints) != 1 || deep != 0 {
+		t.Errorf("Expected a violated constraint to stop before the deep stage, got %v", verr)
+	}
+
+	t.Setenv("STP_TLS_KEY", "/etc/tls/key.pem")
+	register()
+	if err := g.Validate(); err != nil || deep != 1 {
+		t.Errorf("Expected an invalid optional variable not to stop the pass, got %v after %d deep check(s)", err, deep)
+	}
+	g.SetStopSeverity(envreq.SeverityWarning)
+	if err := g.Validate(); !errors.As(err, &verr) || len(verr.Skipped) != 2 || deep != 1 {
+		t.Errorf("Expected the warning to stop the pass, got %v", err)
+	}
+	if len(verr.Warnings) != 1 || verr.Warnings[0].Names[0] != "STP_LEVEL" || !strings.Contains(verr.Error(), "by 1 warning(s): STP_LEVEL:") {
+		t.Errorf("Expected the stopping warning in the error, got %v (%+v)", verr, verr.Warnings)
+	}
+}
+
+func TestDeepBudgets(t *testing.T) {
+	t.Setenv("BDG_BROKER", "kafka-1:9092")
+	t.Setenv("BDG_CACHE", "redis:6379")
+	t.Setenv("BDG_SEARCH", "search:9200")
+
+	hang := make(chan struct{})
+	defer close(hang)
+	g := envreq.New()
+	g.Check(envreq.Requirement{
+		Name:         "BDG_BROKER",
+		Source:       "queue",
+		DeepValidate: func(string) error { <-hang; return nil },
+		DeepTimeout:  50 * time.Millisecond,
+	})
+	g.Check(envreq.Requirement{
+		Name:         "BDG_CACHE",
+		Source:       "cache",
+		DeepValidate: func(string) error { return errors.New("connection refused") },
+	})
+	g.Check(envreq.Requirement{
+		Name:         "BDG_SEARCH",
+		Source:       "search",
+		DeepValidate: func(string) error { return errors.New("cluster is yellow") },
+		DeepSeverity: envreq.SeverityWarning,
+	})
+	g.AddDeepCheck(func(func(string) envreq.Result) error { <-hang; return nil }, envreq.DeepTimeout(50*time.Millisecond))
+	g.AddDeepCheck(func(lookup func(string) envreq.Result) error {
+		lookup("BDG_SEARCH")
+		return errors.New("index missing")
+	}, envreq.DeepSeverity(envreq.SeverityWarning))
+
+	start := time.Now()
+	var verr *envreq.ValidationError
+	if err := g.Validate(); !errors.As(err, &verr) {
+		t.Fatalf("Expected a *ValidationError, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the budgets to bound the deep stage, took %v", elapsed)
+	}
+	if len(verr.Problems) != 2 || !errors.Is(verr.Problems[0].Err, envreq.ErrTimeout) || verr.Problems[1].Err.Error() != "connection refused" {
+		t.Errorf("Expected the hanging and the failing check as problems, got %+v", verr.Problems)
+	}
+	if len(verr.Constraints) != 1 || !errors.Is(verr.Constraints[0].Err, envreq.ErrTimeout) {
+		t.Errorf("Expected the hanging deep check to time out, got %+v", verr.Constraints)
+	}
+	if len(verr.Warnings) != 2 || verr.Warnings[0].Err.Error() != "BDG_SEARCH: cluster is yellow" || verr.Warnings[1].Err.Error() != "BDG_SEARCH: index missing" {
+		t.Errorf("Expected both warnings, got %+v", verr.Warnings)
+	}
+
+	w := envreq.New()
+	w.Check(envreq.Requirement{
+		Name:         "BDG_SEARCH",
+		Source:       "search",
+		DeepValidate: func(string) error { return errors.New("cluster is yellow") },
+		DeepSeverity: envreq.SeverityWarning,
+	})
+	if err := w.Validate(); err != nil {
+		t.Errorf("Expected warnings alone to pass validation, got %v", err)
+	}
+}
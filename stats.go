@@ -0,0 +1,131 @@
+package envreq
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LateWarnInterval is the minimum time between two post-Freeze warnings for
+// optional registrations. Each variable is warned about once; variables
+// registered within the interval after a warning are logged together in
+// one warning when it ends, which bounds the log volume when many distinct
+// variables register late (e.g. names built per request).
+var LateWarnInterval = time.Minute
+
+// Counters is a snapshot of registry activity counters, suitable for export
+// as metrics.
+type Counters struct {
+	LateOptional     uint64 // optional registrations observed after Freeze
+	LateWarnings     uint64 // late optional variables named in a logged warning
+	LateSuppressed   uint64 // post-Freeze warnings dropped by deduplication or delayed by rate limiting
+	LateOptionalVars int    // distinct optional variables registered after Freeze
+	LateWindowed     uint64 // registrations inside late registration windows
+	Revalidations    uint64 // Revalidate runs
+	Degraded         uint64 // Revalidate runs that found problems
+}
+
+// lateState tracks post-Freeze optional registrations.
+type lateState struct {
+	optional   atomic.Uint64
+	warnings   atomic.Uint64
+	suppressed atomic.Uint64
+	windowed   atomic.Uint64
+
+	mu       sync.Mutex
+	seen     map[string]bool // distinct late optional variables
+	pending  []string        // rate limited, logged when the interval ends
+	flush    *time.Timer     // logs pending
+	lastWarn time.Time
+}
+
+// Stats returns a snapshot of the registry counters.
+func Stats() Counters {
+	return std.Stats()
+}
+
+// Stats returns a snapshot of the registry counters.
+func (g *Registry) Stats() Counters {
+	g.late.mu.Lock()
+	vars := len(g.late.seen)
+	g.late.mu.Unlock()
+
+	return Counters{
+		LateOptional:     g.late.optional.Load(),
+		LateWarnings:     g.late.warnings.Load(),
+		LateSuppressed:   g.late.suppressed.Load(),
+		LateOptionalVars: vars,
+		LateWindowed:     g.late.windowed.Load(),
+		Revalidations:    g.periodic.runs.Load(),
+		Degraded:         g.periodic.degraded.Load(),
+	}
+}
+
+// warnLateOptional records an optional registration after Freeze and logs a
+// warning unless this variable was already reported. Within the rate limit
+// the warning is queued for flushLateWarnings instead.
+func (g *Registry) warnLateOptional(r Requirement) {
+	l := &g.late
+	l.optional.Add(1)
+
+	l.mu.Lock()
+	if l.seen[r.Name] {
+		l.mu.Unlock()
+		l.suppressed.Add(1)
+		return
+	}
+	l.seen[r.Name] = true
+	now := time.Now()
+	if !l.lastWarn.IsZero() && now.Sub(l.lastWarn) < LateWarnInterval {
+		l.pending = append(l.pending, fmt.Sprintf("%s (from %s)", r.Name, r.Source))
+		if l.flush == nil {
+			l.flush = time.AfterFunc(l.lastWarn.Add(LateWarnInterval).Sub(now), g.flushLateWarnings)
+		}
+		l.mu.Unlock()
+		l.suppressed.Add(1)
+		return
+	}
+	l.lastWarn = now
+	l.mu.Unlock()
+
+	g.logf("⚠️  envreq: Optional environment variable registered after Freeze(): %s (from %s)", r.Name, r.Source)
+	l.warnings.Add(1)
+}
+
+// flushLateWarnings logs the warnings queued by warnLateOptional as one.
+func (g *Registry) flushLateWarnings() {
+	l := &g.late
+	l.mu.Lock()
+	pending := l.pending
+	l.pending, l.flush = nil, nil
+	if len(pending) > 0 {
+		l.lastWarn = time.Now()
+	}
+	l.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+	g.logf("⚠️  envreq: Optional environment variables registered after Freeze(): %s", strings.Join(pending, ", "))
+	l.warnings.Add(uint64(len(pending)))
+}
+
+// reset clears all counters.
+func (l *lateState) reset() {
+	l.optional.Store(0)
+	l.warnings.Store(0)
+	l.suppressed.Store(0)
+	l.windowed.Store(0)
+
+	l.mu.Lock()
+	l.seen = map[string]bool{}
+	l.pending = nil
+	if l.flush != nil {
+		l.flush.Stop()
+		l.flush = nil
+	}
+	l.lastWarn = time.Time{}
+	l.mu.Unlock()
+}
@@ -0,0 +1,57 @@
+package envreq_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestLateOptionalRateLimit(t *testing.T) {
+	envreq.Reset()
+	defer envreq.Reset()
+
+	envreq.Freeze()
+	for _, name := range []string{"LATE_A", "LATE_B", "LATE_C"} {
+		envreq.Check(envreq.Requirement{Name: name, Source: "handler", Optional: true})
+		// Re-access is normal caching and must not count again
+		envreq.Check(envreq.Requirement{Name: name, Source: "handler", Optional: true})
+	}
+
+	stats := envreq.Stats()
+	if stats.LateOptional != 3 {
+		t.Errorf("Expected 3 late optional registrations, got %d", stats.LateOptional)
+	}
+	if stats.LateOptionalVars != 3 {
+		t.Errorf("Expected 3 distinct late vars, got %d", stats.LateOptionalVars)
+	}
+	if stats.LateWarnings != 1 {
+		t.Errorf("Expected a single warning within the rate limit window, got %d", stats.LateWarnings)
+	}
+	if stats.LateSuppressed != 2 {
+		t.Errorf("Expected 2 suppressed warnings, got %d", stats.LateSuppressed)
+	}
+}
+
+func TestLateOptionalRateLimitFlush(t *testing.T) {
+	defer func(d time.Duration) { envreq.LateWarnInterval = d }(envreq.LateWarnInterval)
+	envreq.LateWarnInterval = 50 * time.Millisecond
+
+	g := envreq.New()
+	logger := &captureLogger{}
+	g.SetLogger(logger)
+	g.Freeze()
+	g.Check(envreq.Requirement{Name: "LATE_FIRST", Source: "handler", Optional: true})
+	g.Check(envreq.Requirement{Name: "LATE_SECOND", Source: "handler", Optional: true})
+
+	// The second variable is logged when the interval ends, not dropped
+	deadline := time.Now().Add(2 * time.Second)
+	for g.Stats().LateWarnings < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	lines := logger.lines[1:] // after the Freeze notice
+	if len(lines) != 2 || !strings.Contains(lines[0], "LATE_FIRST") || !strings.Contains(lines[1], "LATE_SECOND (from handler)") {
+		t.Errorf("Expected both variables warned about, got %q", logger.lines)
+	}
+}
@@ -0,0 +1,189 @@
+package envreq
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Minimums of StrongSecret.
+const (
+	strongSecretLen     = 16
+	strongSecretEntropy = 48
+)
+
+// weakSecrets are placeholder secrets rejected by StrongSecret, compared
+// lower-cased and without separators.
+var weakSecrets = map[string]bool{
+	"changeme": true, "changeit": true, "password": true, "passw0rd": true,
+	"secret": true, "supersecret": true, "admin": true, "root": true,
+	"test": true, "testing": true, "dev": true, "development": true,
+	"default": true, "example": true, "placeholder": true, "letmein": true,
+	"qwerty": true, "123456": true, "12345678": true, "1234567890": true,
+	"todo": true, "fixme": true, "replaceme": true, "xxx": true,
+}
+
+// keyboardRows are the rows of a US keyboard, for spotting keyboard walks
+// such as "qwertyuiop" or "asdfgh".
+var keyboardRows = []string{"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./"}
+
+// MinEntropy returns a validator that checks the value carries at least
+// bits of entropy, e.g. MinEntropy(128) for a session signing key. The
+// estimate (see guessBits) charges little for placeholders, repeats,
+// runs such as "abcdef" or "123456" and keyboard walks such as "qwerty",
+// and never more than the value's length times the Shannon entropy of its
+// characters. It is rough: it catches short, patterned and dictionary-like
+// values, not every guessable one. The error never shows the value or its
+// estimate.
+func MinEntropy(bits float64) func(string) error {
+	return func(v string) error {
+		if guessBits(v) < bits {
+			return fmt.Errorf("must be a random secret of at least %g bits of entropy", bits)
+		}
+		return nil
+	}
+}
+
+// StrongSecret validates a secret in the production profile (see
+// Profile): it must not be or contain a well-known placeholder such as
+// "changeme", and must have at least 16 characters and about 48 bits of
+// entropy (see MinEntropy). In other profiles any value is accepted, so
+// that local setups can keep their throwaway dev secrets. The error never
+// shows the value.
+func StrongSecret(v string) error {
+	if !InProfile("prod", "production")() {
+		return nil
+	}
+	key := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, v)
+	if weakSecrets[key] || containsPlaceholder(key) {
+		return fmt.Errorf("is or contains a placeholder secret; set a real one in production")
+	}
+	if len([]rune(v)) < strongSecretLen {
+		return fmt.Errorf("must be at least %d characters in production", strongSecretLen)
+	}
+	if err := MinEntropy(strongSecretEntropy)(v); err != nil {
+		return fmt.Errorf("is too predictable for production; use a random secret")
+	}
+	return nil
+}
+
+// containsPlaceholder reports whether key, normalized as in StrongSecret,
+// contains a placeholder of at least 5 characters. Shorter ones such as
+// "dev" turn up in random secrets too often to reject outright; guessBits
+// still charges little for them.
+func containsPlaceholder(key string) bool {
+	for w := range weakSecrets {
+		if len(w) >= 5 && strings.Contains(key, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// guessBits estimates the bits of entropy of v. Each character costs the
+// bits of a random pick from the character classes v uses, unless it
+// starts a placeholder, which costs one pick from weakSecrets, starts a
+// repeat of an earlier substring, which costs where it starts, or
+// continues a run or keyboard walk from the previous character, which
+// costs one bit. The estimate is capped by the Shannon estimate.
+func guessBits(v string) float64 {
+	rs := []rune(strings.ToLower(v))
+	free := math.Log2(float64(charsetSize(v)))
+	placeholder := math.Log2(float64(len(weakSecrets)))
+
+	var bits float64
+	for i := 0; i < len(rs); {
+		if n := placeholderAt(rs[i:]); n > 0 {
+			bits += placeholder
+			i += n
+			continue
+		}
+		if n := repeatAt(rs, i); n > 0 {
+			bits += math.Log2(float64(i)) + 1
+			i += n
+			continue
+		}
+		if i > 0 && patterned(rs[i-1], rs[i]) {
+			bits++
+		} else {
+			bits += free
+		}
+		i++
+	}
+	return min(bits, entropy(v)*float64(len(v)))
+}
+
+// charsetSize returns the number of characters in the classes v uses.
+func charsetSize(v string) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range v {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < 0x80:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	n := 0
+	for _, c := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if c.used {
+			n += c.size
+		}
+	}
+	return max(n, 2)
+}
+
+// placeholderAt returns the length of the longest placeholder rs starts
+// with, or 0.
+func placeholderAt(rs []rune) int {
+	n := 0
+	for w := range weakSecrets {
+		if len(w) > n && len(w) <= len(rs) && string(rs[:len(w)]) == w {
+			n = len(w)
+		}
+	}
+	return n
+}
+
+// repeatAt returns the length of the longest substring of at least 3
+// characters starting at i that also starts earlier in rs, or 0.
+func repeatAt(rs []rune, i int) int {
+	n := 0
+	for l := 3; i+l <= len(rs); l++ {
+		if !strings.Contains(string(rs[:i+l-1]), string(rs[i:i+l])) {
+			break
+		}
+		n = l
+	}
+	return n
+}
+
+// patterned reports whether b continues a pattern from a: a repeat, a
+// run such as "ab" or "43", or a step along a keyboard row.
+func patterned(a, b rune) bool {
+	if d := b - a; d >= -1 && d <= 1 {
+		return true
+	}
+	for _, row := range keyboardRows {
+		i, j := strings.IndexRune(row, a), strings.IndexRune(row, b)
+		if i >= 0 && j >= 0 && (i-j == 1 || j-i == 1) {
+			return true
+		}
+	}
+	return false
+}
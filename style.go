@@ -0,0 +1,57 @@
+package envreq
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// OutputStyle selects the characters used in log messages and redaction
+// masks.
+type OutputStyle int32
+
+const (
+	// StyleAuto uses StyleASCII when ENVREQ_ASCII=1 and StyleUnicode
+	// otherwise. It is the default.
+	StyleAuto OutputStyle = iota
+	// StyleUnicode uses emoji markers in logs and bullets in masks.
+	StyleUnicode
+	// StyleASCII uses plain ASCII only, for log processors and terminals
+	// that mangle emoji.
+	StyleASCII
+)
+
+var outputStyle atomic.Int32
+
+// SetOutputStyle sets the output style of all registries.
+func SetOutputStyle(s OutputStyle) {
+	outputStyle.Store(int32(s))
+}
+
+// asciiOutput reports whether output must be plain ASCII.
+func asciiOutput() bool {
+	switch OutputStyle(outputStyle.Load()) {
+	case StyleASCII:
+		return true
+	case StyleUnicode:
+		return false
+	}
+	return os.Getenv("ENVREQ_ASCII") == "1"
+}
+
+// asciiReplacer maps the non-ASCII markers used in messages and masks to
+// plain ASCII.
+var asciiReplacer = strings.NewReplacer(
+	"⚠️  ", "WARNING: ",
+	"🚨 ", "ERROR: ",
+	"📋 ", "",
+	"•", "*",
+)
+
+// styled returns s in the configured output style.
+func styled(s string) string {
+	if asciiOutput() {
+		return asciiReplacer.Replace(s)
+	}
+	return s
+}
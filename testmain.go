@@ -0,0 +1,65 @@
+package envreq
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TestProfile is the profile TestMainHelper selects when none is set.
+const TestProfile = "test"
+
+// TestRunner runs a test binary's tests; *testing.M is one. Taking it
+// rather than *testing.M keeps the testing package out of every binary
+// that imports envreq.
+type TestRunner interface {
+	Run() int
+}
+
+// TestMainHelper validates the variables an integration test suite needs
+// before any test runs, and returns the exit code for os.Exit:
+//
+//	func TestMain(m *testing.M) {
+//	    os.Exit(envreq.TestMainHelper(m,
+//	        envreq.Requirement{Name: "TEST_DATABASE_URL", Source: "integration", Validate: envreq.PostgresDSN},
+//	    ))
+//	}
+//
+// The profile becomes TestProfile unless SetProfile, ENVREQ_PROFILE or
+// APP_ENV already chose one, so that InProfile("test") conditions apply.
+// The profile is process-wide, not per registry. When a requirement is
+// missing or invalid, the redacted report is written to the output set
+// with SetOutput and 2 is returned without running the tests; otherwise
+// m.Run's result is returned.
+func TestMainHelper(m TestRunner, reqs ...Requirement) int {
+	return std.TestMainHelper(m, reqs...)
+}
+
+// TestMainHelper validates reqs in the registry before running the tests.
+// See the package-level TestMainHelper; like it, this selects TestProfile
+// for the whole process, not only for g.
+func (g *Registry) TestMainHelper(m TestRunner, reqs ...Requirement) int {
+	if Profile() == "" {
+		SetProfile(TestProfile)
+	}
+	for _, req := range reqs {
+		g.Check(req)
+	}
+
+	results, err := g.ValidateResults()
+	if err == nil {
+		return m.Run()
+	}
+
+	out := g.output()
+	var verr *ValidationError
+	var groups map[string]string
+	if errors.As(err, &verr) {
+		groups = incompleteGroups(verr.Constraints)
+	}
+	reportPaged(out, results, 0, nil, showValues(), groups)
+	if verr != nil {
+		reportConstraints(out, verr.Constraints)
+	}
+	fmt.Fprintf(out, "\nTests not run: %v\n", err)
+	return 2
+}
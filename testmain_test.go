@@ -0,0 +1,49 @@
+package envreq_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestTestMainHelper(t *testing.T) {
+	t.Setenv("ENVREQ_PROFILE", "")
+	t.Setenv("APP_ENV", "")
+	t.Setenv("TM_DATABASE_URL", "")
+	defer envreq.SetProfile("")
+
+	var out bytes.Buffer
+	g := envreq.New()
+	g.SetOutput(&out)
+	// m is never run when validation fails.
+	code := g.TestMainHelper(nil,
+		envreq.Requirement{Name: "TM_DATABASE_URL", Source: "integration", Validate: envreq.PostgresDSN},
+		envreq.Requirement{Name: "TM_API_KEY", Source: "integration", Optional: true, Sensitive: true},
+	)
+	if code != 2 {
+		t.Errorf("Expected exit code 2, got %d", code)
+	}
+	if envreq.Profile() != envreq.TestProfile {
+		t.Errorf("Expected the test profile, got %q", envreq.Profile())
+	}
+	if !strings.Contains(out.String(), "TM_DATABASE_URL") || !strings.Contains(out.String(), "Tests not run") {
+		t.Errorf("Expected the report in the output:\n%s", out.String())
+	}
+
+	envreq.SetProfile("ci")
+	g.TestMainHelper(nil)
+	if envreq.Profile() != "ci" {
+		t.Errorf("Expected the chosen profile to be kept, got %q", envreq.Profile())
+	}
+
+	t.Setenv("TM_DATABASE_URL", "postgres://db:5432/app")
+	if code := envreq.New().TestMainHelper(runner(7), envreq.Requirement{Name: "TM_DATABASE_URL", Source: "integration", Validate: envreq.PostgresDSN}); code != 7 {
+		t.Errorf("Expected the tests' exit code, got %d", code)
+	}
+}
+
+type runner int
+
+func (r runner) Run() int { return int(r) }
@@ -0,0 +1,55 @@
+package envreq
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// TimeFormat controls how timestamps and durations appear in reports and
+// exports. Operators in different regions misread ambiguous local times
+// during incidents, so the default is UTC in RFC 3339.
+type TimeFormat struct {
+	Location *time.Location // zone timestamps are shown in; nil means UTC
+	Layout   string         // time.Format layout; "" means time.RFC3339
+	Round    time.Duration  // durations are rounded to this unit; 0 means time.Second
+}
+
+var timeFormat atomic.Pointer[TimeFormat]
+
+// SetTimeFormat sets the timestamp and duration format used by reports and
+// exports. The zero TimeFormat restores the defaults.
+func SetTimeFormat(f TimeFormat) {
+	timeFormat.Store(&f)
+}
+
+// formatTime renders t with the configured location and layout.
+func formatTime(t time.Time) string {
+	f := currentTimeFormat()
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(f.Location).Format(f.Layout)
+}
+
+// formatDuration renders d rounded to the configured unit.
+func formatDuration(d time.Duration) string {
+	return d.Round(currentTimeFormat().Round).String()
+}
+
+// currentTimeFormat returns the configured format with defaults filled in.
+func currentTimeFormat() TimeFormat {
+	var f TimeFormat
+	if p := timeFormat.Load(); p != nil {
+		f = *p
+	}
+	if f.Location == nil {
+		f.Location = time.UTC
+	}
+	if f.Layout == "" {
+		f.Layout = time.RFC3339
+	}
+	if f.Round <= 0 {
+		f.Round = time.Second
+	}
+	return f
+}
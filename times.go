@@ -0,0 +1,156 @@
+package envreq
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RFC3339 validates a timestamp such as "2024-03-10T01:30:00Z" or
+// "2024-03-10T01:30:00.5+01:00".
+func RFC3339(v string) error {
+	if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+		return fmt.Errorf("must be an RFC 3339 timestamp, e.g. 2024-03-10T01:30:00Z")
+	}
+	return nil
+}
+
+// TimeLayout returns a validator that checks the value parses with the
+// time.Parse layout, e.g. TimeLayout("15:04") for a daily cutoff or
+// TimeLayout(time.DateOnly).
+func TimeLayout(layout string) func(string) error {
+	return func(v string) error {
+		if _, err := time.Parse(layout, v); err != nil {
+			return fmt.Errorf("must be a time in the layout %q", layout)
+		}
+		return nil
+	}
+}
+
+// Timezone validates an IANA time zone name such as "Europe/Berlin" or
+// "UTC", using time.LoadLocation. "Local" is rejected since its meaning
+// depends on the host. Binaries running where the zone database may be
+// missing (scratch or distroless images, Windows) should import
+// time/tzdata.
+func Timezone(v string) error {
+	if v == "" || v == "Local" {
+		return fmt.Errorf("must be an IANA time zone name, e.g. Europe/Berlin")
+	}
+	if _, err := time.LoadLocation(v); err != nil {
+		return fmt.Errorf("unknown time zone (an IANA name such as Europe/Berlin)")
+	}
+	return nil
+}
+
+// CronExpr validates a cron schedule: five fields (minute, hour, day of
+// month, month, day of week), or six with a leading seconds field, each
+// "*", "?" (days only), a value, a range "1-5", a step "*/15" or "1-30/2",
+// or a comma-separated list of them. Months and weekdays may be named
+// (JAN, MON), and Sunday is 0 or 7. The descriptors @yearly, @annually,
+// @monthly, @weekly, @daily, @midnight, @hourly and "@every <duration>"
+// are accepted too.
+func CronExpr(v string) error {
+	if strings.HasPrefix(v, "@") {
+		return cronDescriptor(v)
+	}
+	fields := strings.Fields(v)
+	specs := cronFields
+	switch len(fields) {
+	case 5:
+		specs = specs[1:]
+	case 6:
+	default:
+		return fmt.Errorf("cron expression must have 5 fields (or 6 with seconds), got %d", len(fields))
+	}
+	for i, f := range fields {
+		if err := specs[i].check(f); err != nil {
+			return fmt.Errorf("cron %s field: %w", specs[i].name, err)
+		}
+	}
+	return nil
+}
+
+// cronDescriptor validates the @ forms of CronExpr.
+func cronDescriptor(v string) error {
+	switch v {
+	case "@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly":
+		return nil
+	}
+	if every, ok := strings.CutPrefix(v, "@every "); ok {
+		if d, err := time.ParseDuration(strings.TrimSpace(every)); err != nil || d <= 0 {
+			return fmt.Errorf("@every needs a positive duration, e.g. @every 5m")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown cron descriptor")
+}
+
+// cronField describes one field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // names of min, min+1, ...
+	question bool     // "?" is allowed
+}
+
+// cronFields are the fields of a six-field expression.
+var cronFields = []cronField{
+	{name: "second", max: 59},
+	{name: "minute", max: 59},
+	{name: "hour", max: 23},
+	{name: "day of month", min: 1, max: 31, question: true},
+	{name: "month", min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	{name: "day of week", max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}, question: true},
+}
+
+// check validates one field.
+func (c cronField) check(f string) error {
+	if f == "?" && c.question {
+		return nil
+	}
+	for _, part := range strings.Split(f, ",") {
+		rng, step, hasStep := strings.Cut(part, "/")
+		if hasStep {
+			if n, err := strconv.Atoi(step); err != nil || n < 1 {
+				return fmt.Errorf("step must be a positive number")
+			}
+		}
+		if rng == "*" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(rng, "-")
+		a, err := c.value(lo)
+		if err != nil {
+			return err
+		}
+		if !isRange {
+			continue
+		}
+		b, err := c.value(hi)
+		if err != nil {
+			return err
+		}
+		if a > b {
+			return fmt.Errorf("range start is after its end")
+		}
+	}
+	return nil
+}
+
+// value parses a number or name of the field.
+func (c cronField) value(s string) (int, error) {
+	for i, name := range c.names {
+		if strings.EqualFold(s, name) {
+			return c.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("must be a number, range, step or list")
+	}
+	if n < c.min || n > c.max {
+		return 0, fmt.Errorf("values must be between %d and %d", c.min, c.max)
+	}
+	return n, nil
+}
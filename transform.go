@@ -0,0 +1,38 @@
+package envreq
+
+import "strings"
+
+// Transforms for Requirement.Transform. strings.TrimSpace and
+// strings.ToLower can be used directly.
+
+// Transforms returns a Transform applying fs in order, e.g.
+// Transforms(strings.TrimSpace, TrimQuotes).
+func Transforms(fs ...func(string) string) func(string) string {
+	return func(v string) string {
+		for _, f := range fs {
+			v = f(v)
+		}
+		return v
+	}
+}
+
+// TrimQuotes removes one pair of matching surrounding quotes, single or
+// double, as left by values pasted from shell scripts or YAML.
+func TrimQuotes(v string) string {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// TrimTrailingSlash removes trailing slashes, e.g. from a base URL that
+// paths are appended to.
+func TrimTrailingSlash(v string) string {
+	return strings.TrimRight(v, "/")
+}
+
+// TrimSpaceAndQuotes trims surrounding whitespace, then quotes, then the
+// whitespace that was inside the quotes.
+func TrimSpaceAndQuotes(v string) string {
+	return strings.TrimSpace(TrimQuotes(strings.TrimSpace(v)))
+}
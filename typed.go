@@ -0,0 +1,173 @@
+package envreq
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMissing is returned by Get when a required variable is not set.
+var ErrMissing = errors.New("required variable is not set")
+
+// Get checks r like Check and returns its value parsed as T. The parsed
+// value is cached alongside the string, so repeated calls do not re-parse.
+//
+// Supported types are string, bool, int, int64, uint, uint64, float64,
+// time.Duration and *url.URL. A missing optional variable without a
+// default yields the zero value and no error. A missing required or an
+// invalid variable yields a *MissingError (errors.Is(err, ErrMissing)
+// holds for the former); parse errors are wrapped with the variable name.
+func Get[T any](r Requirement) (T, error) {
+	return get[T](std, r, 2)
+}
+
+// GetFrom is Get against a specific registry. (Go methods cannot take type
+// parameters, so this is a function rather than a Registry method.)
+func GetFrom[T any](g *Registry, r Requirement) (T, error) {
+	return get[T](g, r, 2)
+}
+
+func get[T any](g *Registry, r Requirement, skip int) (T, error) {
+	var zero T
+
+	res := g.check(r, skip)
+	if err := newMissingError(res); err != nil {
+		return zero, err
+	}
+	if !res.Present {
+		return zero, nil
+	}
+
+	g.mu.RLock()
+	cached := g.cache[res.Name]
+	g.mu.RUnlock()
+	if v, ok := cached.typed.(T); ok && cached.value == res.Value {
+		return v, nil
+	}
+
+	v, err := parseAs[T](res.Value)
+	if err != nil {
+		return zero, fmt.Errorf("envreq: %s: %w", res.Name, err)
+	}
+
+	g.mu.Lock()
+	// Only cache against the string it was parsed from; an override may
+	// have replaced the entry in the meantime.
+	if entry, ok := g.cache[res.Name]; ok && entry.value == res.Value {
+		entry.typed = v
+		g.cache[res.Name] = entry
+	}
+	g.mu.Unlock()
+
+	return v, nil
+}
+
+// parseAs converts s to T.
+func parseAs[T any](s string) (T, error) {
+	var zero T
+	var v any
+
+	var err error
+	switch any(zero).(type) {
+	case string:
+		v = s
+	case bool:
+		v, err = parseBool(s)
+	case int:
+		v, err = strconv.Atoi(s)
+	case int64:
+		v, err = strconv.ParseInt(s, 10, 64)
+	case uint:
+		var n uint64
+		n, err = strconv.ParseUint(s, 10, 0)
+		v = uint(n)
+	case uint64:
+		v, err = strconv.ParseUint(s, 10, 64)
+	case float64:
+		v, err = strconv.ParseFloat(s, 64)
+	case time.Duration:
+		v, err = time.ParseDuration(s)
+	case *url.URL:
+		v, err = url.Parse(s)
+		err = redactURLError(err)
+	default:
+		return zero, fmt.Errorf("unsupported type %T", zero)
+	}
+	if err != nil {
+		return zero, err
+	}
+	return v.(T), nil
+}
+
+// Int returns the value parsed as an int.
+func (r Result) Int() (int, error) {
+	return parseResult[int](r)
+}
+
+// Float returns the value parsed as a float64.
+func (r Result) Float() (float64, error) {
+	return parseResult[float64](r)
+}
+
+// Bytes returns the value parsed as a size in bytes, like the ByteSize
+// validator: "512MB" is 512000000 and "2GiB" 2147483648.
+func (r Result) Bytes() (int64, error) {
+	if err := newMissingError(r); err != nil {
+		return 0, err
+	}
+	if !r.Present {
+		return 0, fmt.Errorf("envreq: %s: %w", r.Name, ErrMissing)
+	}
+	n, err := parseByteSize(r.Value)
+	if err != nil {
+		return 0, fmt.Errorf("envreq: %s: %w", r.Name, err)
+	}
+	return n, nil
+}
+
+// Bool returns the value parsed like the Bool validator, so "True", "1",
+// "yes" and "on" all yield true.
+func (r Result) Bool() (bool, error) {
+	return parseResult[bool](r)
+}
+
+// Duration returns the value parsed with time.ParseDuration.
+func (r Result) Duration() (time.Duration, error) {
+	return parseResult[time.Duration](r)
+}
+
+// URL returns the value parsed with url.Parse.
+func (r Result) URL() (*url.URL, error) {
+	return parseResult[*url.URL](r)
+}
+
+// parseBool accepts the strconv.ParseBool forms plus yes/no and on/off,
+// ignoring case.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "1", "t", "true", "yes", "on":
+		return true, nil
+	case "0", "f", "false", "no", "off":
+		return false, nil
+	}
+	return false, errors.New("must be a boolean (true/false, 1/0, yes/no, on/off)")
+}
+
+// parseResult parses a Result's value, failing if it is absent or invalid.
+func parseResult[T any](r Result) (T, error) {
+	var zero T
+	if err := newMissingError(r); err != nil {
+		return zero, err
+	}
+	if !r.Present {
+		return zero, fmt.Errorf("envreq: %s: %w", r.Name, ErrMissing)
+	}
+	v, err := parseAs[T](r.Value)
+	if err != nil {
+		return zero, fmt.Errorf("envreq: %s: %w", r.Name, err)
+	}
+	return v, nil
+}
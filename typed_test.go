@@ -0,0 +1,82 @@
+package envreq_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestGet(t *testing.T) {
+	envreq.Reset()
+	defer envreq.Reset()
+	t.Setenv("GET_PORT", "8080")
+	t.Setenv("GET_TIMEOUT", "30s")
+	t.Setenv("GET_BAD_INT", "eighty")
+
+	port, err := envreq.Get[int](envreq.Requirement{Name: "GET_PORT", Source: "test"})
+	if err != nil || port != 8080 {
+		t.Errorf("Get[int] = %d, %v; want 8080", port, err)
+	}
+
+	// Cached typed value is returned for the same string
+	port, err = envreq.Get[int](envreq.Requirement{Name: "GET_PORT", Source: "test"})
+	if err != nil || port != 8080 {
+		t.Errorf("cached Get[int] = %d, %v; want 8080", port, err)
+	}
+
+	timeout, err := envreq.Get[time.Duration](envreq.Requirement{Name: "GET_TIMEOUT", Source: "test"})
+	if err != nil || timeout != 30*time.Second {
+		t.Errorf("Get[time.Duration] = %v, %v; want 30s", timeout, err)
+	}
+
+	if _, err := envreq.Get[int](envreq.Requirement{Name: "GET_BAD_INT", Source: "test"}); err == nil {
+		t.Error("Expected parse error for GET_BAD_INT")
+	}
+
+	debug, err := envreq.Get[bool](envreq.Requirement{Name: "GET_DEBUG", Source: "test", Optional: true})
+	if err != nil || debug {
+		t.Errorf("Optional missing Get[bool] = %v, %v; want false, nil", debug, err)
+	}
+
+	if _, err := envreq.Get[string](envreq.Requirement{Name: "GET_REQUIRED", Source: "test"}); !errors.Is(err, envreq.ErrMissing) {
+		t.Errorf("Expected ErrMissing, got %v", err)
+	}
+}
+
+func TestResultTypedAccessors(t *testing.T) {
+	envreq.Reset()
+	defer envreq.Reset()
+	t.Setenv("TYPED_URL", "https://api.example.com/v1")
+	t.Setenv("TYPED_FLAG", "true")
+
+	u, err := envreq.Check(envreq.Requirement{Name: "TYPED_URL", Source: "test"}).URL()
+	if err != nil || u.Host != "api.example.com" {
+		t.Errorf("URL() = %v, %v", u, err)
+	}
+	flag, err := envreq.Check(envreq.Requirement{Name: "TYPED_FLAG", Source: "test"}).Bool()
+	if err != nil || !flag {
+		t.Errorf("Bool() = %v, %v", flag, err)
+	}
+	for v, want := range map[string]bool{"Yes": true, "on": true, "1": true, "OFF": false, "no": false, "False": false} {
+		t.Setenv("TYPED_SWITCH", v)
+		envreq.Reset()
+		if got, err := envreq.Check(envreq.Requirement{Name: "TYPED_SWITCH", Source: "test"}).Bool(); err != nil || got != want {
+			t.Errorf("Bool() of %q = %v, %v, want %v", v, got, err, want)
+		}
+	}
+	if _, err := envreq.Check(envreq.Requirement{Name: "TYPED_FLAG", Source: "test"}).Int(); err == nil {
+		t.Error("Expected Int() to fail for a boolean value")
+	}
+	for v, want := range map[string]int64{"512MB": 512_000_000, "2GiB": 2 << 30, "64k": 64_000, "1.5 Ki": 1536, "100": 100} {
+		t.Setenv("TYPED_SIZE", v)
+		envreq.Reset()
+		if got, err := envreq.Check(envreq.Requirement{Name: "TYPED_SIZE", Source: "test"}).Bytes(); err != nil || got != want {
+			t.Errorf("Bytes() of %q = %v, %v, want %v", v, got, err, want)
+		}
+	}
+	if _, err := envreq.Check(envreq.Requirement{Name: "TYPED_FLAG", Source: "test"}).Bytes(); err == nil {
+		t.Error("Expected Bytes() to fail for a boolean value")
+	}
+}
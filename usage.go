@@ -0,0 +1,75 @@
+package envreq
+
+import "errors"
+
+// Usage is an anonymous summary of how the configuration was set in one
+// validation pass, delivered to the sink set with SetUsageSink. Aggregated
+// across a fleet it shows which knobs nobody sets and which validators
+// catch mistakes, so the configuration surface can shrink over time. It
+// never carries values, defaults included.
+type Usage struct {
+	Profile   string     // active profile, see Profile
+	Variables []VarUsage // sorted by name
+}
+
+// VarUsage is the usage of one variable.
+type VarUsage struct {
+	Name       string
+	Source     string
+	Set        bool   // a value or default was available
+	Defaulted  bool   // the value came from Default or DefaultFunc
+	Provenance string // where the value came from, e.g. ProvenanceEnv; empty when unset
+	Validator  string // name of the Validate function, e.g. "envreq.Port"; empty without one
+	Rejected   bool   // the validators rejected the value
+}
+
+// SetUsageSink opts the default registry in to usage reporting: after
+// every validation pass (Validate, MustValidate and their variants) sink
+// receives the Usage of the pass. Nothing is collected or sent by envreq
+// itself; the sink decides where the data goes, e.g. a metrics pipeline
+// or a log line. It runs synchronously, so it should hand slow work off.
+// Pass nil to opt out again.
+//
+//	envreq.SetUsageSink(func(u envreq.Usage) {
+//	    for _, v := range u.Variables {
+//	        usageCounter.WithLabelValues(v.Name, strconv.FormatBool(v.Defaulted)).Inc()
+//	    }
+//	})
+func SetUsageSink(sink func(Usage)) {
+	std.SetUsageSink(sink)
+}
+
+// SetUsageSink sets the registry's usage sink. See the package-level
+// SetUsageSink.
+func (g *Registry) SetUsageSink(sink func(Usage)) {
+	g.ioMu.Lock()
+	g.usage = sink
+	g.ioMu.Unlock()
+}
+
+// reportUsage delivers the usage of results to the sink, if any.
+func (g *Registry) reportUsage(results []Result) {
+	g.ioMu.RLock()
+	sink := g.usage
+	g.ioMu.RUnlock()
+	if sink == nil {
+		return
+	}
+
+	u := Usage{Profile: Profile(), Variables: make([]VarUsage, len(results))}
+	for i, res := range results {
+		v := VarUsage{
+			Name:      res.Name,
+			Source:    res.Source,
+			Set:       res.Present,
+			Defaulted: res.Present && res.Provenance == ProvenanceDefault,
+			Validator: validatorName(res.Validate),
+			Rejected:  res.Present && res.Err != nil && !errors.Is(res.Err, ErrTimeout),
+		}
+		if res.Present {
+			v.Provenance = res.Provenance
+		}
+		u.Variables[i] = v
+	}
+	sink(u)
+}
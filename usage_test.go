@@ -0,0 +1,46 @@
+package envreq_test
+
+import (
+	"testing"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestUsageSink(t *testing.T) {
+	t.Setenv("USG_PORT", "99999")
+	t.Setenv("USG_TOKEN", "s3cr3t")
+
+	g := envreq.New()
+	g.Check(envreq.Requirement{Name: "USG_PORT", Source: "server", Optional: true, Validate: envreq.Port})
+	g.Check(envreq.Requirement{Name: "USG_TIMEOUT", Source: "server", Default: "5s", Validate: envreq.Duration})
+	g.Check(envreq.Requirement{Name: "USG_TOKEN", Source: "api", Sensitive: true})
+	g.Check(envreq.Requirement{Name: "USG_UNUSED", Source: "api", Optional: true})
+
+	if err := g.Validate(); err != nil {
+		t.Fatalf("Expected no sink to be needed, got %v", err)
+	}
+
+	var got []envreq.Usage
+	g.SetUsageSink(func(u envreq.Usage) { got = append(got, u) })
+	g.Validate()
+	if len(got) != 1 || len(got[0].Variables) != 4 {
+		t.Fatalf("Expected one usage report of 4 variables, got %+v", got)
+	}
+	want := []envreq.VarUsage{
+		{Name: "USG_PORT", Source: "server", Set: true, Provenance: envreq.ProvenanceEnv, Validator: "envreq.Port", Rejected: true},
+		{Name: "USG_TIMEOUT", Source: "server", Set: true, Defaulted: true, Provenance: envreq.ProvenanceDefault, Validator: "envreq.Duration"},
+		{Name: "USG_TOKEN", Source: "api", Set: true, Provenance: envreq.ProvenanceEnv},
+		{Name: "USG_UNUSED", Source: "api"},
+	}
+	for i, v := range got[0].Variables {
+		if v != want[i] {
+			t.Errorf("Expected %+v, got %+v", want[i], v)
+		}
+	}
+
+	g.SetUsageSink(nil)
+	g.Validate()
+	if len(got) != 1 {
+		t.Errorf("Expected no reports after opting out, got %d", len(got))
+	}
+}
@@ -0,0 +1,157 @@
+package envreq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Problem is one required variable that is missing or invalid.
+type Problem struct {
+	Name    string // variable name
+	Source  string // owning package/component
+	Missing bool   // not set and no default
+	Err     error  // validator error when invalid
+}
+
+func (p Problem) String() string {
+	if p.Missing {
+		return p.Name + " (missing)"
+	}
+	return fmt.Sprintf("%s (invalid: %v)", p.Name, p.Err)
+}
+
+// Error makes a Problem usable as an error, e.g. one element of
+// ValidationError.Unwrap.
+func (p Problem) Error() string {
+	return "envreq: " + p.String()
+}
+
+// Unwrap returns ErrMissing for a missing variable and the validator error
+// otherwise, so errors.Is(err, ErrMissing) finds missing variables in a
+// *ValidationError.
+func (p Problem) Unwrap() error {
+	if p.Missing {
+		return ErrMissing
+	}
+	return p.Err
+}
+
+// ValidationError is returned by Validate when required variables are
+// missing or invalid, group constraints are violated, or the registry
+// drifts from the schema named by ENVREQ_SCHEMA.
+type ValidationError struct {
+	Problems    []Problem         // missing/invalid required variables, sorted by name
+	Constraints []ConstraintError // violated group constraints, in registration order
+	Drift       *SchemaDriftError // schema drift, if any
+	Skipped     []Stage           // stages not run because an earlier one failed, see SetStopSeverity
+	Warnings    []ConstraintError // failed deep checks of SeverityWarning, or the warnings that stopped validation; alone they do not fail it
+}
+
+// failed reports whether e holds anything but warnings.
+func (e *ValidationError) failed() bool {
+	return len(e.Problems) > 0 || len(e.Constraints) > 0 || e.Drift != nil || len(e.Skipped) > 0
+}
+
+func (e *ValidationError) Error() string {
+	var parts []string
+	if len(e.Problems) > 0 {
+		names := make([]string, len(e.Problems))
+		for i, p := range e.Problems {
+			names[i] = p.String()
+		}
+		parts = append(parts, fmt.Sprintf("%d required environment variable(s) missing or invalid: %s",
+			len(e.Problems), strings.Join(names, ", ")))
+	}
+	if len(e.Constraints) > 0 {
+		msgs := make([]string, len(e.Constraints))
+		for i, c := range e.Constraints {
+			msgs[i] = c.Err.Error()
+		}
+		parts = append(parts, fmt.Sprintf("%d constraint(s) violated: %s", len(e.Constraints), strings.Join(msgs, "; ")))
+	}
+	if e.Drift != nil {
+		parts = append(parts, e.Drift.Error())
+	}
+	if len(e.Skipped) > 0 {
+		stopped := "validation stopped before the " + stageList(e.Skipped) + " stage(s)"
+		if len(parts) == 0 && len(e.Warnings) > 0 {
+			msgs := make([]string, len(e.Warnings))
+			for i, w := range e.Warnings {
+				msgs[i] = w.Err.Error()
+			}
+			stopped += fmt.Sprintf(" by %d warning(s): %s", len(e.Warnings), strings.Join(msgs, "; "))
+		}
+		parts = append(parts, stopped)
+	}
+	return "envreq: " + strings.TrimPrefix(strings.Join(parts, "; "), "envreq: ")
+}
+
+// Unwrap returns every problem, violated constraint and the schema drift
+// as distinct errors, in that order. errors.Is and errors.As look through
+// them, and error reporting tools that understand multi-errors (as built
+// by errors.Join) can report each one separately:
+//
+//	for _, e := range verr.Unwrap() {
+//	    var p envreq.Problem
+//	    if errors.As(e, &p) { ... }
+//	}
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Problems)+len(e.Constraints)+1)
+	for _, p := range e.Problems {
+		errs = append(errs, p)
+	}
+	for i := range e.Constraints {
+		errs = append(errs, &e.Constraints[i])
+	}
+	if e.Drift != nil {
+		errs = append(errs, e.Drift)
+	}
+	return errs
+}
+
+// Validate checks all registered variables and returns a *ValidationError
+// describing every missing or invalid required variable, or nil. Unlike
+// MustValidate it never exits, so libraries, tests and servers can decide
+// whether to exit, log, or report unhealthy.
+func Validate() error {
+	return std.Validate()
+}
+
+// Validate checks all variables of the registry. See the package-level Validate.
+func (g *Registry) Validate() error {
+	_, err := g.ValidateResults()
+	return err
+}
+
+// ValidateResults is like Validate but also returns the results, e.g. to
+// render a Report alongside the error.
+func ValidateResults() ([]Result, error) {
+	return std.ValidateResults()
+}
+
+// ValidateResults checks all variables of the registry and returns the
+// results together with a *ValidationError, or nil.
+func (g *Registry) ValidateResults() ([]Result, error) {
+	return g.ValidateResultsContext(context.Background())
+}
+
+// problems returns the missing or invalid required variables of results.
+// Variables registered only with FirstUse fail at their use instead.
+func problems(results []Result) []Problem {
+	var out []Problem
+	for _, res := range results {
+		if res.Optional || res.firstUse {
+			continue
+		}
+		if errors.Is(res.Err, ErrTimeout) {
+			out = append(out, Problem{Name: res.Name, Source: res.Source, Err: res.Err})
+		} else if !res.Present {
+			out = append(out, Problem{Name: res.Name, Source: res.Source, Missing: true})
+		} else if res.Err != nil {
+			out = append(out, Problem{Name: res.Name, Source: res.Source, Err: res.Err})
+		}
+	}
+	return out
+}
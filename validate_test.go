@@ -0,0 +1,128 @@
+package envreq_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bbmumford/envreq"
+)
+
+func TestValidate(t *testing.T) {
+	g := envreq.New()
+	t.Setenv("VALIDATE_OK", "https://example.com")
+	t.Setenv("VALIDATE_BAD", "not-a-url")
+
+	g.Check(envreq.Requirement{Name: "VALIDATE_OK", Source: "test", Validate: envreq.URL})
+	if err := g.Validate(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	g.Check(envreq.Requirement{Name: "VALIDATE_BAD", Source: "test", Validate: envreq.URL})
+	g.Check(envreq.Requirement{Name: "VALIDATE_MISSING", Source: "test"})
+	g.Check(envreq.Requirement{Name: "VALIDATE_OPTIONAL", Source: "test", Optional: true})
+
+	results, err := g.ValidateResults()
+	if len(results) != 4 {
+		t.Errorf("Expected 4 results, got %d", len(results))
+	}
+
+	var verr *envreq.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *ValidationError, got %v", err)
+	}
+	if len(verr.Problems) != 2 {
+		t.Fatalf("Expected 2 problems, got %v", verr.Problems)
+	}
+	if verr.Problems[0].Name != "VALIDATE_BAD" || verr.Problems[0].Err == nil {
+		t.Errorf("Expected VALIDATE_BAD invalid, got %+v", verr.Problems[0])
+	}
+	if verr.Problems[1].Name != "VALIDATE_MISSING" || !verr.Problems[1].Missing {
+		t.Errorf("Expected VALIDATE_MISSING missing, got %+v", verr.Problems[1])
+	}
+	if !strings.Contains(err.Error(), "2 required environment variable(s)") {
+		t.Errorf("Unexpected message: %v", err)
+	}
+}
+
+func TestValidationErrorUnwrap(t *testing.T) {
+	g := envreq.New()
+	t.Setenv("MULTI_BAD", "not-a-url")
+	t.Setenv("MULTI_CERT", "cert.pem")
+	g.Check(envreq.Requirement{Name: "MULTI_BAD", Source: "test", Validate: envreq.URL})
+	g.Check(envreq.Requirement{Name: "MULTI_MISSING", Source: "test"})
+	g.AllOrNone("MULTI_CERT", "MULTI_KEY")
+
+	err := g.Validate()
+	if !errors.Is(err, envreq.ErrMissing) {
+		t.Errorf("Expected errors.Is to find the missing variable in %v", err)
+	}
+	var cerr *envreq.ConstraintError
+	if !errors.As(err, &cerr) || cerr.Names[0] != "MULTI_CERT" {
+		t.Errorf("Expected errors.As to find the constraint, got %v", cerr)
+	}
+
+	multi, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("Expected a multi-error, got %T", err)
+	}
+	var got []string
+	for _, e := range multi.Unwrap() {
+		got = append(got, e.Error())
+	}
+	want := []string{
+		"envreq: MULTI_BAD (invalid: URL must have a scheme (http/https))",
+		"envreq: MULTI_MISSING (missing)",
+		"envreq: MULTI_CERT, MULTI_KEY must be set together (MULTI_KEY not set)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unwrap:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidateContextTimeout(t *testing.T) {
+	g := envreq.New()
+	g.Check(envreq.Requirement{Name: "CTX_FAST", Source: "test"})
+	g.Check(envreq.Requirement{Name: "CTX_SLOW", Source: "test"})
+	g.Check(envreq.Requirement{Name: "CTX_SLOW_OPTIONAL", Source: "test", Optional: true})
+
+	release := make(chan struct{})
+	defer close(release)
+	// SetSources drops the cache, so the next validation looks values up again
+	g.SetSources(envreq.SourceFunc(func(name string) (string, bool, error) {
+		if strings.HasPrefix(name, "CTX_SLOW") {
+			<-release
+		}
+		return "value", true, nil
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	results, err := g.ValidateResultsContext(ctx)
+
+	var buf bytes.Buffer
+	envreq.ReportJSON(&buf, results)
+	var rep envreq.JSONReport
+	if err := json.Unmarshal(buf.Bytes(), &rep); err != nil {
+		t.Fatal(err)
+	}
+	status := map[string]string{}
+	for _, e := range rep.Vars {
+		status[e.Name] = e.Status
+	}
+	if status["CTX_FAST"] != envreq.StatusOK || status["CTX_SLOW"] != envreq.StatusTimeout || status["CTX_SLOW_OPTIONAL"] != envreq.StatusTimeout {
+		t.Errorf("Unexpected statuses %v", status)
+	}
+
+	var verr *envreq.ValidationError
+	if !errors.As(err, &verr) || len(verr.Problems) != 1 || verr.Problems[0].Missing {
+		t.Fatalf("Expected one timed out problem, got %v", err)
+	}
+	if !errors.Is(err, envreq.ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected ErrTimeout wrapping the context error, got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package envreq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// ErrTimeout is the error of a variable whose lookup did not finish before
+// the context of ValidateContext or MustValidateContext was done. Its
+// report status is StatusTimeout.
+var ErrTimeout = errors.New("lookup timed out")
+
+// ValidateContext is like Validate, but bounds the validation pass by ctx:
+// variables whose lookups (e.g. remote secret stores) have not finished
+// when ctx is done are reported with ErrTimeout instead of blocking.
+func ValidateContext(ctx context.Context) error {
+	_, err := std.ValidateResultsContext(ctx)
+	return err
+}
+
+// ValidateContext is like Validate, bounded by ctx. See the package-level
+// ValidateContext.
+func (g *Registry) ValidateContext(ctx context.Context) error {
+	_, err := g.ValidateResultsContext(ctx)
+	return err
+}
+
+// ValidateResultsContext is like ValidateResults, bounded by ctx.
+func ValidateResultsContext(ctx context.Context) ([]Result, error) {
+	return std.ValidateResultsContext(ctx)
+}
+
+// ValidateResultsContext checks all variables of the registry like
+// ValidateResults, bounded by ctx. Lookups still running when ctx is done
+// are abandoned, not cancelled: Source has no context, so they finish in
+// the background and a later Check sees their value.
+func (g *Registry) ValidateResultsContext(ctx context.Context) ([]Result, error) {
+	results, verr, err := g.validate(ctx)
+	switch {
+	case err != nil:
+		return results, err
+	case verr.failed():
+		return results, verr
+	}
+	return results, nil
+}
+
+// validate runs the validation pass. verr is never nil, so that the
+// warnings of a passing validation reach MustValidate; err is an error
+// reading the ENVREQ_SCHEMA file.
+func (g *Registry) validate(ctx context.Context) (results []Result, verr *ValidationError, err error) {
+	results = g.checkAllContext(ctx)
+	verr = &ValidationError{}
+	results, verr.Constraints, verr.Warnings, verr.Skipped = g.runStages(ctx, results)
+	verr.Problems = problems(results)
+	g.reportUsage(results)
+
+	if path := os.Getenv("ENVREQ_SCHEMA"); path != "" {
+		if err := g.VerifySchema(path); err != nil {
+			if !errors.As(err, &verr.Drift) {
+				return results, verr, err
+			}
+		}
+	}
+	return results, verr, nil
+}
+
+// runStages runs the constraints and deep stages of the validation pass
+// over the syntax stage's results, stopping early as set with
+// SetStopSeverity. It returns the results with deep validation failures
+// recorded, the violated constraints, the deep check warnings (or the
+// warnings that stopped the pass) and the stages skipped.
+func (g *Registry) runStages(ctx context.Context, results []Result) ([]Result, []ConstraintError, []ConstraintError, []Stage) {
+	if g.stops(results, nil) {
+		// Only warnings can stop the pass this early without a problem to
+		// show, so report them as the reason
+		var warnings []ConstraintError
+		if severity(results, nil) == SeverityWarning {
+			warnings = resultWarnings(results)
+		}
+		return results, nil, warnings, []Stage{StageConstraints, StageDeep}
+	}
+
+	constraints, ok := withContext(ctx, func() []ConstraintError { return g.constraintErrors(results) })
+	if !ok {
+		constraints = []ConstraintError{{Err: fmt.Errorf("group constraints not evaluated: %w", timeoutError(ctx))}}
+	}
+	if g.stops(results, constraints) {
+		return results, constraints, nil, []Stage{StageDeep}
+	}
+
+	checked, errs, warnings := g.deepStage(ctx, results)
+	return checked, append(constraints, errs...), warnings, nil
+}
+
+// MustValidateContext is like MustValidate, but bounds the validation pass,
+// including source lookups and the rollback check, by ctx. Timed out
+// variables are reported with status "timeout" and, when required, fail
+// validation like missing ones, so startup never hangs on a slow provider:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	envreq.MustValidateContext(ctx)
+func MustValidateContext(ctx context.Context) {
+	std.MustValidateContext(ctx)
+}
+
+// MustValidateContext is like MustValidate, bounded by ctx. See the
+// package-level MustValidateContext.
+func (g *Registry) MustValidateContext(ctx context.Context) {
+	g.mustValidate(ctx)
+}
+
+// checkAllContext is CheckAll bounded by ctx. Uncached variables are
+// checked concurrently; those not done in time get ErrTimeout and are left
+// uncached.
+func (g *Registry) checkAllContext(ctx context.Context) []Result {
+	if ctx.Done() == nil {
+		return g.CheckAll()
+	}
+
+	g.mu.RLock()
+	out := make([]Result, 0, len(g.reg))
+	var unchecked []Requirement
+	for name, req := range g.reg {
+		if res, ok := g.cache[name]; ok {
+			out = append(out, res.result(req))
+		} else {
+			unchecked = append(unchecked, req)
+		}
+	}
+	g.mu.RUnlock()
+
+	type done struct {
+		i   int
+		res Result
+	}
+	ch := make(chan done, len(unchecked))
+	for i, req := range unchecked {
+		go func() {
+			ch <- done{i, g.check(req, 1)}
+		}()
+	}
+
+	pending := make(map[int]bool, len(unchecked))
+	for i := range unchecked {
+		pending[i] = true
+	}
+wait:
+	for len(pending) > 0 {
+		select {
+		case d := <-ch:
+			delete(pending, d.i)
+			out = append(out, d.res)
+		case <-ctx.Done():
+			break wait
+		}
+	}
+	for i := range pending {
+		out = append(out, Result{
+			Requirement: unchecked[i],
+			ResolvedAt:  time.Now(),
+			Err:         timeoutError(ctx),
+		})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// withContext runs f and returns its result, or false when ctx was done
+// first. An unfinished f keeps running in the background.
+func withContext[T any](ctx context.Context, f func() T) (T, bool) {
+	if ctx.Done() == nil {
+		return f(), true
+	}
+	ch := make(chan T, 1)
+	go func() {
+		ch <- f()
+	}()
+	select {
+	case v := <-ch:
+		return v, true
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	}
+}
+
+// timeoutError wraps ErrTimeout with the reason ctx is done.
+func timeoutError(ctx context.Context) error {
+	return fmt.Errorf("%w: %w", ErrTimeout, ctx.Err())
+}
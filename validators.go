@@ -1,10 +1,19 @@
 package envreq
 
 import (
+	"encoding/base64"
 	"fmt"
+	"math"
+	"net"
 	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // URL validates that the value is a valid URL.
@@ -15,7 +24,7 @@ func URL(v string) error {
 
 	parsed, err := url.Parse(v)
 	if err != nil {
-		return fmt.Errorf("invalid URL: %w", err)
+		return fmt.Errorf("invalid URL: %w", redactURLError(err))
 	}
 
 	if parsed.Scheme == "" {
@@ -29,6 +38,168 @@ func URL(v string) error {
 	return nil
 }
 
+// HTTPSOnly validates that the value is a URL (see URL) with the https
+// scheme.
+func HTTPSOnly(v string) error {
+	if err := URL(v); err != nil {
+		return err
+	}
+	if parsed, _ := url.Parse(v); !strings.EqualFold(parsed.Scheme, "https") {
+		return fmt.Errorf("URL scheme must be https, got %q", parsed.Scheme)
+	}
+	return nil
+}
+
+// URLHostIn returns a validator that checks the value is a URL whose host
+// is one of hosts, ignoring the port and case. A host of the form
+// "*.example.com" allows any subdomain of example.com.
+func URLHostIn(hosts ...string) func(string) error {
+	return func(v string) error {
+		parsed, err := url.Parse(v)
+		if err != nil {
+			return fmt.Errorf("invalid URL: %w", redactURLError(err))
+		}
+		host := strings.ToLower(parsed.Hostname())
+		for _, h := range hosts {
+			h = strings.ToLower(h)
+			if host == h || (strings.HasPrefix(h, "*.") && strings.HasSuffix(host, h[1:])) {
+				return nil
+			}
+		}
+		return fmt.Errorf("URL host %q is not one of: %s", host, strings.Join(hosts, ", "))
+	}
+}
+
+// URLPathPrefix returns a validator that checks the value is a URL whose
+// path starts with prefix, e.g. "/api/v2/".
+func URLPathPrefix(prefix string) func(string) error {
+	return func(v string) error {
+		parsed, err := url.Parse(v)
+		if err != nil {
+			return fmt.Errorf("invalid URL: %w", redactURLError(err))
+		}
+		if !strings.HasPrefix(parsed.Path, prefix) {
+			return fmt.Errorf("URL path %q must start with %q", parsed.Path, prefix)
+		}
+		return nil
+	}
+}
+
+// URLNoCredentials validates that the value is a URL without user info
+// (user:pass@host). Credentials in a URL escape Sensitive handling, since
+// the URL itself is usually not marked sensitive; pass them in their own
+// variables instead.
+func URLNoCredentials(v string) error {
+	parsed, err := url.Parse(v)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", redactURLError(err))
+	}
+	if parsed.User != nil {
+		return fmt.Errorf("URL must not contain credentials (user:pass@)")
+	}
+	return nil
+}
+
+// URLOption is a rule of URLWith.
+type URLOption func(*urlRules)
+
+type urlRules struct {
+	schemes      []string // allowed schemes, when restricted
+	restricted   bool     // a scheme option was given
+	noUserinfo   bool
+	noTrailSlash bool
+}
+
+// allowSchemes narrows the allowed schemes to those also in schemes.
+func (r *urlRules) allowSchemes(schemes ...string) {
+	if r.restricted {
+		schemes = slices.DeleteFunc(slices.Clone(schemes), func(s string) bool {
+			return !slices.ContainsFunc(r.schemes, func(t string) bool { return strings.EqualFold(s, t) })
+		})
+	}
+	r.schemes, r.restricted = schemes, true
+}
+
+// URLWith returns a validator that checks the value is a URL (see URL)
+// following the rules of opts, e.g. for a webhook target:
+//
+//	Validate: envreq.URLWith(envreq.RequireHTTPS, envreq.ForbidUserinfo)
+//
+// Plain URL accepts any scheme, ftp:// included, and embedded
+// credentials. Errors never show the URL.
+func URLWith(opts ...URLOption) func(string) error {
+	var rules urlRules
+	for _, opt := range opts {
+		opt(&rules)
+	}
+	return func(v string) error {
+		if v == "" {
+			return fmt.Errorf("URL cannot be empty")
+		}
+		parsed, err := url.Parse(v)
+		if err != nil {
+			return fmt.Errorf("invalid URL: %w", redactURLError(err))
+		}
+		if parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("URL must have a scheme and a host")
+		}
+		if rules.restricted && len(rules.schemes) == 0 {
+			return fmt.Errorf("no URL scheme is allowed by all the scheme options")
+		}
+		if rules.restricted && !slices.ContainsFunc(rules.schemes, func(s string) bool { return strings.EqualFold(s, parsed.Scheme) }) {
+			return fmt.Errorf("URL scheme must be one of: %s", strings.Join(rules.schemes, ", "))
+		}
+		if rules.noUserinfo && parsed.User != nil {
+			return fmt.Errorf("URL must not contain credentials (user:pass@)")
+		}
+		if rules.noTrailSlash && strings.HasSuffix(parsed.Path, "/") {
+			return fmt.Errorf("URL must not end with a slash")
+		}
+		return nil
+	}
+}
+
+// Schemes allows only the given URL schemes, e.g. Schemes("https", "wss").
+// Scheme options narrow each other: with several, only the schemes all of
+// them allow are accepted, so Schemes("http", "https") with RequireHTTPS
+// allows https alone.
+func Schemes(schemes ...string) URLOption {
+	return func(r *urlRules) {
+		r.allowSchemes(schemes...)
+	}
+}
+
+// RequireHTTPS allows only the https scheme, like Schemes("https").
+var RequireHTTPS URLOption = Schemes("https")
+
+// ForbidUserinfo rejects URLs with user info (user:pass@host); see
+// URLNoCredentials.
+var ForbidUserinfo URLOption = func(r *urlRules) {
+	r.noUserinfo = true
+}
+
+// ForbidTrailingSlash rejects URLs whose path ends with a slash, so that
+// base URLs are written one way and path joining cannot produce "//". To
+// accept such values and strip the slash instead, set
+// Transform: TrimTrailingSlash.
+var ForbidTrailingSlash URLOption = func(r *urlRules) {
+	r.noTrailSlash = true
+}
+
+// NormalizeTrailingSlash is ForbidTrailingSlash. A validator cannot change
+// the value, so it enforces the normal form by rejecting a trailing slash;
+// combine it with Transform: TrimTrailingSlash to strip the slash instead.
+var NormalizeTrailingSlash = ForbidTrailingSlash
+
+// redactURLError drops the input from a url.Parse error, which may contain
+// credentials.
+func redactURLError(err error) error {
+	if uerr, ok := err.(*url.Error); ok {
+		return uerr.Err
+	}
+	return err
+}
+
 // Duration validates that the value is a valid Go duration string.
 func Duration(v string) error {
 	if v == "" {
@@ -55,6 +226,20 @@ func OneOf(options ...string) func(string) error {
 	}
 }
 
+// Matches returns a validator that checks the whole value matches the
+// regular expression pattern, e.g. Matches(`[a-z][a-z0-9-]{2,62}`) for a
+// bucket name. The pattern is compiled once; an invalid pattern panics,
+// like regexp.MustCompile. The error shows the pattern, never the value.
+func Matches(pattern string) func(string) error {
+	re := regexp.MustCompile(`^(?:` + pattern + `)$`)
+	return func(v string) error {
+		if !re.MatchString(v) {
+			return fmt.Errorf("must match the pattern %s", pattern)
+		}
+		return nil
+	}
+}
+
 // NotEmpty validates that the value is not empty or only whitespace.
 func NotEmpty(v string) error {
 	if strings.TrimSpace(v) == "" {
@@ -63,53 +248,531 @@ func NotEmpty(v string) error {
 	return nil
 }
 
-// Port validates that the value is a valid port number (1-65535).
-func Port(v string) error {
-	if v == "" {
-		return fmt.Errorf("port cannot be empty")
+// MinLen returns a validator that checks the value has at least n
+// characters, e.g. MinLen(32) to catch an API key pasted without its tail.
+// The error never shows the value or its length.
+func MinLen(n int) func(string) error {
+	return func(v string) error {
+		if utf8.RuneCountInString(v) < n {
+			return fmt.Errorf("must be at least %d characters", n)
+		}
+		return nil
+	}
+}
+
+// MaxLen returns a validator that checks the value has at most n
+// characters.
+func MaxLen(n int) func(string) error {
+	return func(v string) error {
+		if utf8.RuneCountInString(v) > n {
+			return fmt.Errorf("must be at most %d characters", n)
+		}
+		return nil
+	}
+}
+
+// ASCIIOnly validates that the value contains only printable ASCII
+// characters, catching smart quotes and non-breaking spaces picked up
+// when a secret is copied from a document or chat.
+func ASCIIOnly(v string) error {
+	for i := 0; i < len(v); i++ {
+		if v[i] < 0x20 || v[i] > 0x7e {
+			return fmt.Errorf("must contain only printable ASCII characters")
+		}
+	}
+	return nil
+}
+
+// NoWhitespace validates that the value contains no whitespace, such as
+// a trailing newline from a copied token.
+func NoWhitespace(v string) error {
+	if strings.IndexFunc(v, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("must not contain whitespace")
+	}
+	return nil
+}
+
+// Bool validates a feature-flag style boolean: the strconv.ParseBool forms
+// plus yes/no and on/off, in any case. Result.Bool returns the canonical
+// value.
+func Bool(v string) error {
+	_, err := parseBool(v)
+	return err
+}
+
+// Int validates that the value is a decimal integer.
+func Int(v string) error {
+	if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+		return fmt.Errorf("must be an integer")
+	}
+	return nil
+}
+
+// IntRange returns a validator that checks the value is a decimal integer
+// between min and max inclusive, e.g. IntRange(1, 100) for a pool size.
+func IntRange(min, max int) func(string) error {
+	return func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		if n < min || n > max {
+			return fmt.Errorf("must be between %d and %d", min, max)
+		}
+		return nil
+	}
+}
+
+// Float validates that the value is a finite decimal number.
+func Float(v string) error {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return fmt.Errorf("must be a number")
+	}
+	return nil
+}
+
+// FloatRange returns a validator that checks the value is a finite number
+// between min and max inclusive, e.g. FloatRange(0, 1) for a sampling rate.
+func FloatRange(min, max float64) func(string) error {
+	return func(v string) error {
+		if err := Float(v); err != nil {
+			return err
+		}
+		f, _ := strconv.ParseFloat(v, 64)
+		if f < min || f > max {
+			return fmt.Errorf("must be between %g and %g", min, max)
+		}
+		return nil
+	}
+}
+
+// ByteSize validates a human-readable size such as "512MB", "2GiB", "64k"
+// or "1048576" (bytes). Decimal units (k/KB, M/MB, G/GB, T/TB, P/PB) are
+// powers of 1000 and binary units (Ki/KiB, Mi/MiB, ...) powers of 1024, as
+// in Kubernetes quantities; units ignore case and may follow a space.
+// Result.Bytes returns the parsed size.
+func ByteSize(v string) error {
+	_, err := parseByteSize(v)
+	return err
+}
+
+// byteUnits maps lower-cased unit suffixes to their size.
+var byteUnits = map[string]float64{
+	"": 1, "b": 1,
+	"k": 1e3, "kb": 1e3, "ki": 1 << 10, "kib": 1 << 10,
+	"m": 1e6, "mb": 1e6, "mi": 1 << 20, "mib": 1 << 20,
+	"g": 1e9, "gb": 1e9, "gi": 1 << 30, "gib": 1 << 30,
+	"t": 1e12, "tb": 1e12, "ti": 1 << 40, "tib": 1 << 40,
+	"p": 1e15, "pb": 1e15, "pi": 1 << 50, "pib": 1 << 50,
+}
+
+// parseByteSize parses a size accepted by ByteSize.
+func parseByteSize(v string) (int64, error) {
+	s := strings.TrimSpace(v)
+	i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i < 0 {
+		i = len(s)
+	}
+	num, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || num == "" {
+		return 0, fmt.Errorf("must be a size such as 512MB or 2GiB")
+	}
+	mult, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit (use B, KB, MB, GB, TB, PB or KiB, MiB, GiB, TiB, PiB)")
+	}
+	size := n * mult
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("size is too large")
+	}
+	if size != math.Trunc(size) {
+		return 0, fmt.Errorf("size must be a whole number of bytes")
+	}
+	return int64(size), nil
+}
+
+// HostPort validates a "host:port" address such as "kafka-1:9092" or
+// "[::1]:8080": an IP address or Hostname, and a valid port.
+func HostPort(v string) error {
+	host, port, err := net.SplitHostPort(v)
+	if aerr, ok := err.(*net.AddrError); ok {
+		// Drop the address, which may be sensitive
+		return fmt.Errorf("invalid host:port: %s", aerr.Err)
+	} else if err != nil {
+		return fmt.Errorf("invalid host:port: %w", err)
+	}
+	if host == "" {
+		return fmt.Errorf("host cannot be empty")
+	}
+	if net.ParseIP(host) == nil {
+		if err := Hostname(host); err != nil {
+			return err
+		}
+	}
+	return Port(port)
+}
+
+// IP validates an IPv4 or IPv6 address, e.g. "10.0.0.7" or "::1".
+func IP(v string) error {
+	if net.ParseIP(v) == nil {
+		return fmt.Errorf("must be an IP address")
+	}
+	return nil
+}
+
+// CIDR validates an IP network in CIDR notation, e.g. "10.0.0.0/8".
+func CIDR(v string) error {
+	if _, _, err := net.ParseCIDR(v); err != nil {
+		return fmt.Errorf("must be a CIDR network such as 10.0.0.0/8")
+	}
+	return nil
+}
+
+// Hostname validates an RFC 1123 host name such as "db-1.internal": dot
+// separated labels of letters, digits and hyphens, each 1-63 characters
+// and not starting or ending with a hyphen, 253 characters at most. A
+// trailing dot is allowed.
+func Hostname(v string) error {
+	name := strings.TrimSuffix(v, ".")
+	if name == "" || len(name) > 253 {
+		return fmt.Errorf("must be a host name of 1-253 characters")
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("invalid host name label")
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return fmt.Errorf("host name may only contain letters, digits, hyphens and dots")
+			}
+		}
+	}
+	return nil
+}
+
+// UUID validates a UUID in the canonical form
+// "f47ac10b-58cc-4372-a567-0e02b2c3d479", in braces or without dashes, in
+// any case.
+func UUID(v string) error {
+	_, err := parseUUID(v)
+	return err
+}
+
+// UUIDVersion returns a validator that checks the value is a UUID of the
+// given version, e.g. UUIDVersion(4) for random instance IDs.
+func UUIDVersion(version int) func(string) error {
+	return func(v string) error {
+		u, err := parseUUID(v)
+		if err != nil {
+			return err
+		}
+		if int(u[6]>>4) != version {
+			return fmt.Errorf("must be a version %d UUID", version)
+		}
+		return nil
+	}
+}
+
+// parseUUID decodes a UUID in any of the forms accepted by UUID.
+func parseUUID(v string) ([16]byte, error) {
+	var u [16]byte
+	s := v
+	if len(s) == 38 && s[0] == '{' && s[37] == '}' {
+		s = s[1:37]
+	}
+	if len(s) == 36 {
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return u, fmt.Errorf("must be a UUID")
+		}
+		s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	}
+	if len(s) != 32 {
+		return u, fmt.Errorf("must be a UUID")
+	}
+	for i := range u {
+		hi, ok1 := unhex(s[2*i])
+		lo, ok2 := unhex(s[2*i+1])
+		if !ok1 || !ok2 {
+			return u, fmt.Errorf("must be a UUID")
+		}
+		u[i] = hi<<4 | lo
 	}
+	return u, nil
+}
 
-	// Simple validation without importing strconv
-	if len(v) == 0 || len(v) > 5 {
-		return fmt.Errorf("invalid port number")
+// unhex returns the value of the hexadecimal digit c.
+func unhex(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
 	}
+	return 0, false
+}
 
-	// Check all digits
-	for _, r := range v {
-		if r < '0' || r > '9' {
-			return fmt.Errorf("port must be numeric")
+// ULID validates a ULID such as "01ARZ3NDEKTSV4RRFFQ69G5FAV": 26 characters
+// of Crockford's base32 alphabet, in any case, not exceeding the 128-bit
+// range.
+func ULID(v string) error {
+	if len(v) != 26 {
+		return fmt.Errorf("must be a ULID of 26 characters")
+	}
+	if v[0] > '7' {
+		return fmt.Errorf("ULID is out of range")
+	}
+	for _, c := range strings.ToUpper(v) {
+		if !strings.ContainsRune("0123456789ABCDEFGHJKMNPQRSTVWXYZ", c) {
+			return fmt.Errorf("ULID may only contain Crockford base32 characters")
 		}
 	}
+	return nil
+}
+
+// MAC validates a hardware address in a form accepted by net.ParseMAC,
+// e.g. "00:1a:2b:3c:4d:5e".
+func MAC(v string) error {
+	if _, err := net.ParseMAC(v); err != nil {
+		return fmt.Errorf("must be a MAC address")
+	}
+	return nil
+}
+
+// Port validates that the value is a valid port number (1-65535).
+func Port(v string) error {
+	_, err := parsePort(v)
+	return err
+}
 
-	// Basic range check (rough)
-	if v == "0" || (len(v) == 5 && v > "65535") {
-		return fmt.Errorf("port must be between 1 and 65535")
+// PortRange returns a validator that checks the value is a port number
+// between min and max inclusive, e.g. PortRange(30000, 32767) for a
+// Kubernetes NodePort.
+func PortRange(min, max int) func(string) error {
+	return func(v string) error {
+		n, err := parsePort(v)
+		if err != nil {
+			return err
+		}
+		if n < min || n > max {
+			return fmt.Errorf("port must be between %d and %d", min, max)
+		}
+		return nil
 	}
+}
 
+// UnprivilegedPort validates a port number (see Port) that a process not
+// running as root can bind: 1024 or above. As root, or where there is no
+// user ID (Windows), any port is accepted. To be warned rather than stopped,
+// e.g. when the binary may hold CAP_NET_BIND_SERVICE, run it as a warning
+// deep check:
+//
+//	envreq.Requirement{Name: "HTTP_PORT", Validate: envreq.Port,
+//	    DeepValidate: envreq.UnprivilegedPort, DeepSeverity: envreq.SeverityWarning}
+func UnprivilegedPort(v string) error {
+	n, err := parsePort(v)
+	if err != nil {
+		return err
+	}
+	if uid := os.Geteuid(); n < 1024 && uid > 0 {
+		return fmt.Errorf("port below 1024 needs root privileges to bind")
+	}
 	return nil
 }
 
-// Base64 validates that the value is valid base64 encoding.
+// parsePort parses a decimal port number.
+func parsePort(v string) (int, error) {
+	if v == "" {
+		return 0, fmt.Errorf("port cannot be empty")
+	}
+	if strings.Trim(v, "0123456789") != "" {
+		return 0, fmt.Errorf("port must be numeric")
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > 65535 {
+		return 0, fmt.Errorf("port must be between 1 and 65535")
+	}
+	return n, nil
+}
+
+// Base64 validates that the value decodes as standard base64 (the + and /
+// alphabet), padded or not. Use Base64Std or Base64URL to require one
+// form, and Base64DecodedLen to check the size of a key.
 func Base64(v string) error {
 	if v == "" {
 		return fmt.Errorf("base64 value cannot be empty")
 	}
+	if _, err := decodeBase64(v, base64.StdEncoding); err != nil {
+		return fmt.Errorf("must be base64 encoded")
+	}
+	return nil
+}
+
+// Base64Std validates padded standard base64, as written by
+// base64.StdEncoding and "openssl rand -base64".
+func Base64Std(v string) error {
+	if v == "" {
+		return fmt.Errorf("base64 value cannot be empty")
+	}
+	if _, err := base64.StdEncoding.Strict().DecodeString(v); err != nil {
+		return fmt.Errorf("must be padded standard base64")
+	}
+	return nil
+}
+
+// Base64URL validates base64 in the URL-safe alphabet (- and _), padded or
+// not, as used by JWT secrets and many token formats.
+func Base64URL(v string) error {
+	if v == "" {
+		return fmt.Errorf("base64 value cannot be empty")
+	}
+	if _, err := decodeBase64(v, base64.URLEncoding); err != nil {
+		return fmt.Errorf("must be URL-safe base64")
+	}
+	return nil
+}
 
-	// Basic base64 character validation
-	for _, r := range v {
-		if !((r >= 'A' && r <= 'Z') ||
-			(r >= 'a' && r <= 'z') ||
-			(r >= '0' && r <= '9') ||
-			r == '+' || r == '/' || r == '=') {
-			return fmt.Errorf("invalid base64 character")
+// Base64DecodedLen returns a validator that checks the value is base64, in
+// either alphabet and padded or not, decoding to exactly n bytes, e.g.
+// Base64DecodedLen(32) for an AES-256 key. The error never shows the value
+// or its decoded length.
+func Base64DecodedLen(n int) func(string) error {
+	return func(v string) error {
+		b, err := decodeBase64(v, base64.StdEncoding)
+		if err != nil {
+			b, err = decodeBase64(v, base64.URLEncoding)
+		}
+		if err != nil || v == "" {
+			return fmt.Errorf("must be base64 encoded")
 		}
+		if len(b) != n {
+			return fmt.Errorf("must decode to %d bytes", n)
+		}
+		return nil
 	}
+}
 
-	// Check padding
-	padding := strings.Count(v, "=")
-	if padding > 2 {
-		return fmt.Errorf("invalid base64 padding")
+// decodeBase64 decodes v with enc, or without padding when v has none.
+// Unused trailing bits must be zero.
+func decodeBase64(v string, enc *base64.Encoding) ([]byte, error) {
+	if !strings.HasSuffix(v, "=") && len(v)%4 != 0 {
+		enc = enc.WithPadding(base64.NoPadding)
 	}
+	return enc.Strict().DecodeString(v)
+}
 
-	return nil
+// All returns a validator that applies validators in order and returns the
+// first error, so later ones may assume earlier ones passed:
+//
+//	Validate: envreq.All(envreq.URL, envreq.HTTPSOnly, envreq.URLHostIn("*.internal"))
+func All(validators ...func(string) error) func(string) error {
+	return func(v string) error {
+		for _, validate := range validators {
+			if validate == nil {
+				continue
+			}
+			if err := validate(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Any returns a validator that accepts the value when at least one of
+// validators does. Otherwise the error lists every rejection, and
+// errors.Is and errors.As see each of them.
+func Any(validators ...func(string) error) func(string) error {
+	return func(v string) error {
+		var errs []any
+		for _, validate := range validators {
+			if validate == nil {
+				continue
+			}
+			err := validate(v)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		if len(errs) == 0 {
+			return nil
+		}
+		format := strings.TrimSuffix(strings.Repeat("%w; ", len(errs)), "; ")
+		return fmt.Errorf("no alternative matched: "+format, errs...)
+	}
+}
+
+// WithMessage returns a validator that replaces the message of validate's
+// errors with msg, e.g. WithMessage(URLHostIn("registry.internal"), "must
+// be the internal registry URL"). The original error is still available
+// to errors.Is and errors.As, and is no longer printed, which also keeps
+// values quoted by validate out of reports.
+func WithMessage(validate func(string) error, msg string) func(string) error {
+	return func(v string) error {
+		if err := validate(v); err != nil {
+			return messageError{msg, err}
+		}
+		return nil
+	}
+}
+
+// messageError is an error with a message replaced by WithMessage.
+type messageError struct {
+	msg string
+	err error
+}
+
+func (e messageError) Error() string { return e.msg }
+func (e messageError) Unwrap() error { return e.err }
+
+// Not returns a validator that inverts validate: the value is valid when
+// validate rejects it. what describes the rejected values for the error
+// message, e.g. Not(OneOf("localhost", "127.0.0.1"), "a loopback host")
+// fails with "must not be a loopback host".
+func Not(validate func(string) error, what string) func(string) error {
+	return func(v string) error {
+		if validate(v) == nil {
+			return fmt.Errorf("must not be %s", what)
+		}
+		return nil
+	}
+}
+
+// When returns a validator that applies validate only while cond reports
+// true. cond is evaluated on every validation, so it follows SetProfile and
+// the environment:
+//
+//	Validate: envreq.When(envreq.InProfile("production"), envreq.Not(envreq.OneOf("debug"), "debug"))
+func When(cond func() bool, validate func(string) error) func(string) error {
+	return func(v string) error {
+		if !cond() {
+			return nil
+		}
+		return validate(v)
+	}
+}
+
+// Unless returns a validator that applies validate except while cond
+// reports true, e.g. Unless(IsDevelopment, ...) for rules that local setups
+// may break.
+func Unless(cond func() bool, validate func(string) error) func(string) error {
+	return When(func() bool { return !cond() }, validate)
+}
+
+// InProfile returns a condition for When and Unless that reports whether
+// the active Profile is one of profiles.
+func InProfile(profiles ...string) func() bool {
+	return func() bool {
+		p := Profile()
+		for _, want := range profiles {
+			if strings.EqualFold(p, want) {
+				return true
+			}
+		}
+		return false
+	}
 }
@@ -0,0 +1,235 @@
+// Package vault is an envreq Source backed by HashiCorp Vault's HTTP API.
+//
+// Requirements reference secrets with a SecretRef of the form
+// "vault:<path>#<field>", e.g. "vault:secret/data/payments#stripe_key" for
+// the KV version 2 engine mounted at secret/ (KV version 1 paths work too):
+//
+//	src, err := vault.New(vault.Config{}) // VAULT_ADDR, VAULT_TOKEN
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	envreq.SetSources(envreq.Env, src)
+//
+//	key := envreq.Check(envreq.Requirement{
+//	    Name:      "STRIPE_API_KEY",
+//	    Source:    "payments",
+//	    SecretRef: "vault:secret/data/payments#stripe_key",
+//	    Sensitive: true,
+//	})
+//
+// Secrets are fetched at Check time and cached per path for their lease
+// duration, or Config.TTL for non-leased secrets such as KV.
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scheme is the SecretRef scheme resolved by Source.
+const Scheme = "vault"
+
+// DefaultTTL is how long secrets without a lease are cached.
+const DefaultTTL = 5 * time.Minute
+
+// Config configures a Source. Zero values fall back to the standard Vault
+// environment variables.
+type Config struct {
+	Address   string        // server URL; default $VAULT_ADDR
+	Token     string        // client token; default $VAULT_TOKEN
+	Namespace string        // Vault Enterprise namespace; default $VAULT_NAMESPACE
+	TTL       time.Duration // cache lifetime of non-leased secrets; default DefaultTTL
+	Timeout   time.Duration // per-request timeout; default 10s
+	// Path, if set, is read for requirements without a SecretRef: the
+	// variable name is used as the field, e.g. Path "secret/data/app"
+	// serves STRIPE_API_KEY from the stripe secret's STRIPE_API_KEY field.
+	Path   string
+	Client *http.Client // default http.DefaultClient
+}
+
+// Source resolves envreq requirements from Vault. It implements
+// envreq.RefSource.
+type Source struct {
+	cfg Config
+
+	mu     sync.Mutex
+	cache  map[string]entry
+	hits   uint64 // secrets served from the cache
+	misses uint64 // secrets read from Vault
+	now    func() time.Time
+}
+
+type entry struct {
+	data    map[string]string
+	expires time.Time
+}
+
+// New returns a Source for cfg.
+func New(cfg Config) (*Source, error) {
+	if cfg.Address == "" {
+		cfg.Address = os.Getenv("VAULT_ADDR")
+	}
+	if cfg.Token == "" {
+		cfg.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	if cfg.Address == "" {
+		return nil, errors.New("vault: no address (set Config.Address or VAULT_ADDR)")
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultTTL
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	cfg.Address = strings.TrimSuffix(cfg.Address, "/")
+	return &Source{cfg: cfg, cache: map[string]entry{}, now: time.Now}, nil
+}
+
+// Name returns "vault", recorded as the provenance of values.
+func (s *Source) Name() string {
+	return Scheme
+}
+
+// Scheme returns "vault".
+func (s *Source) Scheme() string {
+	return Scheme
+}
+
+// Lookup reads name from Config.Path, if configured.
+func (s *Source) Lookup(name string) (string, bool, error) {
+	if s.cfg.Path == "" {
+		return "", false, nil
+	}
+	return s.field(s.cfg.Path, name)
+}
+
+// LookupRef resolves "<path>#<field>".
+func (s *Source) LookupRef(ref string) (string, bool, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || field == "" {
+		return "", false, fmt.Errorf("vault: reference %q is not <path>#<field>", ref)
+	}
+	return s.field(path, field)
+}
+
+// field returns one field of the secret at path.
+func (s *Source) field(path, field string) (string, bool, error) {
+	data, err := s.secret(path)
+	if err != nil || data == nil {
+		return "", false, err
+	}
+	v, ok := data[field]
+	return v, ok, nil
+}
+
+// secret returns the secret at path, from the cache while its lease is
+// valid. A missing secret is cached as nil.
+func (s *Source) secret(path string) (map[string]string, error) {
+	path = strings.Trim(path, "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.cache[path]; ok && s.now().Before(e.expires) {
+		s.hits++
+		return e.data, nil
+	}
+	s.misses++
+	data, lease, err := s.read(path)
+	if err != nil {
+		return nil, err
+	}
+	ttl := s.cfg.TTL
+	if lease > 0 {
+		ttl = lease
+	}
+	s.cache[path] = entry{data: data, expires: s.now().Add(ttl)}
+	return data, nil
+}
+
+// CacheStats returns how many secret reads were served from the cache and
+// how many went to Vault, for envreq.Providers.
+func (s *Source) CacheStats() (hits, misses uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits, s.misses
+}
+
+// response is the subset of a Vault read response used here.
+type response struct {
+	LeaseDuration int             `json:"lease_duration"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// read fetches the secret at path, unwrapping KV version 2 responses.
+func (s *Source) read(path string) (map[string]string, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.cfg.Address+"/v1/"+path, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("X-Vault-Token", s.cfg.Token)
+	if s.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.cfg.Namespace)
+	}
+
+	resp, err := s.cfg.Client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, 0, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, 0, fmt.Errorf("vault: GET %s: %s: %w", path, resp.Status, fs.ErrPermission)
+	default:
+		return nil, 0, fmt.Errorf("vault: GET %s: %s", path, resp.Status)
+	}
+
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, 0, fmt.Errorf("vault: %s: %w", path, err)
+	}
+
+	// KV v2 nests the secret under data.data, next to data.metadata
+	var kv2 struct {
+		Data     map[string]any  `json:"data"`
+		Metadata json.RawMessage `json:"metadata"`
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(r.Data, &kv2); err == nil && kv2.Metadata != nil && kv2.Data != nil {
+		fields = kv2.Data
+	} else if err := json.Unmarshal(r.Data, &fields); err != nil {
+		return nil, 0, fmt.Errorf("vault: %s: %w", path, err)
+	}
+
+	data := make(map[string]string, len(fields))
+	for k, v := range fields {
+		if str, ok := v.(string); ok {
+			data[k] = str
+		} else {
+			b, _ := json.Marshal(v)
+			data[k] = string(b)
+		}
+	}
+	return data, time.Duration(r.LeaseDuration) * time.Second, nil
+}
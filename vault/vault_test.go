@@ -0,0 +1,144 @@
+package vault
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bbmumford/envreq"
+)
+
+func testServer(t *testing.T, reads *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.test" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		reads.Add(1)
+		switch r.URL.Path {
+		case "/v1/secret/data/payments":
+			w.Write([]byte(`{"lease_duration":0,"data":{"data":{"stripe_key":"sk_test_123"},"metadata":{"version":3}}}`))
+		case "/v1/kv/app":
+			w.Write([]byte(`{"lease_duration":60,"data":{"DATABASE_URL":"postgres://db/app","port":5432}}`))
+		default:
+			http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSecretRef(t *testing.T) {
+	var reads atomic.Int32
+	srv := testServer(t, &reads)
+	src, err := New(Config{Address: srv.URL, Token: "s.test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := envreq.New()
+	g.SetSources(envreq.Env, src)
+
+	res := g.Check(envreq.Requirement{
+		Name:      "VAULT_TEST_STRIPE_KEY",
+		Source:    "payments",
+		SecretRef: "vault:secret/data/payments#stripe_key",
+		Sensitive: true,
+	})
+	if !res.Present || res.Value != "sk_test_123" || res.Provenance != "vault" {
+		t.Fatalf("Expected the KV v2 secret from vault, got %q from %q (err %v)", res.Value, res.Provenance, res.Err)
+	}
+
+	// Requirements without a SecretRef are not looked up in Vault
+	if res := g.Check(envreq.Requirement{Name: "VAULT_TEST_OTHER", Source: "test", Optional: true}); res.Present {
+		t.Errorf("Expected VAULT_TEST_OTHER to be missing, got %q", res.Value)
+	}
+
+	res = g.Check(envreq.Requirement{Name: "VAULT_TEST_MISSING", Source: "test", SecretRef: "vault:secret/data/payments#nope"})
+	if res.Present || res.Err == nil {
+		t.Errorf("Expected an unresolved SecretRef error, got %+v", res)
+	}
+	if n := reads.Load(); n != 1 {
+		t.Errorf("Expected the secret to be read once, got %d reads", n)
+	}
+}
+
+func TestCacheLease(t *testing.T) {
+	var reads atomic.Int32
+	srv := testServer(t, &reads)
+	src, err := New(Config{Address: srv.URL, Token: "s.test", TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	src.now = func() time.Time { return now }
+
+	for range 2 {
+		v, ok, err := src.LookupRef("kv/app#DATABASE_URL")
+		if err != nil || !ok || v != "postgres://db/app" {
+			t.Fatalf("Expected the KV v1 value, got %q, %v, %v", v, ok, err)
+		}
+	}
+	if v, _, _ := src.LookupRef("kv/app#port"); v != "5432" {
+		t.Errorf("Expected non-string fields as JSON, got %q", v)
+	}
+	if n := reads.Load(); n != 1 {
+		t.Errorf("Expected one read within the lease, got %d", n)
+	}
+
+	// The 60s lease wins over the configured TTL
+	now = now.Add(61 * time.Second)
+	src.LookupRef("kv/app#DATABASE_URL")
+	if n := reads.Load(); n != 2 {
+		t.Errorf("Expected a re-read after the lease expired, got %d reads", n)
+	}
+}
+
+func TestPathAndErrors(t *testing.T) {
+	var reads atomic.Int32
+	srv := testServer(t, &reads)
+
+	src, _ := New(Config{Address: srv.URL, Token: "s.test", Path: "kv/app"})
+	if v, ok, err := src.Lookup("DATABASE_URL"); err != nil || !ok || v != "postgres://db/app" {
+		t.Errorf("Expected Lookup from Config.Path, got %q, %v, %v", v, ok, err)
+	}
+
+	bad, _ := New(Config{Address: srv.URL, Token: "wrong"})
+	if _, _, err := bad.LookupRef("kv/app#DATABASE_URL"); err == nil {
+		t.Error("Expected an error for a rejected token")
+	}
+	if _, _, err := bad.LookupRef("kv/app"); err == nil {
+		t.Error("Expected an error for a reference without a field")
+	}
+
+	t.Setenv("VAULT_ADDR", "")
+	if _, err := New(Config{}); err == nil {
+		t.Error("Expected an error without an address")
+	}
+}
+
+func TestProviderHealth(t *testing.T) {
+	var reads atomic.Int32
+	srv := testServer(t, &reads)
+	src, _ := New(Config{Address: srv.URL, Token: "s.test"})
+	bad, _ := New(Config{Address: srv.URL, Token: "wrong"})
+
+	g := envreq.New()
+	g.SetSources(envreq.Env, src, bad)
+	for _, name := range []string{"STRIPE_API_KEY", "STRIPE_KEY"} {
+		g.Check(envreq.Requirement{Name: name, Source: "payments", SecretRef: "vault:secret/data/payments#stripe_key"})
+	}
+
+	providers := g.Providers()
+	if p := providers[1]; !p.Reachable || p.Auth != envreq.AuthOK || p.CacheHits != 1 || p.CacheMisses != 1 {
+		t.Errorf("Expected a healthy, cached source, got %+v", p)
+	}
+	if p := providers[2]; !p.Reachable || p.Auth != envreq.AuthFailed || !errors.Is(p.LastError, fs.ErrPermission) {
+		t.Errorf("Expected a rejected token, got %+v", p)
+	}
+}
@@ -0,0 +1,102 @@
+package envreq
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaDriftError describes how the runtime registry diverges from a
+// committed schema.
+type SchemaDriftError struct {
+	Path         string   // schema file that was compared
+	Unexpected   []string // registered at runtime but absent from the schema
+	Unregistered []string // in the schema but never registered at runtime
+	Changed      []string // present in both with different required/sensitive flags
+}
+
+func (e *SchemaDriftError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "envreq: registry does not match schema %s", e.Path)
+	if len(e.Unexpected) > 0 {
+		fmt.Fprintf(&b, "; not in schema: %s", strings.Join(e.Unexpected, ", "))
+	}
+	if len(e.Unregistered) > 0 {
+		fmt.Fprintf(&b, "; never registered: %s", strings.Join(e.Unregistered, ", "))
+	}
+	if len(e.Changed) > 0 {
+		fmt.Fprintf(&b, "; changed: %s", strings.Join(e.Changed, ", "))
+	}
+	return b.String()
+}
+
+// VerifySchema compares the runtime registry against the committed schema
+// file at path (as written by WriteSchema) and returns a *SchemaDriftError
+// when they diverge. Call it after all packages have registered, typically
+// right after MustValidate, so configuration changes are forced through
+// review of the schema file. MustValidate runs it automatically when
+// ENVREQ_SCHEMA names a schema file.
+func VerifySchema(path string) error {
+	return std.VerifySchema(path)
+}
+
+// VerifySchema compares the registry against the committed schema file at
+// path. See the package-level VerifySchema.
+func (g *Registry) VerifySchema(path string) error {
+	f, err := openFile(path)
+	if err != nil {
+		return fmt.Errorf("envreq: %w", err)
+	}
+	defer f.Close()
+
+	want, err := ReadSchema(f)
+	if err != nil {
+		return err
+	}
+
+	drift := compareSchema(want, g.Describe())
+	if drift == nil {
+		return nil
+	}
+	drift.Path = path
+	return drift
+}
+
+// compareSchema returns the differences between the committed and the
+// runtime schema, or nil when they match.
+func compareSchema(want, got Schema) *SchemaDriftError {
+	wantVars := make(map[string]SchemaVar, len(want.Vars))
+	for _, v := range want.Vars {
+		wantVars[v.Name] = v
+	}
+
+	drift := &SchemaDriftError{}
+	for _, v := range got.Vars {
+		w, ok := wantVars[v.Name]
+		if !ok {
+			drift.Unexpected = append(drift.Unexpected, v.Name)
+			continue
+		}
+		delete(wantVars, v.Name)
+
+		var diffs []string
+		if w.Required != v.Required {
+			diffs = append(diffs, fmt.Sprintf("required %t->%t", w.Required, v.Required))
+		}
+		if w.Sensitive != v.Sensitive {
+			diffs = append(diffs, fmt.Sprintf("sensitive %t->%t", w.Sensitive, v.Sensitive))
+		}
+		if len(diffs) > 0 {
+			drift.Changed = append(drift.Changed, fmt.Sprintf("%s (%s)", v.Name, strings.Join(diffs, ", ")))
+		}
+	}
+	for name := range wantVars {
+		drift.Unregistered = append(drift.Unregistered, name)
+	}
+	sort.Strings(drift.Unregistered)
+
+	if len(drift.Unexpected)+len(drift.Unregistered)+len(drift.Changed) == 0 {
+		return nil
+	}
+	return drift
+}